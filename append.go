@@ -2,9 +2,12 @@ package fs
 
 import (
 	"archive/tar"
+	"compress/gzip"
 	"context"
 	"errors"
 	"io"
+	"slices"
+	"strings"
 
 	"lesiw.io/fs/path"
 )
@@ -35,7 +38,8 @@ type AppendDirFS interface {
 	// Data written to the returned writer is extracted as a tar archive into
 	// the directory. The directory will be created if it doesn't exist.
 	// Existing files with the same names will be overwritten, but other files
-	// in the directory are preserved.
+	// in the directory are preserved. If [TarCompression] of ctx is not
+	// [CompressionNone], the stream is compressed in that format.
 	//
 	// The returned writer must be closed to complete the operation.
 	AppendDir(ctx context.Context, dir string) (io.WriteCloser, error)
@@ -51,13 +55,19 @@ type AppendDirFS interface {
 //
 // The returned [WritePathCloser] must be closed when done. The Path() method
 // returns the native filesystem path, or the input path if localization is not
-// supported.
+// supported. If the backend's stream reports its own path via [Pather], such as
+// a normalized object key, that path is returned instead.
 //
 // # Files
 //
 // Writes are added to the end of the file. If the file does not exist, it is
 // created with mode 0644 (or the mode specified via [WithFileMode]).
 //
+// Without [AppendFS], the file is rewritten through [CreateFS]. The existing
+// file's permissions are kept, and are reapplied on Close if the filesystem
+// implements [ChmodFS]. Its modification time becomes the time of the
+// rewrite.
+//
 // Requires: [AppendFS] || ([FS] && [CreateFS])
 //
 // # Directories
@@ -67,6 +77,13 @@ type AppendDirFS interface {
 // the same names are overwritten, but other files in the directory are
 // preserved.
 //
+// Under [WithCompression], the tar stream is expected to be compressed.
+//
+// Without [AppendDirFS], named pipes and devices in the archive are created
+// with [MknodFS]. If the filesystem does not implement it, they are skipped,
+// and Close returns an error wrapping [ErrUnsupported] for each one after
+// the rest of the archive is extracted.
+//
 // Requires: [AppendDirFS] || [CreateFS]
 func Append(
 	ctx context.Context, fsys FS, name string,
@@ -112,6 +129,10 @@ retry:
 }
 
 // createAppend implements append using CreateFS.
+//
+// The file is rewritten, so its original mode is carried into Create and,
+// when the filesystem implements ChmodFS, reapplied once the rewrite is
+// complete.
 func createAppend(
 	ctx context.Context, fsys FS, name string,
 ) (io.WriteCloser, error) {
@@ -121,6 +142,14 @@ func createAppend(
 		return nil, err
 	}
 
+	var info FileInfo
+	if r != nil {
+		info, _ = Stat(ctx, fsys, name)
+	}
+	if info != nil {
+		ctx = WithFileMode(ctx, info.Mode().Perm())
+	}
+
 	w, err := Create(ctx, fsys, name)
 	if err != nil {
 		if r != nil {
@@ -129,14 +158,38 @@ func createAppend(
 		return nil, err
 	}
 
-	return newAppendWriter(r, w), nil
+	aw := newAppendWriter(r, w)
+	if _, ok := fsys.(ChmodFS); ok && info != nil {
+		return &chmodOnClose{aw, ctx, fsys, name, info.Mode().Perm()}, nil
+	}
+	return aw, nil
+}
+
+// chmodOnClose restores a file's mode after it is rewritten.
+type chmodOnClose struct {
+	io.WriteCloser
+	ctx  context.Context
+	fsys FS
+	name string
+	mode Mode
+}
+
+func (c *chmodOnClose) Close() error {
+	if err := c.WriteCloser.Close(); err != nil {
+		return err
+	}
+	err := Chmod(c.ctx, c.fsys, c.name, c.mode)
+	if errors.Is(err, ErrUnsupported) {
+		return nil
+	}
+	return err
 }
 
 func appendDirAsTar(
 	ctx context.Context, fsys FS, dir string,
 ) (io.WriteCloser, error) {
 	dir = path.Dir(dir)
-	if tfs, ok := fsys.(AppendDirFS); ok {
+	if tfs, ok := fsys.(AppendDirFS); ok && stripComponents(ctx) <= 0 {
 		w, err := tfs.AppendDir(ctx, dir)
 		if err != nil && !errors.Is(err, ErrUnsupported) {
 			return nil, err
@@ -148,16 +201,39 @@ func appendDirAsTar(
 
 	// Fallback: Extract one file at a time.
 	pr, pw := io.Pipe()
+	w := &extractWriter{PipeWriter: pw, done: make(chan error, 1)}
 	go func() {
-		err := extractTarToFS(ctx, fsys, dir, pr)
+		var r io.Reader = pr
+		var err error
+		if TarCompression(ctx) == CompressionGzip {
+			r, err = gzip.NewReader(pr)
+		}
+		if err == nil {
+			err = extractTarToFS(ctx, fsys, dir, r)
+		}
 		if err == nil {
 			// Drain trailing data (e.g. tar block-alignment padding)
 			// so the writer side doesn't get a broken pipe error.
 			_, err = io.Copy(io.Discard, pr)
 		}
 		pr.CloseWithError(err)
+		w.done <- err
 	}()
-	return pw, nil
+	return w, nil
+}
+
+// extractWriter feeds a tar stream to a background extraction. Close waits
+// for the extraction to finish and returns its error.
+type extractWriter struct {
+	*io.PipeWriter
+	done chan error
+}
+
+func (w *extractWriter) Close() error {
+	if err := w.PipeWriter.Close(); err != nil {
+		return err
+	}
+	return <-w.done
 }
 
 // extractTarToFS reads a tar archive and extracts it to the filesystem.
@@ -165,39 +241,47 @@ func extractTarToFS(
 	ctx context.Context, fsys FS, dir string, r io.Reader,
 ) error {
 	tr := tar.NewReader(r)
-	_, supportsMkdir := fsys.(MkdirFS)
+	strip := stripComponents(ctx)
+	numericOwner := tarNumericOwner(ctx)
+	var skipped []error
 
 	for {
 		hdr, err := tr.Next()
 		if err == io.EOF {
-			return nil
+			// Special files the filesystem cannot create are reported
+			// once the rest of the archive is extracted.
+			return errors.Join(skipped...)
 		}
 		if err != nil {
 			return err
 		}
 
+		name := hdr.Name
+		if strip > 0 {
+			var ok bool
+			if name, ok = stripPath(name, strip); !ok {
+				continue
+			}
+		}
+
 		// Construct full path
-		fullPath := path.Join(dir, hdr.Name)
+		fullPath := path.Join(dir, name)
 
+		created := true
 		switch hdr.Typeflag {
 		case tar.TypeDir:
-			// Only create directory if MkdirFS is supported
-			// (otherwise directories are virtual)
-			if supportsMkdir {
-				dirCtx := WithDirMode(ctx, Mode(hdr.Mode))
-				err = MkdirAll(dirCtx, fsys, fullPath)
-				if err != nil {
-					return err
-				}
+			// Directories are virtual if the filesystem cannot create
+			// them.
+			dirCtx := WithDirMode(ctx, Mode(hdr.Mode))
+			created, err = mkdirAllOrVirtual(dirCtx, fsys, fullPath)
+			if err != nil {
+				return err
 			}
 		case tar.TypeReg:
-			// Create parent directories only if MkdirFS is supported
-			// (otherwise directories are virtual and created implicitly)
-			if supportsMkdir {
-				parent := path.Dir(fullPath)
-				if err := MkdirAll(ctx, fsys, parent); err != nil {
-					return err
-				}
+			// Parent directories are created implicitly if virtual.
+			parent := path.Dir(fullPath)
+			if _, err := mkdirAllOrVirtual(ctx, fsys, parent); err != nil {
+				return err
 			}
 
 			// Create file with mode from tar header
@@ -216,6 +300,70 @@ func extractTarToFS(
 			if closeErr != nil {
 				return closeErr
 			}
+		case tar.TypeFifo, tar.TypeChar, tar.TypeBlock:
+			err := extractNode(ctx, fsys, fullPath, hdr)
+			if errors.Is(err, ErrUnsupported) {
+				skipped = append(skipped, err)
+				created = false
+			} else if err != nil {
+				return err
+			}
+		default:
+			created = false
+		}
+
+		// Under WithTarNumericOwner, restore ownership by id.
+		if created && numericOwner {
+			err := Chown(ctx, fsys, fullPath, hdr.Uid, hdr.Gid)
+			if err != nil && !errors.Is(err, ErrUnsupported) {
+				return err
+			}
 		}
 	}
 }
+
+// extractNode creates the named pipe or device described by hdr at name,
+// replacing any file already there. It returns an error wrapping
+// [ErrUnsupported] if fsys cannot create special files.
+func extractNode(
+	ctx context.Context, fsys FS, name string, hdr *tar.Header,
+) error {
+	if _, ok := fsys.(MknodFS); !ok {
+		return &PathError{Op: "mknod", Path: name, Err: ErrUnsupported}
+	}
+	mode := Mode(hdr.Mode).Perm()
+	var dev uint64
+	switch hdr.Typeflag {
+	case tar.TypeFifo:
+		mode |= ModeNamedPipe
+	case tar.TypeChar:
+		mode |= ModeDevice | ModeCharDevice
+		dev = makedev(hdr.Devmajor, hdr.Devminor)
+	case tar.TypeBlock:
+		mode |= ModeDevice
+		dev = makedev(hdr.Devmajor, hdr.Devminor)
+	}
+	if _, err := mkdirAllOrVirtual(ctx, fsys, path.Dir(name)); err != nil {
+		return err
+	}
+	err := Mknod(ctx, fsys, name, mode, dev)
+	if errors.Is(err, ErrExist) {
+		if err := Remove(ctx, fsys, name); err != nil {
+			return err
+		}
+		err = Mknod(ctx, fsys, name, mode, dev)
+	}
+	return err
+}
+
+// stripPath removes the first n elements of the slash-separated tar member
+// name. It reports false if name has no elements left after stripping.
+func stripPath(name string, n int) (string, bool) {
+	elems := slices.DeleteFunc(strings.Split(name, "/"), func(e string) bool {
+		return e == "" || e == "."
+	})
+	if len(elems) <= n {
+		return "", false
+	}
+	return strings.Join(elems[n:], "/"), true
+}
@@ -1,11 +1,18 @@
 package fs_test
 
 import (
+	"archive/tar"
 	"context"
 	"fmt"
+	"io"
+	"iter"
 	"log"
+	"maps"
+	"slices"
+	"testing"
 
 	"lesiw.io/fs"
+	"lesiw.io/fs/memfs"
 	"lesiw.io/fs/osfs"
 )
 
@@ -38,3 +45,142 @@ func ExampleAppend() {
 	// first line
 	// second line
 }
+
+// chmodFS is a filesystem without AppendFS or TruncateFS whose Create always
+// applies a default mode, and which tracks modes set through Chmod.
+type chmodFS struct {
+	fs.FS
+	modes map[string]fs.Mode
+}
+
+func (c *chmodFS) Create(
+	ctx context.Context, name string,
+) (io.WriteCloser, error) {
+	c.modes[name] = 0644
+	return c.FS.(fs.CreateFS).Create(ctx, name)
+}
+
+func (c *chmodFS) Remove(ctx context.Context, name string) error {
+	delete(c.modes, name)
+	return c.FS.(fs.RemoveFS).Remove(ctx, name)
+}
+
+func (c *chmodFS) Stat(ctx context.Context, name string) (fs.FileInfo, error) {
+	info, err := c.FS.(fs.StatFS).Stat(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return modeInfo{info, c.modes[name]}, nil
+}
+
+func (c *chmodFS) Chmod(ctx context.Context, name string, mode fs.Mode) error {
+	c.modes[name] = mode
+	return nil
+}
+
+type modeInfo struct {
+	fs.FileInfo
+	mode fs.Mode
+}
+
+func (i modeInfo) Mode() fs.Mode { return i.mode }
+
+func TestAppendFallbackPreservesMode(t *testing.T) {
+	ctx := context.Background()
+	fsys := &chmodFS{memfs.New(), make(map[string]fs.Mode)}
+
+	if err := fs.WriteFile(ctx, fsys, "f", []byte("a")); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if err := fs.Chmod(ctx, fsys, "f", 0600); err != nil {
+		t.Fatalf("Chmod() error = %v", err)
+	}
+
+	w, err := fs.Append(ctx, fsys, "f")
+	if err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	if _, err := w.Write([]byte("b")); err != nil {
+		_ = w.Close()
+		t.Fatalf("Write() error = %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	data, err := fs.ReadFile(ctx, fsys, "f")
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if got, want := string(data), "ab"; got != want {
+		t.Errorf("ReadFile() = %q, want %q", got, want)
+	}
+	info, err := fs.Stat(ctx, fsys, "f")
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	if got, want := info.Mode().Perm(), fs.Mode(0600); got != want {
+		t.Errorf("Mode().Perm() = %v, want %v", got, want)
+	}
+}
+
+// noMkdirFS is an object-store-like filesystem: it can create, stat, list,
+// and remove files, but has no directories of its own to make.
+type noMkdirFS struct{ fsys fs.FS }
+
+func (f noMkdirFS) Open(
+	ctx context.Context, name string,
+) (io.ReadCloser, error) {
+	return fs.Open(ctx, f.fsys, name)
+}
+
+func (f noMkdirFS) Create(
+	ctx context.Context, name string,
+) (io.WriteCloser, error) {
+	return fs.Create(ctx, f.fsys, name)
+}
+
+func (f noMkdirFS) Stat(ctx context.Context, name string) (fs.FileInfo, error) {
+	return fs.Stat(ctx, f.fsys, name)
+}
+
+func (f noMkdirFS) ReadDir(
+	ctx context.Context, name string,
+) iter.Seq2[fs.DirEntry, error] {
+	return fs.ReadDir(ctx, f.fsys, name)
+}
+
+func (f noMkdirFS) Remove(ctx context.Context, name string) error {
+	return fs.Remove(ctx, f.fsys, name)
+}
+
+// appendTar extracts a tar archive of files to dir through fs.Append.
+func appendTar(
+	ctx context.Context, fsys fs.FS, dir string, files map[string]string,
+) error {
+	w, err := fs.Append(ctx, fsys, dir)
+	if err != nil {
+		return err
+	}
+	tw := tar.NewWriter(w)
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		hdr := &tar.Header{
+			Name: name,
+			Mode: 0644,
+			Size: int64(len(files[name])),
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			_ = w.Close()
+			return err
+		}
+		if _, err := io.WriteString(tw, files[name]); err != nil {
+			_ = w.Close()
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		_ = w.Close()
+		return err
+	}
+	return w.Close()
+}
@@ -0,0 +1,228 @@
+package fs
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"strings"
+
+	"lesiw.io/fs/path"
+)
+
+// archiveFormat identifies an archive file format.
+type archiveFormat int
+
+const (
+	formatUnknown archiveFormat = iota
+	formatTar
+	formatTarGzip
+	formatZip
+)
+
+// detectArchiveFormat determines the archive format from the file name.
+func detectArchiveFormat(name string) archiveFormat {
+	name = strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(name, ".zip"):
+		return formatZip
+	case strings.HasSuffix(name, ".tar.gz"),
+		strings.HasSuffix(name, ".tgz"):
+		return formatTarGzip
+	case strings.HasSuffix(name, ".tar"):
+		return formatTar
+	}
+	return formatUnknown
+}
+
+// OpenArchiveMember opens a single member of a tar or zip archive file for
+// reading, without extracting the rest of the archive.
+//
+// The archive format is determined by the extension of archivePath: ".zip",
+// ".tar", ".tar.gz", or ".tgz". The member is a slash-separated path
+// relative to the archive root, such as "dir/file.txt".
+//
+// Zip archives are read through their central directory. If the reader
+// returned by the filesystem implements [io.ReaderAt] and its size is known
+// (via [io.Seeker] or [StatFS]), only the member's bytes are read.
+// Otherwise, the archive is buffered in memory. Tar archives are scanned
+// sequentially until the member is found.
+//
+// The returned reader must be closed when done.
+//
+// Requires: [FS]
+func OpenArchiveMember(
+	ctx context.Context, fsys FS, archivePath, member string,
+) (io.ReadCloser, error) {
+	var err error
+	if archivePath, err = localizePath(ctx, fsys, archivePath); err != nil {
+		return nil, err
+	}
+	member = archiveMemberName(member)
+
+	switch format := detectArchiveFormat(archivePath); format {
+	case formatZip:
+		zr, c, err := openZip(ctx, fsys, archivePath)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range zr.File {
+			if archiveMemberName(f.Name) != member {
+				continue
+			}
+			if f.FileInfo().IsDir() {
+				_ = c.Close()
+				return nil, archiveError(archivePath, member, ErrIsDir)
+			}
+			r, err := f.Open()
+			if err != nil {
+				_ = c.Close()
+				return nil, archiveError(archivePath, member, err)
+			}
+			return readCloser{r, multiCloser{r, c}}, nil
+		}
+		_ = c.Close()
+		return nil, archiveError(archivePath, member, ErrNotExist)
+
+	case formatTar, formatTarGzip:
+		tr, c, err := openTar(ctx, fsys, archivePath, format)
+		if err != nil {
+			return nil, err
+		}
+		for {
+			hdr, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				_ = c.Close()
+				return nil, archiveError(archivePath, member, err)
+			}
+			if archiveMemberName(hdr.Name) != member {
+				continue
+			}
+			if hdr.Typeflag == tar.TypeDir {
+				_ = c.Close()
+				return nil, archiveError(archivePath, member, ErrIsDir)
+			}
+			return readCloser{tr, c}, nil
+		}
+		_ = c.Close()
+		return nil, archiveError(archivePath, member, ErrNotExist)
+	}
+
+	return nil, &PathError{
+		Op:   "open",
+		Path: archivePath,
+		Err:  ErrUnsupported,
+	}
+}
+
+// openZip opens the named zip archive. The returned closer releases the
+// underlying file.
+func openZip(
+	ctx context.Context, fsys FS, name string,
+) (*zip.Reader, io.Closer, error) {
+	f, err := fsys.Open(ctx, name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ra, ok := f.(io.ReaderAt)
+	size := int64(-1)
+	if s, isSeeker := f.(io.Seeker); ok && isSeeker {
+		if size, err = s.Seek(0, io.SeekEnd); err != nil {
+			size = -1
+		}
+	}
+	if ok && size < 0 {
+		if info, err := Stat(ctx, fsys, name); err == nil {
+			size = info.Size()
+		}
+	}
+
+	var c io.Closer = f
+	if !ok || size < 0 {
+		// The archive cannot be read at random, so buffer it.
+		data, err := io.ReadAll(f)
+		closeErr := f.Close()
+		if err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return nil, nil, &PathError{Op: "open", Path: name, Err: err}
+		}
+		ra, size, c = bytes.NewReader(data), int64(len(data)), nopCloser{}
+	}
+
+	zr, err := zip.NewReader(ra, size)
+	if err != nil {
+		_ = c.Close()
+		return nil, nil, &PathError{Op: "open", Path: name, Err: err}
+	}
+	return zr, c, nil
+}
+
+// openTar opens the named tar archive, decompressing it if needed. The
+// returned closer releases the underlying file.
+func openTar(
+	ctx context.Context, fsys FS, name string, format archiveFormat,
+) (*tar.Reader, io.Closer, error) {
+	f, err := fsys.Open(ctx, name)
+	if err != nil {
+		return nil, nil, err
+	}
+	if format != formatTarGzip {
+		return tar.NewReader(f), f, nil
+	}
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		_ = f.Close()
+		return nil, nil, &PathError{Op: "open", Path: name, Err: err}
+	}
+	return tar.NewReader(gz), multiCloser{gz, f}, nil
+}
+
+// archiveMemberName normalizes an archive member name to a clean,
+// slash-separated path without leading "./" or "/" and without a trailing
+// slash.
+func archiveMemberName(name string) string {
+	name = strings.Trim(path.Clean("/"+name), "/")
+	if name == "" {
+		return "."
+	}
+	return name
+}
+
+func archiveError(archivePath, member string, err error) error {
+	return &PathError{
+		Op:   "open",
+		Path: path.Join(archivePath, member),
+		Err:  err,
+	}
+}
+
+// readCloser composes a reader with a separate closer.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// multiCloser closes each closer in order, returning the first error.
+type multiCloser []io.Closer
+
+func (mc multiCloser) Close() error {
+	var err error
+	for _, c := range mc {
+		if cerr := c.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+type nopCloser struct{}
+
+func (nopCloser) Close() error { return nil }
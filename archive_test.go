@@ -0,0 +1,188 @@
+package fs_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"testing"
+
+	"lesiw.io/fs"
+	"lesiw.io/fs/memfs"
+)
+
+type archiveFile struct {
+	name string
+	data string
+}
+
+var testArchiveFiles = []archiveFile{
+	{"README.md", "# Project"},
+	{"src/", ""},
+	{"src/main.go", "package main"},
+	{"src/util.go", "package util"},
+}
+
+func writeTestZip(t *testing.T, files []archiveFile) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, f := range files {
+		w, err := zw.Create(f.name)
+		if err != nil {
+			t.Fatalf("zip Create(%q) error = %v", f.name, err)
+		}
+		if _, err := io.WriteString(w, f.data); err != nil {
+			t.Fatalf("zip Write(%q) error = %v", f.name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("zip Close() error = %v", err)
+	}
+	return buf.Bytes()
+}
+
+func writeTestTar(t *testing.T, files []archiveFile) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, f := range files {
+		hdr := &tar.Header{
+			Name: f.name,
+			Mode: 0644,
+			Size: int64(len(f.data)),
+		}
+		if f.name[len(f.name)-1] == '/' {
+			hdr.Typeflag, hdr.Mode = tar.TypeDir, 0755
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatalf("tar WriteHeader(%q) error = %v", f.name, err)
+		}
+		if _, err := io.WriteString(tw, f.data); err != nil {
+			t.Fatalf("tar Write(%q) error = %v", f.name, err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("tar Close() error = %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestOpenArchiveMember(t *testing.T) {
+	ctx, fsys := context.Background(), memfs.New()
+
+	archives := map[string][]byte{
+		"archive.zip": writeTestZip(t, testArchiveFiles),
+		"archive.tar": writeTestTar(t, testArchiveFiles),
+	}
+	for name, data := range archives {
+		if err := fs.WriteFile(ctx, fsys, name, data); err != nil {
+			t.Fatalf("WriteFile(%q) error = %v", name, err)
+		}
+	}
+
+	for name := range archives {
+		t.Run(name, func(t *testing.T) {
+			r, err := fs.OpenArchiveMember(ctx, fsys, name, "src/main.go")
+			if err != nil {
+				t.Fatalf("OpenArchiveMember() error = %v", err)
+			}
+			closeOnCleanup(t, r)
+
+			got, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatalf("ReadAll() error = %v", err)
+			}
+			if want := "package main"; string(got) != want {
+				t.Errorf("ReadAll() = %q, want %q", got, want)
+			}
+
+			_, err = fs.OpenArchiveMember(ctx, fsys, name, "missing.txt")
+			if !errors.Is(err, fs.ErrNotExist) {
+				t.Errorf("OpenArchiveMember(missing) error = %v, "+
+					"want ErrNotExist", err)
+			}
+			_, err = fs.OpenArchiveMember(ctx, fsys, name, "src")
+			if !errors.Is(err, fs.ErrIsDir) {
+				t.Errorf("OpenArchiveMember(src) error = %v, "+
+					"want ErrIsDir", err)
+			}
+		})
+	}
+}
+
+func TestMountArchive(t *testing.T) {
+	ctx, fsys := context.Background(), memfs.New()
+
+	// Omit the explicit directory entry to exercise synthesized parents.
+	files := []archiveFile{
+		{"README.md", "# Project"},
+		{"src/main.go", "package main"},
+		{"src/util.go", "package util"},
+	}
+	archives := map[string][]byte{
+		"archive.zip": writeTestZip(t, files),
+		"archive.tar": writeTestTar(t, files),
+	}
+	for name, data := range archives {
+		if err := fs.WriteFile(ctx, fsys, name, data); err != nil {
+			t.Fatalf("WriteFile(%q) error = %v", name, err)
+		}
+	}
+
+	for name := range archives {
+		t.Run(name, func(t *testing.T) {
+			afs, err := fs.MountArchive(ctx, fsys, name)
+			if err != nil {
+				t.Fatalf("MountArchive() error = %v", err)
+			}
+			t.Cleanup(func() { _ = fs.Close(afs) })
+
+			var got []string
+			for e, err := range fs.ReadDir(ctx, afs, ".") {
+				if err != nil {
+					t.Fatalf("ReadDir() error = %v", err)
+				}
+				got = append(got, e.Name())
+			}
+			if want := []string{"README.md", "src"}; !slices.Equal(got, want) {
+				t.Errorf("ReadDir() = %v, want %v", got, want)
+			}
+
+			info, err := fs.Stat(ctx, afs, "src")
+			if err != nil {
+				t.Fatalf("Stat(src) error = %v", err)
+			}
+			if !info.IsDir() {
+				t.Errorf("Stat(src).IsDir() = false, want true")
+			}
+
+			data, err := fs.ReadFile(ctx, afs, "src/util.go")
+			if err != nil {
+				t.Fatalf("ReadFile() error = %v", err)
+			}
+			if want := "package util"; string(data) != want {
+				t.Errorf("ReadFile() = %q, want %q", data, want)
+			}
+
+			matches, err := fs.Glob(ctx, afs, "src/*.go")
+			if err != nil {
+				t.Fatalf("Glob() error = %v", err)
+			}
+			if len(matches) != 2 {
+				t.Errorf("Glob() = %v, want 2 matches", matches)
+			}
+
+			_, err = fs.Stat(ctx, afs, "missing.txt")
+			if !errors.Is(err, fs.ErrNotExist) {
+				t.Errorf("Stat(missing) error = %v, want ErrNotExist", err)
+			}
+			if _, err := afs.Open(ctx, "src"); !errors.Is(err, fs.ErrIsDir) {
+				t.Errorf("Open(src) error = %v, want ErrIsDir", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package fs
+
+// defaultBlockSize is the I/O size used when a file reports no preference.
+const defaultBlockSize = 32 * 1024
+
+// BlockSize returns the preferred I/O size for the file described by info,
+// suitable as a copy buffer size.
+//
+// If info has a BlockSize() int64 method that returns a positive value, that
+// value is used. Otherwise, BlockSize consults the platform stat data in
+// info.Sys(), such as st_blksize on Unix. If neither is available, BlockSize
+// returns 32KB.
+func BlockSize(info FileInfo) int64 {
+	if bs, ok := info.(interface{ BlockSize() int64 }); ok {
+		if n := bs.BlockSize(); n > 0 {
+			return n
+		}
+	}
+	if n := sysBlockSize(info.Sys()); n > 0 {
+		return n
+	}
+	return defaultBlockSize
+}
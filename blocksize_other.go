@@ -0,0 +1,5 @@
+//go:build !unix
+
+package fs
+
+func sysBlockSize(sys any) int64 { return 0 }
@@ -0,0 +1,51 @@
+//go:build unix
+
+package fs_test
+
+import (
+	"context"
+	"testing"
+
+	"lesiw.io/fs"
+	"lesiw.io/fs/memfs"
+	"lesiw.io/fs/osfs"
+)
+
+func TestBlockSizeOS(t *testing.T) {
+	fsys, ctx := osfs.NewTemp(), context.Background()
+	defer fs.Close(fsys)
+
+	if err := fs.WriteFile(ctx, fsys, "file.txt", []byte("data")); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	info, err := fs.Stat(ctx, fsys, "file.txt")
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	if got := fs.BlockSize(info); got <= 0 {
+		t.Errorf("BlockSize() = %d, want > 0", got)
+	}
+}
+
+type blockSizeInfo struct{ fs.FileInfo }
+
+func (blockSizeInfo) BlockSize() int64 { return 1 << 20 }
+
+func TestBlockSize(t *testing.T) {
+	ctx, fsys := context.Background(), memfs.New()
+
+	if err := fs.WriteFile(ctx, fsys, "file.txt", []byte("data")); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	info, err := fs.Stat(ctx, fsys, "file.txt")
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	if got, want := fs.BlockSize(info), int64(32*1024); got != want {
+		t.Errorf("BlockSize(memfs) = %d, want %d", got, want)
+	}
+	got := fs.BlockSize(blockSizeInfo{info})
+	if want := int64(1 << 20); got != want {
+		t.Errorf("BlockSize(BlockSize method) = %d, want %d", got, want)
+	}
+}
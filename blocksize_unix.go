@@ -0,0 +1,12 @@
+//go:build unix
+
+package fs
+
+import "syscall"
+
+func sysBlockSize(sys any) int64 {
+	if st, ok := sys.(*syscall.Stat_t); ok {
+		return int64(st.Blksize)
+	}
+	return 0
+}
@@ -0,0 +1,140 @@
+package fs
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+
+	"lesiw.io/fs/path"
+)
+
+// sidecarExt is the extension of the checksum sidecar of a file.
+const sidecarExt = ".sha256"
+
+// VerifyChecksum checks the named file against the SHA-256 digest in its
+// sidecar, name plus ".sha256", as written under [WithChecksumSidecar] or
+// by sha256sum. It returns an error wrapping [ErrVerifyFailed] if the
+// contents do not match, and one wrapping [ErrNotExist] if there is no
+// sidecar.
+//
+// Requires: [FS]
+func VerifyChecksum(ctx context.Context, fsys FS, name string) error {
+	var err error
+	if name, err = localizePath(ctx, fsys, name); err != nil {
+		return err
+	}
+	want, err := readSidecar(ctx, fsys, name)
+	if err != nil {
+		return err
+	}
+	r, err := fsys.Open(withoutSidecar(ctx), name)
+	if err != nil {
+		return newPathError("verify", name, err)
+	}
+	defer r.Close()
+	got := sha256.New()
+	if _, err := io.Copy(got, r); err != nil {
+		return &PathError{Op: "verify", Path: name, Err: err}
+	}
+	if !bytes.Equal(got.Sum(nil), want) {
+		return &PathError{Op: "verify", Path: name, Err: ErrVerifyFailed}
+	}
+	return nil
+}
+
+// withoutSidecar returns ctx with [WithChecksumSidecar] cleared. Wrappers
+// forward through the package helpers with the caller's ctx, so only the
+// outermost call may write or check a sidecar; the layers beneath it must
+// not add their own.
+func withoutSidecar(ctx context.Context) context.Context {
+	return context.WithValue(ctx, checksumSidecarKey, false)
+}
+
+// readSidecar returns the digest stored in the sidecar of the named file.
+func readSidecar(ctx context.Context, fsys FS, name string) ([]byte, error) {
+	data, err := ReadFile(withoutSidecar(ctx), fsys, name+sidecarExt)
+	if err != nil {
+		return nil, err
+	}
+	field, _, _ := bytes.Cut(bytes.TrimSpace(data), []byte(" "))
+	sum, err := hex.DecodeString(string(field))
+	if err != nil || len(sum) != sha256.Size {
+		return nil, &PathError{
+			Op:   "verify",
+			Path: name + sidecarExt,
+			Err:  ErrInvalid,
+		}
+	}
+	return sum, nil
+}
+
+// sidecarCloser digests the data written through it, and on Close writes
+// the digest to the sidecar of the file.
+type sidecarCloser struct {
+	io.WriteCloser
+	ctx  context.Context
+	fsys FS
+	name string
+	sum  hash.Hash
+}
+
+// sidecarOnClose wraps wc so that Close writes the sidecar of the named
+// file.
+func sidecarOnClose(
+	ctx context.Context, fsys FS, name string, wc io.WriteCloser,
+) io.WriteCloser {
+	return &sidecarCloser{wc, ctx, fsys, name, sha256.New()}
+}
+
+func (s *sidecarCloser) Write(p []byte) (int, error) {
+	n, err := s.WriteCloser.Write(p)
+	s.sum.Write(p[:n])
+	return n, err
+}
+
+func (s *sidecarCloser) Close() error {
+	if err := s.WriteCloser.Close(); err != nil {
+		return err
+	}
+	line := fmt.Sprintf("%x  %s\n", s.sum.Sum(nil), path.Base(s.name))
+	ctx := withoutSidecar(s.ctx)
+	return WriteFile(ctx, s.fsys, s.name+sidecarExt, []byte(line))
+}
+
+// verifySidecar wraps r so that reading to the end checks it against the
+// sidecar of the named file. Files without a sidecar are not checked.
+func verifySidecar(
+	ctx context.Context, fsys FS, name string, r io.ReadCloser,
+) (io.ReadCloser, error) {
+	want, err := readSidecar(ctx, fsys, name)
+	if errors.Is(err, ErrNotExist) {
+		return r, nil
+	} else if err != nil {
+		_ = r.Close()
+		return nil, err
+	}
+	return &sidecarReader{r, name, want, sha256.New()}, nil
+}
+
+// sidecarReader digests the data read through it and checks the digest at
+// EOF.
+type sidecarReader struct {
+	io.ReadCloser
+	name string
+	want []byte
+	sum  hash.Hash
+}
+
+func (s *sidecarReader) Read(p []byte) (int, error) {
+	n, err := s.ReadCloser.Read(p)
+	s.sum.Write(p[:n])
+	if err == io.EOF && !bytes.Equal(s.sum.Sum(nil), s.want) {
+		return n, &PathError{Op: "verify", Path: s.name, Err: ErrVerifyFailed}
+	}
+	return n, err
+}
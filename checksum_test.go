@@ -0,0 +1,113 @@
+package fs_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"lesiw.io/fs"
+	"lesiw.io/fs/memfs"
+)
+
+func TestChecksumSidecar(t *testing.T) {
+	ctx, fsys := context.Background(), memfs.New()
+	ctx = fs.WithChecksumSidecar(ctx)
+	data := []byte("archival data")
+
+	if err := fs.WriteFile(ctx, fsys, "dir/file.txt", data); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	sidecar, err := fs.ReadFile(ctx, fsys, "dir/file.txt.sha256")
+	if err != nil {
+		t.Fatalf("ReadFile(sidecar) error = %v", err)
+	}
+	if !strings.HasSuffix(string(sidecar), "  file.txt\n") {
+		t.Errorf("sidecar = %q, want sha256sum format", sidecar)
+	}
+	if err := fs.VerifyChecksum(ctx, fsys, "dir/file.txt"); err != nil {
+		t.Errorf("VerifyChecksum() error = %v", err)
+	}
+	got, err := fs.ReadFile(ctx, fsys, "dir/file.txt")
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if string(got) != string(data) {
+		t.Errorf("ReadFile() = %q, want %q", got, data)
+	}
+
+	// Corrupt the file behind the sidecar's back.
+	bg := context.Background()
+	err = fs.WriteFile(bg, fsys, "dir/file.txt", []byte("archival dat4"))
+	if err != nil {
+		t.Fatalf("WriteFile(corrupt) error = %v", err)
+	}
+	err = fs.VerifyChecksum(ctx, fsys, "dir/file.txt")
+	if !errors.Is(err, fs.ErrVerifyFailed) {
+		t.Errorf("VerifyChecksum() error = %v, want ErrVerifyFailed", err)
+	}
+	_, err = fs.ReadFile(ctx, fsys, "dir/file.txt")
+	if !errors.Is(err, fs.ErrVerifyFailed) {
+		t.Errorf("ReadFile() error = %v, want ErrVerifyFailed", err)
+	}
+	if _, err := fs.ReadFile(bg, fsys, "dir/file.txt"); err != nil {
+		t.Errorf("ReadFile() without sidecar option error = %v", err)
+	}
+}
+
+func TestVerifyChecksumMissing(t *testing.T) {
+	ctx, fsys := context.Background(), memfs.New()
+	if err := fs.WriteFile(ctx, fsys, "file.txt", nil); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	err := fs.VerifyChecksum(ctx, fsys, "file.txt")
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("VerifyChecksum() error = %v, want ErrNotExist", err)
+	}
+	got, err := fs.ReadFile(fs.WithChecksumSidecar(ctx), fsys, "file.txt")
+	if err != nil || len(got) != 0 {
+		t.Errorf("ReadFile() = %q, %v, want unchecked read", got, err)
+	}
+}
+
+func TestChecksumSidecarWrapped(t *testing.T) {
+	ctx := fs.WithChecksumSidecar(context.Background())
+	backend, fsys := newEncryptFS(t)
+	data := []byte("sealed data")
+
+	if err := fs.WriteFile(ctx, fsys, "f", data); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	got, err := fs.ReadFile(ctx, fsys, "f")
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if string(got) != string(data) {
+		t.Errorf("ReadFile() = %q, want %q", got, data)
+	}
+	if err := fs.VerifyChecksum(ctx, fsys, "f"); err != nil {
+		t.Errorf("VerifyChecksum() error = %v", err)
+	}
+	// The sidecar is written once, through the encrypting layer.
+	sidecar, err := fs.ReadFile(context.Background(), backend, "f.sha256")
+	if err != nil {
+		t.Fatalf("ReadFile(backend sidecar) error = %v", err)
+	}
+	if strings.HasSuffix(string(sidecar), "  f\n") {
+		t.Errorf("backend sidecar = %q, want ciphertext", sidecar)
+	}
+
+	rec, log := fs.Record(memfs.New())
+	if err := fs.WriteFile(ctx, rec, "g", data); err != nil {
+		t.Fatalf("WriteFile(recorded) error = %v", err)
+	}
+	var ops []string
+	for _, op := range log.Ops() {
+		ops = append(ops, op.Op+" "+op.Path)
+	}
+	want := []string{"create ./g", "create ./g.sha256"}
+	if !slices.Equal(ops, want) {
+		t.Errorf("log.Ops() = %v, want %v", ops, want)
+	}
+}
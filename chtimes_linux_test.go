@@ -0,0 +1,60 @@
+//go:build linux
+
+package fs_test
+
+import (
+	"context"
+	"syscall"
+	"testing"
+	"time"
+
+	"lesiw.io/fs"
+	"lesiw.io/fs/osfs"
+)
+
+func TestChtimes(t *testing.T) {
+	old := time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC)
+	set := time.Date(2002, 2, 2, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name         string
+		atime, mtime time.Time
+		wantA, wantM time.Time
+	}{
+		{"both", set, set, set, set},
+		{"atime only", set, time.Time{}, set, old},
+		{"mtime only", time.Time{}, set, old, set},
+		{"neither", time.Time{}, time.Time{}, old, old},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys, ctx := osfs.NewTemp(), context.Background()
+			defer fs.Close(fsys)
+			if err := fs.WriteFile(ctx, fsys, "f", nil); err != nil {
+				t.Fatalf("WriteFile() error = %v", err)
+			}
+			if err := fs.Chtimes(ctx, fsys, "f", old, old); err != nil {
+				t.Fatalf("Chtimes() error = %v", err)
+			}
+
+			err := fs.Chtimes(ctx, fsys, "f", tt.atime, tt.mtime)
+			if err != nil {
+				t.Fatalf("Chtimes() error = %v", err)
+			}
+
+			info, err := fs.Stat(ctx, fsys, "f")
+			if err != nil {
+				t.Fatalf("Stat() error = %v", err)
+			}
+			if got := info.ModTime(); !got.Equal(tt.wantM) {
+				t.Errorf("ModTime() = %v, want %v", got, tt.wantM)
+			}
+			st, ok := info.Sys().(*syscall.Stat_t)
+			if !ok {
+				t.Fatalf("Sys() = %T, want *syscall.Stat_t", info.Sys())
+			}
+			if got := time.Unix(st.Atim.Unix()); !got.Equal(tt.wantA) {
+				t.Errorf("atime = %v, want %v", got, tt.wantA)
+			}
+		})
+	}
+}
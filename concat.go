@@ -0,0 +1,74 @@
+package fs
+
+import (
+	"context"
+	"io"
+)
+
+// OpenConcat opens the named files for reading as a single stream of their
+// concatenated contents. Analogous to: [io.MultiReader], cat.
+//
+// Files are opened lazily, one at a time, in the order given. Each file is
+// closed as soon as it is exhausted, so at most one file is open at once.
+// The first error opening or reading a file is returned from Read, with the
+// offending path.
+//
+// The returned reader must be closed when done.
+//
+// Requires: [FS]
+func OpenConcat(
+	ctx context.Context, fsys FS, names ...string,
+) (io.ReadCloser, error) {
+	return &concatReader{ctx: ctx, fsys: fsys, names: names}, nil
+}
+
+type concatReader struct {
+	ctx   context.Context
+	fsys  FS
+	names []string
+	cur   ReadPathCloser
+	err   error
+}
+
+func (cr *concatReader) Read(p []byte) (int, error) {
+	for cr.err == nil {
+		if cr.cur == nil {
+			if len(cr.names) == 0 {
+				cr.err = io.EOF
+				break
+			}
+			cr.cur, cr.err = Open(cr.ctx, cr.fsys, cr.names[0])
+			cr.names = cr.names[1:]
+			continue
+		}
+		n, err := cr.cur.Read(p)
+		if err == io.EOF {
+			if err := cr.cur.Close(); err != nil {
+				cr.err = &PathError{Op: "close", Path: cr.cur.Path(), Err: err}
+			}
+			cr.cur = nil
+			if n > 0 {
+				return n, nil
+			}
+			continue
+		}
+		if err != nil {
+			cr.err = &PathError{Op: "read", Path: cr.cur.Path(), Err: err}
+		}
+		return n, nil
+	}
+	return 0, cr.err
+}
+
+func (cr *concatReader) Close() error {
+	cr.names = nil
+	if cr.err == nil {
+		cr.err = ErrClosed
+	}
+	if cr.cur == nil {
+		return nil
+	}
+	err := cr.cur.Close()
+	cr.cur = nil
+	return err
+}
@@ -0,0 +1,67 @@
+package fs_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"lesiw.io/fs"
+	"lesiw.io/fs/memfs"
+)
+
+func TestOpenConcat(t *testing.T) {
+	ctx, fsys := context.Background(), memfs.New()
+
+	for name, data := range map[string]string{
+		"a.log": "first\n",
+		"b.log": "second\n",
+		"c.log": "third\n",
+	} {
+		if err := fs.WriteFile(ctx, fsys, name, []byte(data)); err != nil {
+			t.Fatalf("WriteFile(%q) error = %v", name, err)
+		}
+	}
+
+	r, err := fs.OpenConcat(ctx, fsys, "a.log", "b.log", "c.log")
+	if err != nil {
+		t.Fatalf("OpenConcat() error = %v", err)
+	}
+	closeOnCleanup(t, r)
+
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	if want := "first\nsecond\nthird\n"; string(got) != want {
+		t.Errorf("ReadAll() = %q, want %q", got, want)
+	}
+}
+
+func TestOpenConcatMissing(t *testing.T) {
+	ctx, fsys := context.Background(), memfs.New()
+
+	for _, name := range []string{"a.log", "c.log"} {
+		if err := fs.WriteFile(ctx, fsys, name, []byte(name)); err != nil {
+			t.Fatalf("WriteFile(%q) error = %v", name, err)
+		}
+	}
+
+	r, err := fs.OpenConcat(ctx, fsys, "a.log", "b.log", "c.log")
+	if err != nil {
+		t.Fatalf("OpenConcat() error = %v", err)
+	}
+	closeOnCleanup(t, r)
+
+	got, err := io.ReadAll(r)
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("ReadAll() error = %v, want ErrNotExist", err)
+	}
+	if err != nil && !strings.Contains(err.Error(), "b.log") {
+		t.Errorf("ReadAll() error = %v, want path b.log", err)
+	}
+	if want := "a.log"; string(got) != want {
+		t.Errorf("ReadAll() = %q, want %q", got, want)
+	}
+}
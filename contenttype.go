@@ -0,0 +1,75 @@
+package fs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"lesiw.io/fs/path"
+)
+
+const (
+	// sniffLen is the number of bytes considered by http.DetectContentType.
+	sniffLen = 512
+
+	octetStream = "application/octet-stream"
+)
+
+// DetectContentType returns the MIME type of the named file, suitable for a
+// Content-Type header.
+// Analogous to: [http.DetectContentType], file --mime-type, S3 HeadObject.
+//
+// The type is taken from the first of these that is available:
+//   - The type stored by the backend, if the [FileInfo] returned by [Stat]
+//     has a ContentType() string method. An empty result, or the generic
+//     "application/octet-stream" that object stores assign by default, is
+//     treated as unknown.
+//   - The file's extension, via [mime.TypeByExtension].
+//   - The first 512 bytes of the file, via [http.DetectContentType]. Byte
+//     sniffing is enabled by default and can be disabled with
+//     [WithContentTypeSniff].
+//
+// If no type can be determined, DetectContentType returns
+// "application/octet-stream".
+//
+// Requires: [FS]
+func DetectContentType(
+	ctx context.Context, fsys FS, name string,
+) (string, error) {
+	if _, ok := fsys.(StatFS); ok {
+		info, err := Stat(ctx, fsys, name)
+		if errors.Is(err, ErrNotExist) {
+			return "", err
+		}
+		if ct, ok := info.(interface{ ContentType() string }); ok {
+			if t := ct.ContentType(); t != "" && t != octetStream {
+				return t, nil
+			}
+		}
+	}
+
+	base := path.Base(name)
+	if i := strings.LastIndexByte(base, '.'); i > 0 {
+		if t := mime.TypeByExtension(base[i:]); t != "" {
+			return t, nil
+		}
+	}
+
+	if !contentTypeSniff(ctx) {
+		return octetStream, nil
+	}
+	r, err := Open(ctx, fsys, name)
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+	buf := make([]byte, sniffLen)
+	n, err := io.ReadFull(r, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", &PathError{Op: "read", Path: name, Err: err}
+	}
+	return http.DetectContentType(buf[:n]), nil
+}
@@ -0,0 +1,81 @@
+package fs_test
+
+import (
+	"context"
+	"testing"
+
+	"lesiw.io/fs"
+	"lesiw.io/fs/memfs"
+)
+
+// typedFS reports a stored content type for every file, as S3 does.
+type typedFS struct {
+	fs.FS
+	contentType string
+}
+
+func (f typedFS) Stat(ctx context.Context, name string) (fs.FileInfo, error) {
+	info, err := fs.Stat(ctx, f.FS, name)
+	if err != nil {
+		return nil, err
+	}
+	return typedInfo{info, f.contentType}, nil
+}
+
+type typedInfo struct {
+	fs.FileInfo
+	contentType string
+}
+
+func (i typedInfo) ContentType() string { return i.contentType }
+
+func TestDetectContentType(t *testing.T) {
+	ctx, mem := context.Background(), memfs.New()
+	files := map[string]string{
+		"page.html": "plain words",
+		"image":     "\x89PNG\r\n\x1a\n",
+		"notes":     "just some text",
+	}
+	for name, data := range files {
+		if err := fs.WriteFile(ctx, mem, name, []byte(data)); err != nil {
+			t.Fatalf("WriteFile(%q) error = %v", name, err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		fsys fs.FS
+		ctx  context.Context
+		file string
+		want string
+	}{
+		{"stored", typedFS{mem, "image/webp"}, ctx, "page.html", "image/webp"},
+		{"generic stored", typedFS{mem, "application/octet-stream"}, ctx,
+			"page.html", "text/html; charset=utf-8"},
+		{"extension", mem, ctx, "page.html", "text/html; charset=utf-8"},
+		{"sniffed", mem, ctx, "image", "image/png"},
+		{"sniffed text", mem, ctx, "notes", "text/plain; charset=utf-8"},
+		{"no sniff", mem, fs.WithContentTypeSniff(ctx, false), "image",
+			"application/octet-stream"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := fs.DetectContentType(tt.ctx, tt.fsys, tt.file)
+			if err != nil {
+				t.Fatalf("DetectContentType(%q) error = %v", tt.file, err)
+			}
+			if got != tt.want {
+				t.Errorf("DetectContentType(%q) = %q, want %q",
+					tt.file, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectContentTypeNotExist(t *testing.T) {
+	ctx, fsys := context.Background(), memfs.New()
+
+	if _, err := fs.DetectContentType(ctx, fsys, "missing"); err == nil {
+		t.Error("DetectContentType(missing) error = nil, want error")
+	}
+}
@@ -12,6 +12,32 @@ const (
 	dirModeKey contextKey = iota
 	fileModeKey
 	workDirKey
+	idempotencyKey
+	requestIDKey
+	localTimesKey
+	userAgentKey
+	skipHiddenKey
+	walkSkipKey
+	sortedTarKey
+	reproducibleTarKey
+	contentTypeSniffKey
+	parentDirModeKey
+	syncOnCloseKey
+	precountKey
+	ignoreVanishedKey
+	stayOnFilesystemKey
+	readaheadKey
+	stripComponentsKey
+	contentEncodingKey
+	stripBOMKey
+	verifyWriteKey
+	umaskKey
+	tarNumericOwnerKey
+	osDefaultsKey
+	compressionKey
+	checksumSidecarKey
+	concurrencyKey
+	walkBudgetKey
 )
 
 // WithDirMode returns a context that carries a directory mode for automatic
@@ -32,22 +58,97 @@ func WithFileMode(ctx context.Context, mode Mode) context.Context {
 	return context.WithValue(ctx, fileModeKey, mode)
 }
 
-// DirMode retrieves the directory mode from context.
-// Returns 0755 if no mode is set.
+// WithOSDefaults returns a context that changes the default file and
+// directory modes to those of package os: 0666 for files, as with
+// [os.Create], and 0777 for directories, as with [os.MkdirAll], each with
+// the bits in the process umask cleared. This suits code migrating from
+// package os that expects files to be created with the same permissions.
+//
+// Modes set with [WithFileMode] and [WithDirMode] still take precedence,
+// and [Umask] applies on top of either. The process umask is read once,
+// on the first use of a context with this option, and never on platforms
+// without one.
+func WithOSDefaults(ctx context.Context) context.Context {
+	return context.WithValue(ctx, osDefaultsKey, true)
+}
+
+func osDefaults(ctx context.Context) bool {
+	v, _ := ctx.Value(osDefaultsKey).(bool)
+	return v
+}
+
+// DirMode retrieves the directory mode from context, with the permission
+// bits in [Umask] cleared. Returns 0755 if no mode is set, or 0777 less the
+// process umask under [WithOSDefaults].
 func DirMode(ctx context.Context) Mode {
 	if mode, ok := ctx.Value(dirModeKey).(Mode); ok {
-		return mode
+		return mode &^ Umask(ctx)
 	}
-	return 0755
+	if osDefaults(ctx) {
+		return 0777 &^ processUmask() &^ Umask(ctx)
+	}
+	return 0755 &^ Umask(ctx)
 }
 
-// FileMode retrieves the file mode from context.
-// Returns 0644 if no mode is set.
+// FileMode retrieves the file mode from context, with the permission bits
+// in [Umask] cleared. Returns 0644 if no mode is set, or 0666 less the
+// process umask under [WithOSDefaults].
 func FileMode(ctx context.Context) Mode {
 	if mode, ok := ctx.Value(fileModeKey).(Mode); ok {
-		return mode
+		return mode &^ Umask(ctx)
+	}
+	if osDefaults(ctx) {
+		return 0666 &^ processUmask() &^ Umask(ctx)
+	}
+	return 0644 &^ Umask(ctx)
+}
+
+// WithUmask returns a context that carries a umask: permission bits that
+// are cleared from the modes of files and directories created by [Create],
+// [Append], [Mkdir], [MkdirAll], and the implicit directory creation of
+// other operations. Like a process umask, it applies on top of the modes set
+// with [WithFileMode] and [WithDirMode]:
+//
+//	ctx = fs.WithFileMode(ctx, 0666)
+//	ctx = fs.WithUmask(ctx, 0022)
+//	fs.WriteFile(ctx, fsys, "file.txt", data)  // created 0644
+//
+// Explicit modes passed to [Chmod] are not masked. If no umask is set, it
+// is 0 and modes are used as given. The host's own umask may still apply
+// on backends such as osfs.
+func WithUmask(ctx context.Context, mask Mode) context.Context {
+	return context.WithValue(ctx, umaskKey, mask&ModePerm)
+}
+
+// Umask retrieves the umask from context.
+// Returns 0 if no umask is set.
+func Umask(ctx context.Context) Mode {
+	if mask, ok := ctx.Value(umaskKey).(Mode); ok {
+		return mask
 	}
-	return 0644
+	return 0
+}
+
+// WithParentDirMode returns a context that carries a directory mode for
+// the intermediate directories created by [MkdirAll]. The final directory
+// still uses [DirMode]:
+//
+//	ctx = fs.WithDirMode(ctx, 0700)
+//	ctx = fs.WithParentDirMode(ctx, 0755)
+//	fs.MkdirAll(ctx, fsys, "a/b/c")  // a and b are 0755, c is 0700
+//
+// If no parent directory mode is set, parents use DirMode.
+func WithParentDirMode(ctx context.Context, mode Mode) context.Context {
+	return context.WithValue(ctx, parentDirModeKey, mode)
+}
+
+// ParentDirMode retrieves the parent directory mode from context, with the
+// permission bits in [Umask] cleared. Returns [DirMode] if no mode is set.
+func ParentDirMode(ctx context.Context) Mode {
+	if mode, ok := ctx.Value(parentDirModeKey).(Mode); ok {
+		return mode &^ Umask(ctx)
+	}
+	return DirMode(ctx)
 }
 
 // WithWorkDir returns a context that carries a working directory for
@@ -77,3 +178,444 @@ func WorkDir(ctx context.Context) string {
 	}
 	return ""
 }
+
+// WithIdempotencyKey returns a context that carries an idempotency key for
+// write operations. Backends that support idempotent writes attach the key
+// to the request, so that a retried write with the same key is applied at
+// most once. Backends without idempotency support ignore it.
+//
+// A key identifies one logical write. Callers that retry a failed write
+// should reuse the same context, and therefore the same key.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKey, key)
+}
+
+// IdempotencyKey retrieves the idempotency key from context.
+// Returns an empty string if no key is set.
+func IdempotencyKey(ctx context.Context) string {
+	if key, ok := ctx.Value(idempotencyKey).(string); ok {
+		return key
+	}
+	return ""
+}
+
+// WithRequestID returns a context that carries a request ID for correlating
+// filesystem operations with backend logs and traces.
+//
+// By convention, backends that make network requests include the ID in
+// their outbound requests, for example as an X-Request-ID header. Backends
+// without a place to send it ignore it.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// RequestID retrieves the request ID from context.
+// Returns an empty string if no request ID is set.
+func RequestID(ctx context.Context) string {
+	if id, ok := ctx.Value(requestIDKey).(string); ok {
+		return id
+	}
+	return ""
+}
+
+// WithContentEncoding returns a context that carries a content encoding,
+// such as "gzip", for files written by [Create] and [WriteFile]. It labels
+// data that is already encoded; it does not encode anything itself.
+//
+// Backends that store object metadata, such as object stores, record it so
+// that HTTP clients decode the content transparently. Backends without
+// metadata ignore it.
+func WithContentEncoding(ctx context.Context, enc string) context.Context {
+	return context.WithValue(ctx, contentEncodingKey, enc)
+}
+
+// ContentEncoding retrieves the content encoding from context.
+// Returns an empty string if no encoding is set.
+func ContentEncoding(ctx context.Context) string {
+	if enc, ok := ctx.Value(contentEncodingKey).(string); ok {
+		return enc
+	}
+	return ""
+}
+
+// WithLocalTimes returns a context that disables ModTime normalization in
+// [Stat] and [Lstat], so that FileInfo values are returned exactly as the
+// backend reports them, in whatever time zone it uses.
+func WithLocalTimes(ctx context.Context) context.Context {
+	return context.WithValue(ctx, localTimesKey, true)
+}
+
+func localTimes(ctx context.Context) bool {
+	v, _ := ctx.Value(localTimesKey).(bool)
+	return v
+}
+
+// DefaultUserAgent is the User-Agent that HTTP-based backends send when the
+// context carries none.
+const DefaultUserAgent = "lesiw.io/fs/1"
+
+// WithUserAgent returns a context that carries a User-Agent for outbound
+// requests. It is a hint for HTTP-based backends, which send it in the
+// User-Agent header; other backends ignore it.
+func WithUserAgent(ctx context.Context, ua string) context.Context {
+	return context.WithValue(ctx, userAgentKey, ua)
+}
+
+// UserAgent retrieves the User-Agent from context.
+// Returns DefaultUserAgent if no User-Agent is set.
+func UserAgent(ctx context.Context) string {
+	if ua, ok := ctx.Value(userAgentKey).(string); ok {
+		return ua
+	}
+	return DefaultUserAgent
+}
+
+// WithSkipHidden returns a context that hides hidden files from [ReadDir],
+// [Walk], and [Glob]. A file is hidden if its name begins with a dot, or,
+// on Windows, if it has the hidden attribute.
+//
+// Hidden directories are not descended into. The filter is applied by the
+// helpers, so it works the same for every backend.
+func WithSkipHidden(ctx context.Context) context.Context {
+	return context.WithValue(ctx, skipHiddenKey, true)
+}
+
+func skipHidden(ctx context.Context) bool {
+	v, _ := ctx.Value(skipHiddenKey).(bool)
+	return v
+}
+
+// WithWalkSkip returns a context that prunes directories from [Walk]. When
+// skip returns true for a directory entry, the entry is still yielded but
+// its contents are not read.
+//
+// Pruning is applied by the ReadDir-based fallback of Walk. Filesystems
+// that implement [WalkFS] natively may ignore it.
+func WithWalkSkip(
+	ctx context.Context, skip func(DirEntry) bool,
+) context.Context {
+	return context.WithValue(ctx, walkSkipKey, skip)
+}
+
+func walkSkip(ctx context.Context) func(DirEntry) bool {
+	skip, _ := ctx.Value(walkSkipKey).(func(DirEntry) bool)
+	return skip
+}
+
+// WithSortedTar returns a context that makes tar streams built by [Open]
+// list their members in lexicographic order of their full paths, rather than
+// directory by directory. This suits consumers that compare or hash archives,
+// such as reproducible build tools.
+//
+// The whole directory listing is gathered before the first member is
+// written. Sorting applies when the stream is built from [ReadDirFS]; tar
+// streams produced natively through [DirFS] are passed through unchanged.
+func WithSortedTar(ctx context.Context) context.Context {
+	return context.WithValue(ctx, sortedTarKey, true)
+}
+
+func sortedTar(ctx context.Context) bool {
+	v, _ := ctx.Value(sortedTarKey).(bool)
+	return v
+}
+
+// WithReproducibleTar returns a context that makes tar streams built by
+// [Open] independent of when and by whom their files were written, so that
+// the same content always yields the same bytes. This suits build caches
+// keyed by a hash of a directory's tar stream.
+//
+// Member headers have their modification time set to the Unix epoch, their
+// access and change times cleared, and their owner and group reset to 0
+// with empty names. Modes are normalized to 0755 for directories and
+// executable files and 0644 for everything else. Combine it with
+// [WithSortedTar] for a fully canonical archive.
+//
+// Like WithSortedTar, it applies when the stream is built from [ReadDirFS].
+func WithReproducibleTar(ctx context.Context) context.Context {
+	return context.WithValue(ctx, reproducibleTarKey, true)
+}
+
+func reproducibleTar(ctx context.Context) bool {
+	v, _ := ctx.Value(reproducibleTarKey).(bool)
+	return v
+}
+
+// WithTarNumericOwner returns a context that makes tar streams carry file
+// ownership by number alone, for transport between hosts whose user
+// databases differ, like tar --numeric-owner.
+//
+// Tar streams built by [Open] keep each member's uid and gid but leave its
+// user and group names empty. Tar streams extracted by [Create] and
+// [Append] ignore member names and set each file's owner to the member's
+// uid and gid with [Chown], which usually requires privileges. Without this
+// option, extraction leaves ownership to the filesystem.
+//
+// Like [WithSortedTar], it applies when the stream is built from
+// [ReadDirFS] or extracted file by file; native [DirFS] and [AppendDirFS]
+// streams are passed through unchanged.
+func WithTarNumericOwner(ctx context.Context) context.Context {
+	return context.WithValue(ctx, tarNumericOwnerKey, true)
+}
+
+func tarNumericOwner(ctx context.Context) bool {
+	v, _ := ctx.Value(tarNumericOwnerKey).(bool)
+	return v
+}
+
+// A Compression is a compression format for the tar streams of directories.
+type Compression int
+
+// Compression formats for [WithCompression].
+const (
+	CompressionNone Compression = iota // Uncompressed tar
+	CompressionGzip                    // Gzip-compressed tar, as in .tar.gz
+)
+
+// WithCompression returns a context that compresses the tar streams of
+// directories with c: [Open] with a trailing slash returns a compressed
+// stream, and the writers returned by [Create] and [Append] with a trailing
+// slash expect one. This saves bandwidth when many small text files cross a
+// slow link.
+//
+// The streams built from [ReadDirFS] and extracted file by file apply the
+// compression themselves. Native [DirFS] and [AppendDirFS] implementations
+// receive the context and must honor [TarCompression] to support it.
+func WithCompression(ctx context.Context, c Compression) context.Context {
+	return context.WithValue(ctx, compressionKey, c)
+}
+
+// TarCompression retrieves the compression for directory tar streams from
+// context. Returns [CompressionNone] if none is set.
+func TarCompression(ctx context.Context) Compression {
+	c, _ := ctx.Value(compressionKey).(Compression)
+	return c
+}
+
+// WithContentTypeSniff returns a context that enables or disables reading
+// file contents in [DetectContentType] when the type cannot be determined
+// from metadata or the file extension. Sniffing is enabled by default.
+func WithContentTypeSniff(ctx context.Context, sniff bool) context.Context {
+	return context.WithValue(ctx, contentTypeSniffKey, sniff)
+}
+
+func contentTypeSniff(ctx context.Context) bool {
+	if v, ok := ctx.Value(contentTypeSniffKey).(bool); ok {
+		return v
+	}
+	return true
+}
+
+// WithSyncOnClose returns a context that makes files written by [Create]
+// and [WriteFile] durable before Close returns. Writers that have a
+// Sync method, such as [os.File], are synced to stable storage before they
+// are closed; a failed sync is reported by Close.
+//
+// Syncing waits for the device to acknowledge the write, which can make
+// each Close orders of magnitude slower, so reserve it for data that must
+// survive a crash. Backends whose writers have no Sync method, such as
+// object stores that upload on Close, already hold the data durably once
+// Close succeeds and are unaffected.
+func WithSyncOnClose(ctx context.Context) context.Context {
+	return context.WithValue(ctx, syncOnCloseKey, true)
+}
+
+func syncOnClose(ctx context.Context) bool {
+	v, _ := ctx.Value(syncOnCloseKey).(bool)
+	return v
+}
+
+// WithPrecount returns a context that lets [WalkWithCount] walk the tree
+// once to count its entries before returning the iterator. The precount
+// roughly doubles the cost of the walk.
+func WithPrecount(ctx context.Context) context.Context {
+	return context.WithValue(ctx, precountKey, true)
+}
+
+func precount(ctx context.Context) bool {
+	v, _ := ctx.Value(precountKey).(bool)
+	return v
+}
+
+// WithIgnoreVanished returns a context that makes [Walk] silently skip
+// files and directories that are removed while the walk is in progress,
+// instead of yielding an error for each. This is common when walking a
+// live tree, such as a build or temporary directory. The root itself must
+// still exist.
+//
+// It applies when the walk is built from [ReadDirFS].
+func WithIgnoreVanished(ctx context.Context) context.Context {
+	return context.WithValue(ctx, ignoreVanishedKey, true)
+}
+
+func ignoreVanished(ctx context.Context) bool {
+	v, _ := ctx.Value(ignoreVanishedKey).(bool)
+	return v
+}
+
+// WithWalkBudget returns a context that caps the number of entries [Walk]
+// yields at maxEntries, to bound the cost of walking a tree chosen by an
+// untrusted caller. Once maxEntries entries have been yielded, a further
+// entry ends the walk with an error wrapping [ErrWalkBudgetExceeded] in its
+// place. Errors yielded for unreadable entries do not count against the
+// budget.
+//
+// The budget is enforced by Walk itself, so it applies to native [WalkFS]
+// implementations as well as to the fallback. Implementations may also read
+// it to stop listing early. A budget less than 1 is no budget.
+func WithWalkBudget(ctx context.Context, maxEntries int) context.Context {
+	return context.WithValue(ctx, walkBudgetKey, maxEntries)
+}
+
+func walkBudget(ctx context.Context) int {
+	n, _ := ctx.Value(walkBudgetKey).(int)
+	return n
+}
+
+// WithConcurrency returns a context that lets operations issue up to n
+// requests to the filesystem at once. [Walk] built on [ReadDirFS] reads up
+// to n directories ahead of the one being yielded, which hides the latency
+// of backends where each listing is a network round trip, such as S3 and
+// WebDAV. Entries are yielded in the same order regardless of n.
+//
+// If no concurrency is set, or n is less than 1, operations issue one
+// request at a time.
+func WithConcurrency(ctx context.Context, n int) context.Context {
+	return context.WithValue(ctx, concurrencyKey, n)
+}
+
+// Concurrency retrieves the number of concurrent requests from context.
+// Returns 1 if none is set.
+func Concurrency(ctx context.Context) int {
+	if n, ok := ctx.Value(concurrencyKey).(int); ok && n > 1 {
+		return n
+	}
+	return 1
+}
+
+// WithStayOnFilesystem returns a context that makes [Walk] stay on the file
+// system of its root, like du -x or find -xdev. Mount points below the root
+// are still yielded, but their contents are not.
+//
+// It applies when the walk is built from [ReadDirFS], and detects mount
+// points with [IsMountPoint].
+func WithStayOnFilesystem(ctx context.Context) context.Context {
+	return context.WithValue(ctx, stayOnFilesystemKey, true)
+}
+
+func stayOnFilesystem(ctx context.Context) bool {
+	v, _ := ctx.Value(stayOnFilesystemKey).(bool)
+	return v
+}
+
+// WithReadahead returns a context that makes [Open] prefetch up to n bytes
+// of a file in the background while the caller processes what it has
+// already read, overlapping network latency with computation. A value of
+// zero or less disables readahead, which is the default.
+//
+// The prefetching reader does not implement [io.Seeker]. Local files opened
+// as [os.File] are returned unchanged, since the kernel already reads them
+// ahead.
+func WithReadahead(ctx context.Context, n int) context.Context {
+	return context.WithValue(ctx, readaheadKey, n)
+}
+
+func readahead(ctx context.Context) int {
+	v, _ := ctx.Value(readaheadKey).(int)
+	return v
+}
+
+// WithStripComponents returns a context that makes tar streams written to a
+// directory by [Create] and [Append] drop the first n elements of each
+// member's path, like tar --strip-components. Members with n or fewer
+// elements, such as the wrapper directory itself, are skipped.
+//
+// Stripping happens during extraction, so while it is set, archives are
+// extracted one member at a time even if fsys implements [AppendDirFS].
+func WithStripComponents(ctx context.Context, n int) context.Context {
+	return context.WithValue(ctx, stripComponentsKey, n)
+}
+
+func stripComponents(ctx context.Context) int {
+	v, _ := ctx.Value(stripComponentsKey).(int)
+	return v
+}
+
+// backendOptionKey namespaces the keys of [WithBackendOption], so that they
+// never collide with other context values.
+type backendOptionKey struct{ key any }
+
+// WithBackendOption returns a context that carries value for the backend
+// option identified by key. It lets a backend accept per-operation
+// settings of its own, such as an object store's storage class, without
+// this package knowing about them. Backends that do not recognize key
+// ignore it.
+//
+// As with [context.WithValue], key must be comparable, and a backend should
+// define its keys with an exported constant of an unexported or
+// backend-specific type, so that keys from different backends are distinct:
+//
+//	ctx = fs.WithBackendOption(ctx, s3.StorageClass, "STANDARD_IA")
+func WithBackendOption(ctx context.Context, key, value any) context.Context {
+	return context.WithValue(ctx, backendOptionKey{key}, value)
+}
+
+// BackendOption retrieves the value of the backend option identified by key
+// from context. Returns nil if the option is not set.
+func BackendOption(ctx context.Context, key any) any {
+	return ctx.Value(backendOptionKey{key})
+}
+
+// WithStripBOM returns a context that makes [ReadFile] remove a leading
+// byte order mark. A UTF-8 BOM is dropped, and UTF-16 content marked with
+// a little- or big-endian BOM is decoded to UTF-8.
+//
+// Files without a BOM are returned unchanged. It is off by default, since
+// binary data may begin with the same bytes.
+func WithStripBOM(ctx context.Context) context.Context {
+	return context.WithValue(ctx, stripBOMKey, true)
+}
+
+func stripBOM(ctx context.Context) bool {
+	v, _ := ctx.Value(stripBOMKey).(bool)
+	return v
+}
+
+// WithVerifyWrite returns a context that makes files written by [Create]
+// and [WriteFile] check their contents when closed. Close returns an error
+// wrapping [ErrVerifyFailed] if the file does not hold the data written.
+//
+// If [StatFS] reports an ETag that is the MD5 digest of the data, as for
+// single-part S3 uploads, the file is taken as verified. Otherwise it is
+// read back in full, which doubles the cost of every write, so reserve it
+// for backends that are not trusted to store data faithfully.
+func WithVerifyWrite(ctx context.Context) context.Context {
+	return context.WithValue(ctx, verifyWriteKey, true)
+}
+
+func verifyWrite(ctx context.Context) bool {
+	v, _ := ctx.Value(verifyWriteKey).(bool)
+	return v
+}
+
+// WithChecksumSidecar returns a context that keeps a SHA-256 digest of each
+// file beside it, for end-to-end integrity on backends that report no
+// checksums of their own.
+//
+// Files written by [Create] and [WriteFile] are followed by a sidecar file
+// with the same name plus ".sha256", in the format of sha256sum, once they
+// are closed. Files read by [Open] and [ReadFile] are checked against their
+// sidecar, if there is one: the read that reaches the end of the file
+// returns an error wrapping [ErrVerifyFailed] if the contents do not match.
+// Files are checked as they are read, so the reader does not seek.
+//
+// Sidecars are not renamed, removed, or updated along with their files, so
+// a file changed by other means, such as a native [AppendFS], no longer
+// verifies. Use [VerifyChecksum] to check a file explicitly.
+func WithChecksumSidecar(ctx context.Context) context.Context {
+	return context.WithValue(ctx, checksumSidecarKey, true)
+}
+
+func checksumSidecar(ctx context.Context) bool {
+	v, _ := ctx.Value(checksumSidecarKey).(bool)
+	return v
+}
@@ -85,3 +85,73 @@ func ExampleDirMode() {
 	// Output:
 	// Mode: 0700
 }
+
+func TestUmask(t *testing.T) {
+	ctx := t.Context()
+
+	if got := fs.Umask(ctx); got != 0 {
+		t.Errorf("Umask(ctx) = %04o, want 0", got)
+	}
+
+	ctx = fs.WithUmask(ctx, 0o022)
+	if got, want := fs.FileMode(ctx), fs.Mode(0o644); got != want {
+		t.Errorf("FileMode(ctx) = %04o, want %04o", got, want)
+	}
+	ctx = fs.WithFileMode(fs.WithDirMode(ctx, 0o777), 0o666)
+	ctx = fs.WithParentDirMode(ctx, 0o775)
+	for _, tt := range []struct {
+		name      string
+		got, want fs.Mode
+	}{
+		{"FileMode", fs.FileMode(ctx), 0o644},
+		{"DirMode", fs.DirMode(ctx), 0o755},
+		{"ParentDirMode", fs.ParentDirMode(ctx), 0o755},
+	} {
+		if tt.got != tt.want {
+			t.Errorf("%s(ctx) = %04o, want %04o", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestIdempotencyKey(t *testing.T) {
+	ctx := t.Context()
+
+	if got := fs.IdempotencyKey(ctx); got != "" {
+		t.Errorf("IdempotencyKey(ctx) = %q, want empty", got)
+	}
+
+	ctx = fs.WithIdempotencyKey(ctx, "write-1")
+	ctx = fs.WithFileMode(ctx, 0600)
+	if got, want := fs.IdempotencyKey(ctx), "write-1"; got != want {
+		t.Errorf("IdempotencyKey(ctx) = %q, want %q", got, want)
+	}
+}
+
+func TestContentEncoding(t *testing.T) {
+	ctx := t.Context()
+
+	if got := fs.ContentEncoding(ctx); got != "" {
+		t.Errorf("ContentEncoding(ctx) = %q, want empty", got)
+	}
+
+	ctx = fs.WithContentEncoding(ctx, "gzip")
+	if got, want := fs.ContentEncoding(ctx), "gzip"; got != want {
+		t.Errorf("ContentEncoding(ctx) = %q, want %q", got, want)
+	}
+}
+
+func TestBackendOption(t *testing.T) {
+	type key int
+	ctx := context.WithValue(t.Context(), key(0), "plain")
+	ctx = fs.WithBackendOption(ctx, key(1), "class")
+
+	if got := fs.BackendOption(ctx, key(1)); got != "class" {
+		t.Errorf("BackendOption(1) = %v, want %q", got, "class")
+	}
+	if got := fs.BackendOption(ctx, key(0)); got != nil {
+		t.Errorf("BackendOption(0) = %v, want nil", got)
+	}
+	if got := ctx.Value(key(1)); got != nil {
+		t.Errorf("Value(1) = %v, want nil", got)
+	}
+}
@@ -0,0 +1,113 @@
+package fs
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"lesiw.io/fs/path"
+)
+
+// A CopyFS is a file system with the Copy method.
+//
+// Backends that can copy a file without sending its contents through the
+// client, such as object stores with a server-side copy, implement CopyFS.
+type CopyFS interface {
+	FS
+
+	// Copy copies the file src to dst. If dst already exists, it is
+	// replaced. The parent directory of dst must exist.
+	Copy(ctx context.Context, src, dst string) error
+}
+
+// Copy copies the named file src to dst.
+// Analogous to: cp, cp -r, S3 CopyObject.
+//
+// If dst already exists, it is replaced. If the parent directory of dst does
+// not exist and the filesystem implements [MkdirFS], it is created, as in
+// [Create].
+//
+// # Files
+//
+// Without [CopyFS], or if its Copy returns [ErrUnsupported], the file is
+// read with [Open] and written with [Create], so its contents pass through
+// the caller, in buffers of the destination's [BlockSize].
+//
+// Requires: [CopyFS] || ([FS] && [CreateFS])
+//
+// # Directories
+//
+// A trailing slash on src copies the directory tree into dst, which is
+// treated as a directory. Without a native Copy, src is read as a tar stream
+// and extracted with [Append], so files already in dst are kept unless the
+// copy overwrites them.
+//
+// Requires: [CopyFS] || (See [Open] and [Append] requirements)
+func Copy(ctx context.Context, fsys FS, src, dst string) error {
+	var err error
+	if src, err = localizePath(ctx, fsys, src); err != nil {
+		return err
+	}
+	if dst, err = localizePath(ctx, fsys, dst); err != nil {
+		return err
+	}
+	if path.IsDir(src) && !path.IsDir(dst) {
+		dst = path.Join(dst, "")
+	}
+	if cfs, ok := fsys.(CopyFS); ok {
+		err := cfs.Copy(ctx, src, dst)
+		if errors.Is(err, ErrNotExist) && !path.IsDir(dst) {
+			// The source may exist, but not the parent of dst.
+			if dir := path.Dir(dst); dir != "." && dir != dst {
+				if merr := MkdirAll(ctx, fsys, dir); merr == nil {
+					err = cfs.Copy(ctx, src, dst)
+				}
+			}
+		}
+		if !errors.Is(err, ErrUnsupported) {
+			return newPathError("copy", src, err)
+		}
+	}
+	return copyStream(ctx, fsys, src, dst)
+}
+
+// copyStream copies src to dst through the client. Directories are copied
+// as a tar stream.
+func copyStream(ctx context.Context, fsys FS, src, dst string) error {
+	r, err := Open(ctx, fsys, src)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	var w WritePathCloser
+	if path.IsDir(dst) {
+		w, err = Append(ctx, fsys, dst)
+	} else {
+		w, err = Create(ctx, fsys, dst)
+	}
+	if err != nil {
+		return err
+	}
+	buf := make([]byte, copyBufferSize(ctx, fsys, src, dst))
+	_, err = io.CopyBuffer(w, r, buf)
+	closeErr := w.Close()
+	if err != nil {
+		return &PathError{Op: "copy", Path: src, Err: err}
+	}
+	if closeErr != nil {
+		return &PathError{Op: "copy", Path: dst, Err: closeErr}
+	}
+	return nil
+}
+
+// copyBufferSize returns the [BlockSize] of dst, which exists once it has
+// been created, or else of src.
+func copyBufferSize(ctx context.Context, fsys FS, src, dst string) int64 {
+	for _, name := range []string{dst, src} {
+		if info, err := Stat(ctx, fsys, name); err == nil {
+			return BlockSize(info)
+		}
+	}
+	return defaultBlockSize
+}
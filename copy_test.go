@@ -0,0 +1,210 @@
+package fs_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"testing"
+
+	"lesiw.io/fs"
+	"lesiw.io/fs/memfs"
+)
+
+func TestCopy(t *testing.T) {
+	ctx, fsys := context.Background(), memfs.New()
+	if err := fs.WriteFile(ctx, fsys, "a.txt", []byte("hello")); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	if err := fs.Copy(ctx, fsys, "a.txt", "sub/dir/b.txt"); err != nil {
+		t.Fatalf("Copy() error = %v", err)
+	}
+	for _, name := range []string{"a.txt", "sub/dir/b.txt"} {
+		data, err := fs.ReadFile(ctx, fsys, name)
+		if err != nil {
+			t.Fatalf("ReadFile(%q) error = %v", name, err)
+		}
+		if got, want := string(data), "hello"; got != want {
+			t.Errorf("ReadFile(%q) = %q, want %q", name, got, want)
+		}
+	}
+
+	err := fs.Copy(ctx, fsys, "missing.txt", "c.txt")
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Copy(missing) error = %v, want ErrNotExist", err)
+	}
+}
+
+func TestCopyDir(t *testing.T) {
+	ctx, fsys := context.Background(), memfs.New()
+	for name, data := range map[string]string{
+		"src/x.txt":    "x",
+		"src/y/z.txt":  "z",
+		"dst/keep.txt": "keep",
+	} {
+		if err := fs.WriteFile(ctx, fsys, name, []byte(data)); err != nil {
+			t.Fatalf("WriteFile(%q) error = %v", name, err)
+		}
+	}
+
+	if err := fs.Copy(ctx, fsys, "src/", "dst"); err != nil {
+		t.Fatalf("Copy() error = %v", err)
+	}
+	for name, want := range map[string]string{
+		"dst/x.txt":    "x",
+		"dst/y/z.txt":  "z",
+		"dst/keep.txt": "keep",
+		"src/x.txt":    "x",
+	} {
+		data, err := fs.ReadFile(ctx, fsys, name)
+		if err != nil {
+			t.Fatalf("ReadFile(%q) error = %v", name, err)
+		}
+		if got := string(data); got != want {
+			t.Errorf("ReadFile(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+// serverCopyFS copies files natively and counts the files opened through
+// the client. With unsupported set, its Copy reports ErrUnsupported.
+type serverCopyFS struct {
+	fs.FS
+	unsupported bool
+	copies      int
+	opens       int
+}
+
+func (f *serverCopyFS) Open(ctx context.Context, name string) (
+	io.ReadCloser, error,
+) {
+	f.opens++
+	return f.FS.Open(ctx, name)
+}
+
+func (f *serverCopyFS) Create(ctx context.Context, name string) (
+	io.WriteCloser, error,
+) {
+	return fs.Create(ctx, f.FS, name)
+}
+
+func (f *serverCopyFS) Copy(ctx context.Context, src, dst string) error {
+	if f.unsupported {
+		return fs.ErrUnsupported
+	}
+	f.copies++
+	data, err := fs.ReadFile(ctx, f.FS, src)
+	if err != nil {
+		return err
+	}
+	return fs.WriteFile(ctx, f.FS, dst, data)
+}
+
+func TestCopyNative(t *testing.T) {
+	for _, unsupported := range []bool{false, true} {
+		ctx := context.Background()
+		fsys := &serverCopyFS{FS: memfs.New(), unsupported: unsupported}
+		err := fs.WriteFile(ctx, fsys.FS, "a.txt", []byte("hello"))
+		if err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+
+		if err := fs.Copy(ctx, fsys, "a.txt", "b.txt"); err != nil {
+			t.Fatalf("Copy() error = %v", err)
+		}
+		data, err := fs.ReadFile(ctx, fsys.FS, "b.txt")
+		if err != nil {
+			t.Fatalf("ReadFile() error = %v", err)
+		}
+		if got, want := string(data), "hello"; got != want {
+			t.Errorf("ReadFile() = %q, want %q", got, want)
+		}
+
+		wantCopies, wantOpens := 1, 0
+		if unsupported {
+			wantCopies, wantOpens = 0, 1
+		}
+		if fsys.copies != wantCopies || fsys.opens != wantOpens {
+			t.Errorf(
+				"unsupported=%v: copies, opens = %d, %d, want %d, %d",
+				unsupported, fsys.copies, fsys.opens, wantCopies, wantOpens,
+			)
+		}
+	}
+}
+
+// blockFS reports a block size of 7 for every file and records the size of
+// each write to its files. Its readers hide io.WriterTo, so that copies go
+// through a buffer.
+type blockFS struct {
+	fs.FS
+	writes []int
+}
+
+type blockInfo struct{ fs.FileInfo }
+
+func (blockInfo) BlockSize() int64 { return 7 }
+
+func (f *blockFS) Open(ctx context.Context, name string) (
+	io.ReadCloser, error,
+) {
+	r, err := f.FS.Open(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return struct{ io.ReadCloser }{r}, nil
+}
+
+func (f *blockFS) Create(ctx context.Context, name string) (
+	io.WriteCloser, error,
+) {
+	w, err := fs.Create(ctx, f.FS, name)
+	if err != nil {
+		return nil, err
+	}
+	return &blockWriter{w, f}, nil
+}
+
+func (f *blockFS) Stat(ctx context.Context, name string) (
+	fs.FileInfo, error,
+) {
+	info, err := fs.Stat(ctx, f.FS, name)
+	if err != nil {
+		return nil, err
+	}
+	return blockInfo{info}, nil
+}
+
+type blockWriter struct {
+	io.WriteCloser
+	fsys *blockFS
+}
+
+func (w *blockWriter) Write(p []byte) (int, error) {
+	w.fsys.writes = append(w.fsys.writes, len(p))
+	return w.WriteCloser.Write(p)
+}
+
+func TestCopyBlockSize(t *testing.T) {
+	ctx, fsys := context.Background(), &blockFS{FS: memfs.New()}
+	data := []byte("twenty bytes of data")
+	if err := fs.WriteFile(ctx, fsys, "a.txt", data); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	fsys.writes = nil
+
+	if err := fs.Copy(ctx, fsys, "a.txt", "b.txt"); err != nil {
+		t.Fatalf("Copy() error = %v", err)
+	}
+	if want := []int{7, 7, 6}; !slices.Equal(fsys.writes, want) {
+		t.Errorf("Copy() writes = %v, want %v", fsys.writes, want)
+	}
+	got, err := fs.ReadFile(ctx, fsys, "b.txt")
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if string(got) != string(data) {
+		t.Errorf("ReadFile() = %q, want %q", got, data)
+	}
+}
@@ -0,0 +1,48 @@
+package fs
+
+import (
+	"context"
+	"errors"
+)
+
+// A CountFS is a file system with the Count method.
+//
+// Backends that can report the size of a directory without listing it, or
+// more cheaply than yielding each entry, implement CountFS.
+type CountFS interface {
+	FS
+
+	// Count returns the number of entries in the named directory.
+	Count(ctx context.Context, dir string) (int, error)
+}
+
+// Count returns the number of entries in the named directory.
+// Analogous to: ls | wc -l.
+//
+// If fsys does not implement [CountFS], or Count returns [ErrUnsupported],
+// Count reads the whole directory with [ReadDir] and counts its entries,
+// which takes time proportional to the size of the directory. The fallback
+// is also used under [WithSkipHidden], so that hidden entries are not
+// counted.
+//
+// Requires: [CountFS] || [ReadDirFS] || [WalkFS]
+func Count(ctx context.Context, fsys FS, dir string) (int, error) {
+	var err error
+	if dir, err = localizePath(ctx, fsys, dir); err != nil {
+		return 0, err
+	}
+	if cfs, ok := fsys.(CountFS); ok && !skipHidden(ctx) {
+		n, err := cfs.Count(ctx, dir)
+		if !errors.Is(err, ErrUnsupported) {
+			return n, newPathError("count", dir, err)
+		}
+	}
+	var n int
+	for _, err := range ReadDir(ctx, fsys, dir) {
+		if err != nil {
+			return 0, err
+		}
+		n++
+	}
+	return n, nil
+}
@@ -0,0 +1,110 @@
+package fs_test
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"testing"
+
+	"lesiw.io/fs"
+	"lesiw.io/fs/memfs"
+	"lesiw.io/fs/osfs"
+)
+
+// countFS reports a fixed count, or delegates to ReadDir when count is
+// negative, and records which path was taken.
+type countFS struct {
+	fs.FS
+	count         int
+	counts, reads int
+}
+
+func (f *countFS) Count(ctx context.Context, dir string) (int, error) {
+	f.counts++
+	if f.count < 0 {
+		return 0, fs.ErrUnsupported
+	}
+	return f.count, nil
+}
+
+func (f *countFS) ReadDir(
+	ctx context.Context, dir string,
+) iter.Seq2[fs.DirEntry, error] {
+	f.reads++
+	return fs.ReadDir(ctx, f.FS, dir)
+}
+
+func TestCount(t *testing.T) {
+	ctx, fsys := context.Background(), memfs.New()
+	for _, name := range []string{"dir/a", "dir/b", "dir/sub/c"} {
+		if err := fs.WriteFile(ctx, fsys, name, nil); err != nil {
+			t.Fatalf("WriteFile(%q) error = %v", name, err)
+		}
+	}
+
+	n, err := fs.Count(ctx, fsys, "dir")
+	if err != nil {
+		t.Fatalf("Count() error = %v", err)
+	}
+	if want := 3; n != want {
+		t.Errorf("Count() = %d, want %d", n, want)
+	}
+
+	if _, err := fs.Count(ctx, fsys, "dir/a"); !errors.Is(err, fs.ErrNotDir) {
+		t.Errorf("Count(file) error = %v, want ErrNotDir", err)
+	}
+}
+
+func TestCountNative(t *testing.T) {
+	ctx := context.Background()
+	fsys := &countFS{FS: memfs.New(), count: 42}
+
+	n, err := fs.Count(ctx, fsys, ".")
+	if err != nil {
+		t.Fatalf("Count() error = %v", err)
+	}
+	if n != 42 || fsys.counts != 1 || fsys.reads != 0 {
+		t.Errorf("Count() = %d with %d Count and %d ReadDir calls, "+
+			"want 42 with 1 and 0", n, fsys.counts, fsys.reads)
+	}
+}
+
+func TestCountFallback(t *testing.T) {
+	ctx, mem := context.Background(), memfs.New()
+	for _, name := range []string{"a", "b"} {
+		if err := fs.WriteFile(ctx, mem, name, nil); err != nil {
+			t.Fatalf("WriteFile(%q) error = %v", name, err)
+		}
+	}
+	fsys := &countFS{FS: mem, count: -1}
+
+	n, err := fs.Count(ctx, fsys, ".")
+	if err != nil {
+		t.Fatalf("Count() error = %v", err)
+	}
+	if n != 2 || fsys.counts != 1 || fsys.reads != 1 {
+		t.Errorf("Count() = %d with %d Count and %d ReadDir calls, "+
+			"want 2 with 1 and 1", n, fsys.counts, fsys.reads)
+	}
+}
+
+func TestCountOS(t *testing.T) {
+	fsys, ctx := osfs.NewTemp(), context.Background()
+	t.Cleanup(func() { fs.Close(fsys) })
+	for _, name := range []string{"a", "b", "c"} {
+		if err := fs.WriteFile(ctx, fsys, name, nil); err != nil {
+			t.Fatalf("WriteFile(%q) error = %v", name, err)
+		}
+	}
+
+	n, err := fs.Count(ctx, fsys, ".")
+	if err != nil {
+		t.Fatalf("Count() error = %v", err)
+	}
+	if want := 3; n != want {
+		t.Errorf("Count() = %d, want %d", n, want)
+	}
+	if _, err := fs.Count(ctx, fsys, "a"); !errors.Is(err, fs.ErrNotDir) {
+		t.Errorf("Count(file) error = %v, want ErrNotDir", err)
+	}
+}
@@ -30,13 +30,18 @@ type CreateFS interface {
 // mode 0755 (or the mode specified via [WithDirMode]).
 //
 // The returned [WritePathCloser] must be closed when done. The Path() method
-// returns the native filesystem path, or the input path if localization is
-// not supported.
+// returns the native filesystem path, or the input path if localization is not
+// supported. If the backend's stream reports its own path via [Pather], such as
+// a normalized object key, that path is returned instead.
 //
 // # Files
 //
 // If the file already exists, it is truncated. If the file does not exist,
 // it is created with mode 0644 (or the mode specified via [WithFileMode]).
+// Under [WithSyncOnClose], Close syncs the file to stable storage first.
+// Under [WithVerifyWrite], Close checks that the file holds the data
+// written. Under [WithChecksumSidecar], Close also writes a digest of the
+// data beside the file.
 //
 // Requires: [CreateFS]
 //
@@ -71,6 +76,10 @@ func Create(
 			Err:  ErrUnsupported,
 		}
 	}
+	sidecar := checksumSidecar(ctx)
+	if sidecar {
+		ctx = withoutSidecar(ctx)
+	}
 
 retry:
 	f, err := cfs.Create(ctx, name)
@@ -87,17 +96,63 @@ retry:
 		}
 		goto retry
 	}
+	if syncOnClose(ctx) {
+		if p, ok := f.(Pather); ok && p.Path() != "" {
+			name = p.Path()
+		}
+		f = syncBeforeClose(f)
+	}
+	if verifyWrite(ctx) {
+		f = verifyOnClose(ctx, fsys, name, f)
+	}
+	if sidecar {
+		f = sidecarOnClose(ctx, fsys, name, f)
+	}
 	return writePathCloser(f, name), nil
 }
 
+type syncer interface{ Sync() error }
+
+// syncCloser syncs its writer to stable storage before closing it.
+type syncCloser struct {
+	io.WriteCloser
+	s syncer
+}
+
+func (c syncCloser) Close() error {
+	if err := c.s.Sync(); err != nil {
+		_ = c.WriteCloser.Close()
+		return err
+	}
+	return c.WriteCloser.Close()
+}
+
+// syncBeforeClose wraps wc so that Close calls Sync first, if wc has a Sync
+// method. The io.ReaderFrom fast path is preserved.
+func syncBeforeClose(wc io.WriteCloser) io.WriteCloser {
+	s, ok := wc.(syncer)
+	if !ok {
+		return wc
+	}
+	sc := syncCloser{wc, s}
+	if rf, ok := wc.(io.ReaderFrom); ok {
+		return struct {
+			syncCloser
+			io.ReaderFrom
+		}{sc, rf}
+	}
+	return sc
+}
+
 func createDirAsTar(
 	ctx context.Context, fsys FS, dir string,
 ) (io.WriteCloser, error) {
 	dir = path.Dir(dir)
-	if _, ok := fsys.(MkdirFS); ok {
-		if err := MkdirAll(ctx, fsys, dir); err != nil {
-			return nil, err
-		}
+	created, err := mkdirAllOrVirtual(ctx, fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	if created {
 		if err := Truncate(ctx, fsys, path.Join(dir, ""), 0); err != nil {
 			return nil, err
 		}
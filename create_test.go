@@ -3,9 +3,14 @@ package fs_test
 import (
 	"context"
 	"fmt"
+	"io"
 	"log"
+	"slices"
+	"strings"
+	"testing"
 
 	"lesiw.io/fs"
+	"lesiw.io/fs/memfs"
 	"lesiw.io/fs/osfs"
 )
 
@@ -33,3 +38,158 @@ func ExampleCreate() {
 	// Output:
 	// Creating a new file
 }
+
+// keyFS is a CreateFS whose writers report a normalized key.
+type keyFS struct{ fs.FS }
+
+func (keyFS) Create(ctx context.Context, name string) (io.WriteCloser, error) {
+	return keyWriter(strings.TrimPrefix(name, "./")), nil
+}
+
+type keyWriter string
+
+func (w keyWriter) Write(p []byte) (int, error) { return len(p), nil }
+func (w keyWriter) Close() error                { return nil }
+func (w keyWriter) Path() string                { return string(w) }
+
+func TestCreatePathFromBackend(t *testing.T) {
+	ctx, fsys := context.Background(), keyFS{memfs.New()}
+
+	w, err := fs.Create(ctx, fsys, "./a/b")
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	closeOnCleanup(t, w)
+
+	if got, want := w.Path(), "a/b"; got != want {
+		t.Errorf("Path() = %q, want %q", got, want)
+	}
+}
+
+func TestCreateCopyFastPath(t *testing.T) {
+	fsys, ctx := osfs.NewTemp(), context.Background()
+	defer fs.Close(fsys)
+
+	data := strings.Repeat("0123456789", 10000)
+	if err := fs.WriteFile(ctx, fsys, "src.txt", []byte(data)); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	src, err := fs.Open(ctx, fsys, "src.txt")
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	closeOnCleanup(t, src)
+	if _, ok := src.(io.WriterTo); !ok {
+		t.Errorf("Open() = %T, want io.WriterTo", src)
+	}
+	dst, err := fs.Create(ctx, fsys, "dst.txt")
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if _, ok := dst.(io.ReaderFrom); !ok {
+		t.Errorf("Create() = %T, want io.ReaderFrom", dst)
+	}
+
+	// io.CopyBuffer only uses buf for the generic read/write loop, so an
+	// untouched buf means the copy went through WriteTo or ReadFrom.
+	buf := make([]byte, 512)
+	for i := range buf {
+		buf[i] = 0xff
+	}
+	if _, err := io.CopyBuffer(dst, src, buf); err != nil {
+		_ = dst.Close()
+		t.Fatalf("CopyBuffer() error = %v", err)
+	}
+	if err := dst.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	for _, b := range buf {
+		if b != 0xff {
+			t.Fatal("CopyBuffer() used the generic buffer loop")
+		}
+	}
+
+	got, err := fs.ReadFile(ctx, fsys, "dst.txt")
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if string(got) != data {
+		t.Errorf("ReadFile() = %d bytes, want %d", len(got), len(data))
+	}
+}
+
+// syncFS records the order of Sync and Close calls on created files.
+type syncFS struct {
+	fs.FS
+	calls []string
+}
+
+func (f *syncFS) Create(
+	ctx context.Context, name string,
+) (io.WriteCloser, error) {
+	return &syncWriter{fsys: f}, nil
+}
+
+type syncWriter struct{ fsys *syncFS }
+
+func (w *syncWriter) Write(p []byte) (int, error) { return len(p), nil }
+
+func (w *syncWriter) Sync() error {
+	w.fsys.calls = append(w.fsys.calls, "sync")
+	return nil
+}
+
+func (w *syncWriter) Close() error {
+	w.fsys.calls = append(w.fsys.calls, "close")
+	return nil
+}
+
+func TestWithSyncOnClose(t *testing.T) {
+	ctx := context.Background()
+	fsys := &syncFS{}
+
+	if err := fs.WriteFile(ctx, fsys, "file.txt", []byte("data")); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if got, want := fsys.calls, []string{"close"}; !slices.Equal(got, want) {
+		t.Errorf("calls without option = %v, want %v", got, want)
+	}
+
+	fsys.calls = nil
+	ctx = fs.WithSyncOnClose(ctx)
+	if err := fs.WriteFile(ctx, fsys, "file.txt", []byte("data")); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	want := []string{"sync", "close"}
+	if got := fsys.calls; !slices.Equal(got, want) {
+		t.Errorf("calls with WithSyncOnClose = %v, want %v", got, want)
+	}
+}
+
+func TestWithSyncOnCloseOS(t *testing.T) {
+	fsys, ctx := osfs.NewTemp(), context.Background()
+	t.Cleanup(func() { fs.Close(fsys) })
+	ctx = fs.WithSyncOnClose(ctx)
+
+	w, err := fs.Create(ctx, fsys, "dir/file.txt")
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if _, ok := w.(io.ReaderFrom); !ok {
+		t.Errorf("Create() writer does not implement io.ReaderFrom")
+	}
+	if _, err := io.Copy(w, strings.NewReader("durable")); err != nil {
+		t.Fatalf("Copy() error = %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	data, err := fs.ReadFile(ctx, fsys, "dir/file.txt")
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if got, want := string(data), "durable"; got != want {
+		t.Errorf("ReadFile() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,590 @@
+package fs
+
+import (
+	"context"
+	"io"
+	"iter"
+	"time"
+)
+
+// WithDeadlineWrapper returns a filesystem that bounds every operation on
+// fsys to at most maxDuration.
+//
+// Each operation runs with a context derived via [context.WithTimeout], so
+// an earlier deadline already carried by the caller's context still applies.
+// Iterators such as ReadDir and Walk are bounded from the start of
+// iteration to its end.
+//
+// For streaming operations (Open, Create, Append, and their directory
+// forms), the deadline applies only to the call that returns the stream,
+// not to reading or writing it. Once the stream is returned it stays usable
+// until closed, and it implements [io.Seeker] and [io.WriterTo] (or
+// [io.ReaderFrom]) when the underlying stream does. Copy, Trash, and
+// multipart uploads are forwarded only when fsys implements them natively,
+// so that the fallbacks in [Copy], [Trash], and [UploadParallel] stream
+// through the wrapper instead of under a single deadline.
+//
+// The wrapper implements every optional interface. Operations that fsys
+// does not implement natively run through the package-level helpers, so
+// their fallbacks still apply, and those that cannot be done at all fail
+// with [ErrUnsupported], which the helpers treat as a missing interface.
+func WithDeadlineWrapper(fsys FS, maxDuration time.Duration) FS {
+	return &deadlineFS{fsys: fsys, max: maxDuration}
+}
+
+type deadlineFS struct {
+	fsys FS
+	max  time.Duration
+}
+
+// stream calls open with a context that is canceled if open does not
+// return within the deadline. On success, the returned cancel func must be
+// called when the stream is closed.
+func stream[T io.Closer](
+	d *deadlineFS, ctx context.Context, op, name string,
+	open func(context.Context) (T, error),
+) (T, context.CancelFunc, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	timer := time.AfterFunc(d.max, cancel)
+	s, err := open(ctx)
+	if !timer.Stop() {
+		if err == nil {
+			_ = s.Close()
+		}
+		var zero T
+		return zero, nil, &PathError{
+			Op:   op,
+			Path: name,
+			Err:  context.DeadlineExceeded,
+		}
+	}
+	if err != nil {
+		cancel()
+		var zero T
+		return zero, nil, err
+	}
+	return s, cancel, nil
+}
+
+// cancelCloser closes c, then cancels its context.
+type cancelCloser struct {
+	c      io.Closer
+	cancel context.CancelFunc
+}
+
+func (cc cancelCloser) Close() error {
+	defer cc.cancel()
+	return cc.c.Close()
+}
+
+type writeCloser struct {
+	io.Writer
+	io.Closer
+}
+
+// cancelReader closes r, then calls cancel. If r implements io.Seeker or
+// io.WriterTo, so does the result, as with readPathCloser.
+func cancelReader(r io.ReadCloser, cancel context.CancelFunc) io.ReadCloser {
+	rc := readCloser{r, cancelCloser{r, cancel}}
+	s, isSeeker := r.(io.Seeker)
+	wt, isWriterTo := r.(io.WriterTo)
+	switch {
+	case isSeeker && isWriterTo:
+		return struct {
+			readCloser
+			io.Seeker
+			io.WriterTo
+		}{rc, s, wt}
+	case isSeeker:
+		return struct {
+			readCloser
+			io.Seeker
+		}{rc, s}
+	case isWriterTo:
+		return struct {
+			readCloser
+			io.WriterTo
+		}{rc, wt}
+	}
+	return rc
+}
+
+func (d *deadlineFS) openStream(
+	ctx context.Context, op, name string,
+	open func(context.Context) (io.ReadCloser, error),
+) (io.ReadCloser, error) {
+	r, cancel, err := stream(d, ctx, op, name, open)
+	if err != nil {
+		return nil, err
+	}
+	return cancelReader(r, cancel), nil
+}
+
+func (d *deadlineFS) createStream(
+	ctx context.Context, op, name string,
+	create func(context.Context) (io.WriteCloser, error),
+) (io.WriteCloser, error) {
+	w, cancel, err := stream(d, ctx, op, name, create)
+	if err != nil {
+		return nil, err
+	}
+	wc := writeCloser{w, cancelCloser{w, cancel}}
+	if rf, ok := w.(io.ReaderFrom); ok {
+		return struct {
+			writeCloser
+			io.ReaderFrom
+		}{wc, rf}, nil
+	}
+	return wc, nil
+}
+
+// seq bounds an iterator from the start of iteration to its end.
+func (d *deadlineFS) seq(
+	ctx context.Context,
+	walk func(context.Context) iter.Seq2[DirEntry, error],
+) iter.Seq2[DirEntry, error] {
+	return func(yield func(DirEntry, error) bool) {
+		ctx, cancel := context.WithTimeout(ctx, d.max)
+		defer cancel()
+		for e, err := range walk(ctx) {
+			if !yield(e, err) {
+				return
+			}
+		}
+	}
+}
+
+func (d *deadlineFS) Open(
+	ctx context.Context, name string,
+) (io.ReadCloser, error) {
+	return d.openStream(ctx, "open", name,
+		func(ctx context.Context) (io.ReadCloser, error) {
+			return Open(ctx, d.fsys, name)
+		})
+}
+
+func (d *deadlineFS) OpenDir(
+	ctx context.Context, dir string,
+) (io.ReadCloser, error) {
+	dfs, ok := d.fsys.(DirFS)
+	if !ok {
+		return nil, &PathError{Op: "opendir", Path: dir, Err: ErrUnsupported}
+	}
+	return d.openStream(ctx, "opendir", dir, func(ctx context.Context) (
+		io.ReadCloser, error,
+	) {
+		return dfs.OpenDir(ctx, dir)
+	})
+}
+
+func (d *deadlineFS) OpenRange(
+	ctx context.Context, name string, offset, length int64,
+) (io.ReadCloser, error) {
+	rfs, ok := d.fsys.(OpenRangeFS)
+	if !ok {
+		return nil, &PathError{Op: "open", Path: name, Err: ErrUnsupported}
+	}
+	return d.openStream(ctx, "open", name, func(ctx context.Context) (
+		io.ReadCloser, error,
+	) {
+		return rfs.OpenRange(ctx, name, offset, length)
+	})
+}
+
+func (d *deadlineFS) CanSeek(ctx context.Context, name string) bool {
+	ctx, cancel := context.WithTimeout(ctx, d.max)
+	defer cancel()
+	return CanSeek(ctx, d.fsys, name)
+}
+
+func (d *deadlineFS) Create(
+	ctx context.Context, name string,
+) (io.WriteCloser, error) {
+	return d.createStream(ctx, "create", name,
+		func(ctx context.Context) (io.WriteCloser, error) {
+			return Create(ctx, d.fsys, name)
+		})
+}
+
+func (d *deadlineFS) Append(
+	ctx context.Context, name string,
+) (io.WriteCloser, error) {
+	return d.createStream(ctx, "append", name,
+		func(ctx context.Context) (io.WriteCloser, error) {
+			return Append(ctx, d.fsys, name)
+		})
+}
+
+func (d *deadlineFS) AppendDir(
+	ctx context.Context, dir string,
+) (io.WriteCloser, error) {
+	afs, ok := d.fsys.(AppendDirFS)
+	if !ok {
+		return nil, &PathError{
+			Op:   "appenddir",
+			Path: dir,
+			Err:  ErrUnsupported,
+		}
+	}
+	return d.createStream(ctx, "appenddir", dir,
+		func(ctx context.Context) (io.WriteCloser, error) {
+			return afs.AppendDir(ctx, dir)
+		})
+}
+
+func (d *deadlineFS) Stat(ctx context.Context, name string) (FileInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.max)
+	defer cancel()
+	return Stat(ctx, d.fsys, name)
+}
+
+func (d *deadlineFS) StatMany(
+	ctx context.Context, names []string,
+) ([]FileInfo, error) {
+	// Batching only pays off when the backend batches natively.
+	sfs, ok := d.fsys.(StatManyFS)
+	if !ok {
+		return nil, &PathError{Op: "stat", Err: ErrUnsupported}
+	}
+	ctx, cancel := context.WithTimeout(ctx, d.max)
+	defer cancel()
+	return sfs.StatMany(ctx, names)
+}
+
+func (d *deadlineFS) Exists(ctx context.Context, name string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.max)
+	defer cancel()
+	return Exists(ctx, d.fsys, name)
+}
+
+func (d *deadlineFS) SameFile(
+	ctx context.Context, a, b string,
+) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.max)
+	defer cancel()
+	return SameFile(ctx, d.fsys, a, b)
+}
+
+func (d *deadlineFS) Lstat(
+	ctx context.Context, name string,
+) (FileInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.max)
+	defer cancel()
+	return Lstat(ctx, d.fsys, name)
+}
+
+func (d *deadlineFS) ReadLink(
+	ctx context.Context, name string,
+) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.max)
+	defer cancel()
+	return ReadLink(ctx, d.fsys, name)
+}
+
+func (d *deadlineFS) Symlink(
+	ctx context.Context, oldname, newname string,
+) error {
+	ctx, cancel := context.WithTimeout(ctx, d.max)
+	defer cancel()
+	return Symlink(ctx, d.fsys, oldname, newname)
+}
+
+func (d *deadlineFS) Link(
+	ctx context.Context, oldname, newname string,
+) error {
+	ctx, cancel := context.WithTimeout(ctx, d.max)
+	defer cancel()
+	return Link(ctx, d.fsys, oldname, newname)
+}
+
+func (d *deadlineFS) ReadDir(
+	ctx context.Context, name string,
+) iter.Seq2[DirEntry, error] {
+	return d.seq(ctx, func(ctx context.Context) iter.Seq2[DirEntry, error] {
+		return ReadDir(ctx, d.fsys, name)
+	})
+}
+
+func (d *deadlineFS) Walk(
+	ctx context.Context, root string, depth int,
+) iter.Seq2[DirEntry, error] {
+	return d.seq(ctx, func(ctx context.Context) iter.Seq2[DirEntry, error] {
+		return Walk(ctx, d.fsys, root, depth)
+	})
+}
+
+func (d *deadlineFS) Glob(
+	ctx context.Context, pattern string,
+) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.max)
+	defer cancel()
+	return Glob(ctx, d.fsys, pattern)
+}
+
+func (d *deadlineFS) Count(ctx context.Context, dir string) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.max)
+	defer cancel()
+	return Count(ctx, d.fsys, dir)
+}
+
+func (d *deadlineFS) Mkdir(ctx context.Context, name string) error {
+	ctx, cancel := context.WithTimeout(ctx, d.max)
+	defer cancel()
+	return Mkdir(ctx, d.fsys, name)
+}
+
+func (d *deadlineFS) MkdirAll(ctx context.Context, name string) error {
+	ctx, cancel := context.WithTimeout(ctx, d.max)
+	defer cancel()
+	return MkdirAll(ctx, d.fsys, name)
+}
+
+func (d *deadlineFS) Remove(ctx context.Context, name string) error {
+	ctx, cancel := context.WithTimeout(ctx, d.max)
+	defer cancel()
+	return Remove(ctx, d.fsys, name)
+}
+
+func (d *deadlineFS) RemoveAll(ctx context.Context, name string) error {
+	ctx, cancel := context.WithTimeout(ctx, d.max)
+	defer cancel()
+	return RemoveAll(ctx, d.fsys, name)
+}
+
+func (d *deadlineFS) Rename(
+	ctx context.Context, oldname, newname string,
+) error {
+	ctx, cancel := context.WithTimeout(ctx, d.max)
+	defer cancel()
+	return Rename(ctx, d.fsys, oldname, newname)
+}
+
+// Copy forwards only a native Copy. Otherwise it reports ErrUnsupported,
+// so that [Copy] streams the file through Open and Create and the deadline
+// bounds opening the streams rather than the whole transfer.
+func (d *deadlineFS) Copy(ctx context.Context, src, dst string) error {
+	cfs, ok := d.fsys.(CopyFS)
+	if !ok {
+		return &PathError{Op: "copy", Path: src, Err: ErrUnsupported}
+	}
+	ctx, cancel := context.WithTimeout(ctx, d.max)
+	defer cancel()
+	return cfs.Copy(ctx, src, dst)
+}
+
+// Trash forwards only a native Trash, so that the fallback in [Trash]
+// renames through the wrapper.
+func (d *deadlineFS) Trash(ctx context.Context, name string) error {
+	tfs, ok := d.fsys.(TrashFS)
+	if !ok {
+		return &PathError{Op: "trash", Path: name, Err: ErrUnsupported}
+	}
+	ctx, cancel := context.WithTimeout(ctx, d.max)
+	defer cancel()
+	return tfs.Trash(ctx, name)
+}
+
+func (d *deadlineFS) Chmod(
+	ctx context.Context, name string, mode Mode,
+) error {
+	ctx, cancel := context.WithTimeout(ctx, d.max)
+	defer cancel()
+	return Chmod(ctx, d.fsys, name, mode)
+}
+
+func (d *deadlineFS) Mknod(
+	ctx context.Context, name string, mode Mode, dev uint64,
+) error {
+	ctx, cancel := context.WithTimeout(ctx, d.max)
+	defer cancel()
+	return Mknod(ctx, d.fsys, name, mode, dev)
+}
+
+func (d *deadlineFS) Chown(
+	ctx context.Context, name string, uid, gid int,
+) error {
+	ctx, cancel := context.WithTimeout(ctx, d.max)
+	defer cancel()
+	return Chown(ctx, d.fsys, name, uid, gid)
+}
+
+func (d *deadlineFS) Chtimes(
+	ctx context.Context, name string, atime, mtime time.Time,
+) error {
+	ctx, cancel := context.WithTimeout(ctx, d.max)
+	defer cancel()
+	return Chtimes(ctx, d.fsys, name, atime, mtime)
+}
+
+func (d *deadlineFS) Truncate(
+	ctx context.Context, name string, size int64,
+) error {
+	ctx, cancel := context.WithTimeout(ctx, d.max)
+	defer cancel()
+	return Truncate(ctx, d.fsys, name, size)
+}
+
+func (d *deadlineFS) TruncateDir(ctx context.Context, dir string) error {
+	tfs, ok := d.fsys.(TruncateDirFS)
+	if !ok {
+		return &PathError{Op: "truncate", Path: dir, Err: ErrUnsupported}
+	}
+	ctx, cancel := context.WithTimeout(ctx, d.max)
+	defer cancel()
+	return tfs.TruncateDir(ctx, dir)
+}
+
+func (d *deadlineFS) Temp(ctx context.Context, name string) (string, error) {
+	tfs, ok := d.fsys.(TempFS)
+	if !ok {
+		return "", &PathError{Op: "temp", Path: name, Err: ErrUnsupported}
+	}
+	ctx, cancel := context.WithTimeout(ctx, d.max)
+	defer cancel()
+	return tfs.Temp(ctx, name)
+}
+
+func (d *deadlineFS) TempDir(
+	ctx context.Context, name string,
+) (string, error) {
+	tfs, ok := d.fsys.(TempDirFS)
+	if !ok {
+		return "", &PathError{Op: "tempdir", Path: name, Err: ErrUnsupported}
+	}
+	ctx, cancel := context.WithTimeout(ctx, d.max)
+	defer cancel()
+	return tfs.TempDir(ctx, name)
+}
+
+func (d *deadlineFS) Localize(
+	ctx context.Context, path string,
+) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.max)
+	defer cancel()
+	return Localize(ctx, d.fsys, path)
+}
+
+func (d *deadlineFS) Abs(ctx context.Context, name string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.max)
+	defer cancel()
+	return Abs(ctx, d.fsys, name)
+}
+
+func (d *deadlineFS) SysConn(
+	ctx context.Context, name string,
+) (RawConn, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.max)
+	defer cancel()
+	return SysConn(ctx, d.fsys, name)
+}
+
+func (d *deadlineFS) IsMountPoint(
+	ctx context.Context, name string,
+) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.max)
+	defer cancel()
+	return IsMountPoint(ctx, d.fsys, name)
+}
+
+// multipart returns the MultipartFS of the underlying filesystem, or an
+// error with ErrUnsupported.
+func (d *deadlineFS) multipart(name string) (MultipartFS, error) {
+	mfs, ok := d.fsys.(MultipartFS)
+	if !ok {
+		return nil, &PathError{Op: "upload", Path: name, Err: ErrUnsupported}
+	}
+	return mfs, nil
+}
+
+func (d *deadlineFS) CreateMultipart(
+	ctx context.Context, name string,
+) (string, error) {
+	mfs, err := d.multipart(name)
+	if err != nil {
+		return "", err
+	}
+	ctx, cancel := context.WithTimeout(ctx, d.max)
+	defer cancel()
+	return mfs.CreateMultipart(ctx, name)
+}
+
+// UploadPart bounds the upload of each part separately.
+func (d *deadlineFS) UploadPart(
+	ctx context.Context, name, uploadID string, number int,
+	r io.Reader, size int64,
+) (Part, error) {
+	mfs, err := d.multipart(name)
+	if err != nil {
+		return Part{}, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, d.max)
+	defer cancel()
+	return mfs.UploadPart(ctx, name, uploadID, number, r, size)
+}
+
+func (d *deadlineFS) CompleteMultipart(
+	ctx context.Context, name, uploadID string, parts []Part,
+) error {
+	mfs, err := d.multipart(name)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, d.max)
+	defer cancel()
+	return mfs.CompleteMultipart(ctx, name, uploadID, parts)
+}
+
+func (d *deadlineFS) AbortMultipart(
+	ctx context.Context, name, uploadID string,
+) error {
+	mfs, err := d.multipart(name)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, d.max)
+	defer cancel()
+	return mfs.AbortMultipart(ctx, name, uploadID)
+}
+
+func (d *deadlineFS) Close() error { return Close(d.fsys) }
+
+var (
+	_ AbsFS         = (*deadlineFS)(nil)
+	_ AppendFS      = (*deadlineFS)(nil)
+	_ AppendDirFS   = (*deadlineFS)(nil)
+	_ ChmodFS       = (*deadlineFS)(nil)
+	_ ChownFS       = (*deadlineFS)(nil)
+	_ ChtimesFS     = (*deadlineFS)(nil)
+	_ CopyFS        = (*deadlineFS)(nil)
+	_ CountFS       = (*deadlineFS)(nil)
+	_ CreateFS      = (*deadlineFS)(nil)
+	_ DirFS         = (*deadlineFS)(nil)
+	_ ExistsFS      = (*deadlineFS)(nil)
+	_ GlobFS        = (*deadlineFS)(nil)
+	_ LinkFS        = (*deadlineFS)(nil)
+	_ LocalizeFS    = (*deadlineFS)(nil)
+	_ MkdirFS       = (*deadlineFS)(nil)
+	_ MkdirAllFS    = (*deadlineFS)(nil)
+	_ MknodFS       = (*deadlineFS)(nil)
+	_ MountFS       = (*deadlineFS)(nil)
+	_ MultipartFS   = (*deadlineFS)(nil)
+	_ OpenRangeFS   = (*deadlineFS)(nil)
+	_ ReadDirFS     = (*deadlineFS)(nil)
+	_ ReadLinkFS    = (*deadlineFS)(nil)
+	_ RemoveFS      = (*deadlineFS)(nil)
+	_ RemoveAllFS   = (*deadlineFS)(nil)
+	_ RenameFS      = (*deadlineFS)(nil)
+	_ SameFileFS    = (*deadlineFS)(nil)
+	_ SeekFS        = (*deadlineFS)(nil)
+	_ StatFS        = (*deadlineFS)(nil)
+	_ StatManyFS    = (*deadlineFS)(nil)
+	_ SymlinkFS     = (*deadlineFS)(nil)
+	_ SysFS         = (*deadlineFS)(nil)
+	_ TempFS        = (*deadlineFS)(nil)
+	_ TempDirFS     = (*deadlineFS)(nil)
+	_ TrashFS       = (*deadlineFS)(nil)
+	_ TruncateFS    = (*deadlineFS)(nil)
+	_ TruncateDirFS = (*deadlineFS)(nil)
+	_ WalkFS        = (*deadlineFS)(nil)
+	_ io.Closer     = (*deadlineFS)(nil)
+)
@@ -0,0 +1,135 @@
+package fs_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"lesiw.io/fs"
+	"lesiw.io/fs/fstest"
+	"lesiw.io/fs/memfs"
+)
+
+// slowFS delays every Stat until its context is done or delay elapses.
+type slowFS struct {
+	fs.FS
+	delay time.Duration
+}
+
+func (s slowFS) Stat(ctx context.Context, name string) (fs.FileInfo, error) {
+	select {
+	case <-time.After(s.delay):
+		return fs.Stat(ctx, s.FS, name)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestWithDeadlineWrapper(t *testing.T) {
+	ctx := context.Background()
+	fsys := fs.WithDeadlineWrapper(
+		slowFS{memfs.New(), time.Minute}, 10*time.Millisecond,
+	)
+
+	start := time.Now()
+	_, err := fs.Stat(ctx, fsys, "file.txt")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Stat() error = %v, want DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("Stat() took %v, want abort at deadline", elapsed)
+	}
+}
+
+func TestWithDeadlineWrapperStream(t *testing.T) {
+	ctx := context.Background()
+	fsys := fs.WithDeadlineWrapper(memfs.New(), time.Second)
+
+	if err := fs.WriteFile(ctx, fsys, "file.txt", []byte("data")); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	r, err := fs.Open(ctx, fsys, "file.txt")
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	closeOnCleanup(t, r)
+
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	if want := "data"; string(got) != want {
+		t.Errorf("ReadAll() = %q, want %q", got, want)
+	}
+}
+
+func TestWithDeadlineWrapperFS(t *testing.T) {
+	fsys := fs.WithDeadlineWrapper(memfs.New(), time.Minute)
+	fstest.TestFS(t.Context(), t, fsys)
+}
+
+func TestWithDeadlineWrapperForwards(t *testing.T) {
+	ctx := context.Background()
+
+	mem := fs.WithDeadlineWrapper(memfs.New(), time.Minute)
+	if err := fs.WriteFile(ctx, mem, "file.txt", []byte("data")); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	r, err := fs.Open(ctx, mem, "file.txt")
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	closeOnCleanup(t, r)
+	s, ok := r.(io.Seeker)
+	if !ok {
+		t.Fatalf("Open() = %T, want io.Seeker", r)
+	}
+	if pos, err := s.Seek(2, io.SeekStart); err != nil || pos != 2 {
+		t.Errorf("Seek(2) = %d, %v, want 2, nil", pos, err)
+	}
+	if got, err := io.ReadAll(r); err != nil || string(got) != "ta" {
+		t.Errorf("ReadAll() after Seek = %q, %v, want %q", got, err, "ta")
+	}
+
+	server := &serverCopyFS{FS: memfs.New()}
+	fsys := fs.WithDeadlineWrapper(server, time.Minute)
+	if err := fs.WriteFile(ctx, fsys, "src.txt", []byte("data")); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if err := fs.Copy(ctx, fsys, "src.txt", "dst.txt"); err != nil {
+		t.Fatalf("Copy() error = %v", err)
+	}
+	if server.copies != 1 || server.opens != 0 {
+		t.Errorf("Copy() copies, opens = %d, %d, want native copy",
+			server.copies, server.opens)
+	}
+
+	upload := &multipartFS{FS: memfs.New()}
+	fsys = fs.WithDeadlineWrapper(upload, time.Minute)
+	data := []byte("0123456789")
+	err = fs.UploadParallel(ctx, fsys, "big.bin",
+		bytes.NewReader(data), int64(len(data)), 2)
+	if err != nil {
+		t.Fatalf("UploadParallel() error = %v", err)
+	}
+	if got := len(upload.parts); got != 2 {
+		t.Errorf("uploaded parts = %d, want 2", got)
+	}
+}
+
+func TestWithDeadlineWrapperNoMkdir(t *testing.T) {
+	ctx := context.Background()
+	fsys := fs.WithDeadlineWrapper(noMkdirFS{memfs.New()}, time.Minute)
+
+	err := appendTar(ctx, fsys, "out/", map[string]string{"a/b.txt": "b"})
+	if err != nil {
+		t.Fatalf("Append(out/) error = %v", err)
+	}
+	got, err := fs.ReadFile(ctx, fsys, "out/a/b.txt")
+	if err != nil || string(got) != "b" {
+		t.Errorf("ReadFile(out/a/b.txt) = %q, %v, want %q", got, err, "b")
+	}
+}
@@ -0,0 +1,108 @@
+package fs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+)
+
+// DownloadParallel copies the named file into w, fetching up to parts byte
+// ranges concurrently. It returns the number of bytes written.
+// Analogous to: aria2c -x, s5cmd cp, S3 Transfer Manager downloads.
+//
+// When fsys implements [OpenRangeFS] and [StatFS] reports the file's size,
+// the file is split into parts ranges of roughly equal size. Each range is
+// written at its own offset, so w must accept concurrent WriteAt calls, as
+// [os.File] does. If any range fails, the remaining ranges are canceled
+// and the first error is returned.
+//
+// Otherwise, or if parts is less than 2, the file is copied sequentially.
+//
+// Requires: [FS]
+func DownloadParallel(
+	ctx context.Context, fsys FS, name string, w io.WriterAt, parts int,
+) (int64, error) {
+	var err error
+	if name, err = localizePath(ctx, fsys, name); err != nil {
+		return 0, err
+	}
+	rfs, ok := fsys.(OpenRangeFS)
+	if !ok || parts < 2 {
+		return downloadSequential(ctx, fsys, name, w)
+	}
+	info, err := Stat(ctx, fsys, name)
+	if err != nil {
+		if errors.Is(err, ErrUnsupported) {
+			return downloadSequential(ctx, fsys, name, w)
+		}
+		return 0, err
+	}
+	size := info.Size()
+	if size < int64(parts) {
+		return downloadSequential(ctx, fsys, name, w)
+	}
+
+	rctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	fail := func(err error) {
+		once.Do(func() {
+			firstErr = err
+			cancel()
+		})
+	}
+	chunk := size / int64(parts)
+	for i := range parts {
+		off := int64(i) * chunk
+		n := chunk
+		if i == parts-1 {
+			n = size - off
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r, err := rfs.OpenRange(rctx, name, off, n)
+			if err != nil {
+				fail(newPathError("open", name, err))
+				return
+			}
+			defer r.Close()
+			got, err := io.Copy(io.NewOffsetWriter(w, off), r)
+			if err == nil && got != n {
+				err = io.ErrUnexpectedEOF
+			}
+			if err != nil {
+				fail(&PathError{Op: "read", Path: name, Err: err})
+			}
+		}()
+	}
+	wg.Wait()
+	if firstErr != nil {
+		if errors.Is(firstErr, ErrUnsupported) {
+			return downloadSequential(ctx, fsys, name, w)
+		}
+		return 0, firstErr
+	}
+	return size, nil
+}
+
+func downloadSequential(
+	ctx context.Context, fsys FS, name string, w io.WriterAt,
+) (int64, error) {
+	r, err := Open(ctx, fsys, name)
+	if err != nil {
+		return 0, err
+	}
+	defer r.Close()
+	n, err := io.Copy(io.NewOffsetWriter(w, 0), r)
+	if err != nil {
+		return n, &PathError{Op: "read", Path: name, Err: err}
+	}
+	return n, nil
+}
@@ -0,0 +1,134 @@
+package fs_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"lesiw.io/fs"
+	"lesiw.io/fs/memfs"
+)
+
+// rangeFS serves byte ranges and counts the requests it receives.
+type rangeFS struct {
+	fs.FS
+	ranges atomic.Int32
+}
+
+func (f *rangeFS) OpenRange(
+	ctx context.Context, name string, offset, length int64,
+) (io.ReadCloser, error) {
+	f.ranges.Add(1)
+	data, err := fs.ReadFile(ctx, f.FS, name)
+	if err != nil {
+		return nil, err
+	}
+	end := int64(len(data))
+	if length >= 0 && offset+length < end {
+		end = offset + length
+	}
+	return io.NopCloser(bytes.NewReader(data[offset:end])), nil
+}
+
+func (f *rangeFS) Stat(ctx context.Context, name string) (fs.FileInfo, error) {
+	return fs.Stat(ctx, f.FS, name)
+}
+
+func TestDownloadParallel(t *testing.T) {
+	ctx, mem := context.Background(), memfs.New()
+	data := bytes.Repeat([]byte("0123456789abcdef"), 1000)
+	data = append(data, "tail"...)
+	if err := fs.WriteFile(ctx, mem, "big.bin", data); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	fsys := &rangeFS{FS: mem}
+
+	out, err := os.Create(filepath.Join(t.TempDir(), "out.bin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out.Close()
+
+	n, err := fs.DownloadParallel(ctx, fsys, "big.bin", out, 4)
+	if err != nil {
+		t.Fatalf("DownloadParallel() error = %v", err)
+	}
+	if got, want := n, int64(len(data)); got != want {
+		t.Errorf("DownloadParallel() = %d, want %d", got, want)
+	}
+	if got, want := fsys.ranges.Load(), int32(4); got != want {
+		t.Errorf("range requests = %d, want %d", got, want)
+	}
+	got, err := os.ReadFile(out.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Error("downloaded content does not match")
+	}
+}
+
+func TestDownloadParallelSequential(t *testing.T) {
+	ctx, fsys := context.Background(), memfs.New()
+	data := []byte("no ranges here")
+	if err := fs.WriteFile(ctx, fsys, "file.txt", data); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	out, err := os.Create(filepath.Join(t.TempDir(), "out.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out.Close()
+
+	n, err := fs.DownloadParallel(ctx, fsys, "file.txt", out, 4)
+	if err != nil {
+		t.Fatalf("DownloadParallel() error = %v", err)
+	}
+	if got, want := n, int64(len(data)); got != want {
+		t.Errorf("DownloadParallel() = %d, want %d", got, want)
+	}
+	got, err := os.ReadFile(out.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Errorf("downloaded = %q, want %q", got, data)
+	}
+}
+
+func TestOpenRange(t *testing.T) {
+	ctx, fsys := context.Background(), memfs.New()
+	if err := fs.WriteFile(ctx, fsys, "f", []byte("0123456789")); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	for _, tt := range []struct {
+		offset, length int64
+		want           string
+	}{
+		{0, 3, "012"},
+		{4, 2, "45"},
+		{7, -1, "789"},
+		{8, 10, "89"},
+	} {
+		r, err := fs.OpenRange(ctx, fsys, "f", tt.offset, tt.length)
+		if err != nil {
+			t.Fatalf("OpenRange(%d, %d) error = %v",
+				tt.offset, tt.length, err)
+		}
+		got, err := io.ReadAll(r)
+		_ = r.Close()
+		if err != nil {
+			t.Fatalf("ReadAll() error = %v", err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("OpenRange(%d, %d) = %q, want %q",
+				tt.offset, tt.length, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+// Package embedfs implements lesiw.io/fs.FS over an embed.FS with local
+// overrides on disk.
+//
+// It suits servers that ship default assets in their binary but allow them
+// to be replaced without a rebuild: reads prefer a file on disk and fall
+// back to the embedded asset, and writes always go to disk.
+package embedfs
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"io"
+	stdfs "io/fs"
+	"iter"
+	stdpath "path"
+	"slices"
+	"strings"
+
+	"lesiw.io/fs"
+	"lesiw.io/fs/osfs"
+	"lesiw.io/fs/path"
+)
+
+// New returns a filesystem that layers the directory diskDir over assets.
+//
+// A path that exists in diskDir hides the embedded asset of the same name.
+// Paths are the same in both layers, so an asset embedded as
+// static/index.html is overridden by diskDir/static/index.html. Directories
+// are merged, so ReadDir lists the entries of both layers.
+//
+// Create, Mkdir, and Remove act on diskDir only. Embedded assets cannot be
+// removed, so Remove reports [fs.ErrPermission] for a path that exists only
+// in assets, and removing an override reveals the asset beneath it.
+func New(assets embed.FS, diskDir string) fs.FS {
+	return &embedFS{assets: assets, disk: osfs.New(), dir: diskDir}
+}
+
+type embedFS struct {
+	assets embed.FS
+	disk   fs.FS
+	dir    string
+}
+
+// key converts name, relative to any working directory in ctx, into a path
+// valid in both layers. The root is ".".
+func key(ctx context.Context, name string) string {
+	if w := fs.WorkDir(ctx); w != "" && !path.IsAbs(name) {
+		name = path.Join(w, name)
+	}
+	name = stdpath.Clean("/" + name)[1:]
+	if name == "" {
+		return "."
+	}
+	return name
+}
+
+// diskCtx returns a context that roots the disk layer at the override
+// directory. Names passed to the disk layer are already keyed, so any
+// working directory from the caller has been applied.
+func (f *embedFS) diskCtx(ctx context.Context) context.Context {
+	return fs.WithWorkDir(ctx, f.dir)
+}
+
+var _ fs.FS = (*embedFS)(nil)
+
+func (f *embedFS) Open(
+	ctx context.Context, name string,
+) (io.ReadCloser, error) {
+	k := key(ctx, name)
+	r, err := f.disk.Open(f.diskCtx(ctx), k)
+	if !errors.Is(err, fs.ErrNotExist) {
+		return r, err
+	}
+	info, err := stdfs.Stat(f.assets, k)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	if info.IsDir() {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrIsDir}
+	}
+	return f.assets.Open(k)
+}
+
+var _ fs.StatFS = (*embedFS)(nil)
+
+func (f *embedFS) Stat(ctx context.Context, name string) (fs.FileInfo, error) {
+	k := key(ctx, name)
+	info, err := fs.Stat(f.diskCtx(ctx), f.disk, k)
+	if !errors.Is(err, fs.ErrNotExist) {
+		return info, err
+	}
+	info, err = stdfs.Stat(f.assets, k)
+	if err != nil {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+	}
+	return info, nil
+}
+
+var _ fs.ReadDirFS = (*embedFS)(nil)
+
+// ReadDir merges the entries of both layers, in lexicographic order. An
+// entry on disk hides the embedded entry of the same name.
+func (f *embedFS) ReadDir(
+	ctx context.Context, name string,
+) iter.Seq2[fs.DirEntry, error] {
+	return func(yield func(fs.DirEntry, error) bool) {
+		k := key(ctx, name)
+		var (
+			entries []fs.DirEntry
+			seen    = make(map[string]bool)
+			found   bool
+		)
+		for e, err := range fs.ReadDir(f.diskCtx(ctx), f.disk, k) {
+			if errors.Is(err, fs.ErrNotExist) {
+				break
+			}
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			found = true
+			seen[e.Name()] = true
+			entries = append(entries, e)
+		}
+		if !found {
+			if info, err := fs.Stat(f.diskCtx(ctx), f.disk, k); err == nil {
+				if !info.IsDir() {
+					yield(nil, &fs.PathError{
+						Op:   "readdir",
+						Path: name,
+						Err:  fs.ErrNotDir,
+					})
+					return
+				}
+				found = true // An empty directory on disk.
+			}
+		}
+
+		assets, err := stdfs.ReadDir(f.assets, k)
+		if err == nil {
+			found = true
+		}
+		for _, e := range assets {
+			if !seen[e.Name()] {
+				entries = append(entries, fs.FromIOFSDirEntry(e))
+			}
+		}
+
+		if !found {
+			yield(nil, &fs.PathError{
+				Op:   "readdir",
+				Path: name,
+				Err:  fs.ErrNotExist,
+			})
+			return
+		}
+		slices.SortFunc(entries, func(a, b fs.DirEntry) int {
+			return strings.Compare(a.Name(), b.Name())
+		})
+		for _, e := range entries {
+			if !yield(e, nil) {
+				return
+			}
+		}
+	}
+}
+
+var _ fs.CreateFS = (*embedFS)(nil)
+
+func (f *embedFS) Create(
+	ctx context.Context, name string,
+) (io.WriteCloser, error) {
+	return fs.Create(f.diskCtx(ctx), f.disk, key(ctx, name))
+}
+
+var _ fs.MkdirFS = (*embedFS)(nil)
+
+func (f *embedFS) Mkdir(ctx context.Context, name string) error {
+	return fs.Mkdir(f.diskCtx(ctx), f.disk, key(ctx, name))
+}
+
+var _ fs.RemoveFS = (*embedFS)(nil)
+
+func (f *embedFS) Remove(ctx context.Context, name string) error {
+	k := key(ctx, name)
+	err := fs.Remove(f.diskCtx(ctx), f.disk, k)
+	if errors.Is(err, fs.ErrNotExist) {
+		if _, serr := stdfs.Stat(f.assets, k); serr == nil {
+			return &fs.PathError{
+				Op:   "remove",
+				Path: name,
+				Err:  fs.ErrPermission,
+			}
+		}
+	}
+	return err
+}
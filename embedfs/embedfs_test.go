@@ -0,0 +1,102 @@
+package embedfs
+
+import (
+	"embed"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"lesiw.io/fs"
+)
+
+//go:embed testdata
+var testAssets embed.FS
+
+func TestEmbeddedOnly(t *testing.T) {
+	fsys, ctx := New(testAssets, t.TempDir()), t.Context()
+
+	data, err := fs.ReadFile(ctx, fsys, "testdata/static/index.html")
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if got, want := string(data), "embedded index"; got != want {
+		t.Errorf("ReadFile() = %q, want %q", got, want)
+	}
+	_, err = fsys.Open(ctx, "testdata/static")
+	if !errors.Is(err, fs.ErrIsDir) {
+		t.Errorf("Open(testdata/static) error = %v, want ErrIsDir", err)
+	}
+}
+
+func TestOverride(t *testing.T) {
+	dir := t.TempDir()
+	fsys, ctx := New(testAssets, dir), t.Context()
+
+	err := fs.WriteFile(ctx, fsys, "testdata/static/index.html",
+		[]byte("override"))
+	if err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	data, err := fs.ReadFile(ctx, fsys, "testdata/static/index.html")
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if got, want := string(data), "override"; got != want {
+		t.Errorf("ReadFile() = %q, want %q", got, want)
+	}
+
+	disk := filepath.Join(dir, "testdata", "static", "index.html")
+	if _, err := os.Stat(disk); err != nil {
+		t.Errorf("override not on disk: %v", err)
+	}
+
+	if err := fs.Remove(ctx, fsys, "testdata/static/index.html"); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	data, err = fs.ReadFile(ctx, fsys, "testdata/static/index.html")
+	if err != nil {
+		t.Fatalf("ReadFile() after Remove error = %v", err)
+	}
+	if got, want := string(data), "embedded index"; got != want {
+		t.Errorf("ReadFile() after Remove = %q, want %q", got, want)
+	}
+
+	err = fs.Remove(ctx, fsys, "testdata/static/index.html")
+	if !errors.Is(err, fs.ErrPermission) {
+		t.Errorf("Remove(embedded) error = %v, want ErrPermission", err)
+	}
+}
+
+func TestWriteNewFile(t *testing.T) {
+	dir := t.TempDir()
+	fsys, ctx := New(testAssets, dir), t.Context()
+
+	err := fs.WriteFile(ctx, fsys, "testdata/static/app.js", []byte("js"))
+	if err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	disk := filepath.Join(dir, "testdata", "static", "app.js")
+	data, err := os.ReadFile(disk)
+	if err != nil {
+		t.Fatalf("os.ReadFile() error = %v", err)
+	}
+	if got, want := string(data), "js"; got != want {
+		t.Errorf("os.ReadFile() = %q, want %q", got, want)
+	}
+
+	var names []string
+	for e, err := range fs.ReadDir(ctx, fsys, "testdata/static") {
+		if err != nil {
+			t.Fatalf("ReadDir() error = %v", err)
+		}
+		names = append(names, e.Name())
+	}
+	want := []string{"app.js", "index.html", "style.css"}
+	if !slices.Equal(names, want) {
+		t.Errorf("ReadDir() = %q, want %q", names, want)
+	}
+}
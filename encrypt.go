@@ -0,0 +1,390 @@
+package fs
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+	"iter"
+	"time"
+)
+
+// A Cipher encrypts and decrypts file contents for [Encrypt].
+//
+// If a Cipher also has a PlaintextSize(size int64) int64 method, it is used
+// to convert the size of a file in the underlying filesystem into the size
+// of its contents. [NewGCMCipher] has one.
+type Cipher interface {
+	// Encrypt returns a writer that encrypts what is written to it and
+	// writes the ciphertext to w. Close flushes the ciphertext, but does
+	// not close w.
+	Encrypt(w io.Writer) io.WriteCloser
+
+	// Decrypt returns a reader of the plaintext of the ciphertext read from
+	// r. If the ciphertext fails authentication, Read returns an error
+	// wrapping [ErrDecryptFailed].
+	Decrypt(r io.Reader) io.Reader
+}
+
+// Encrypt returns a filesystem that stores the contents of files in fsys
+// encrypted with c, for encryption at rest that is transparent to callers.
+// Files written with [Create] are encrypted as they are written, and files
+// read with [Open] are decrypted as they are read.
+//
+// Stat and ReadDir report the size of a file's contents if c has a
+// PlaintextSize method, as described in [Cipher], and the size of its
+// ciphertext otherwise. Names, modes, and times are not encrypted.
+//
+// The wrapper forwards Open, Create, Stat, ReadDir, Mkdir, Remove, Rename,
+// Chmod, Chtimes, Localize, and Close to fsys. Other operations fall back
+// to the implementations built on these, so that contents always pass
+// through c: Append, for example, decrypts and rewrites the whole file.
+// Forwarded operations that fsys does not support fail with
+// [ErrUnsupported], which the fallbacks treat as a missing interface, so
+// directories stay virtual on a backend without [MkdirFS].
+func Encrypt(fsys FS, c Cipher) FS {
+	return &encryptFS{fsys: fsys, cipher: c}
+}
+
+type encryptFS struct {
+	fsys   FS
+	cipher Cipher
+}
+
+func (e *encryptFS) Open(
+	ctx context.Context, name string,
+) (io.ReadCloser, error) {
+	f, err := Open(ctx, e.fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	return readCloser{e.cipher.Decrypt(f), f}, nil
+}
+
+func (e *encryptFS) Create(
+	ctx context.Context, name string,
+) (io.WriteCloser, error) {
+	f, err := Create(ctx, e.fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	return &encryptWriter{e.cipher.Encrypt(f), f}, nil
+}
+
+// encryptWriter closes the underlying file once the ciphertext is flushed.
+type encryptWriter struct {
+	io.WriteCloser
+	f io.Closer
+}
+
+func (w *encryptWriter) Close() error {
+	err := w.WriteCloser.Close()
+	if cerr := w.f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func (e *encryptFS) Stat(
+	ctx context.Context, name string,
+) (FileInfo, error) {
+	info, err := Stat(ctx, e.fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	return e.plainInfo(info), nil
+}
+
+func (e *encryptFS) ReadDir(
+	ctx context.Context, name string,
+) iter.Seq2[DirEntry, error] {
+	return func(yield func(DirEntry, error) bool) {
+		for entry, err := range ReadDir(ctx, e.fsys, name) {
+			if entry != nil {
+				entry = plainEntry{entry, e}
+			}
+			if !yield(entry, err) {
+				return
+			}
+		}
+	}
+}
+
+// plainInfo reports the size of the contents of the file described by info.
+func (e *encryptFS) plainInfo(info FileInfo) FileInfo {
+	ps, ok := e.cipher.(interface{ PlaintextSize(int64) int64 })
+	if !ok || !info.Mode().IsRegular() {
+		return info
+	}
+	return plainSizeInfo{info, ps.PlaintextSize(info.Size())}
+}
+
+type plainSizeInfo struct {
+	FileInfo
+	size int64
+}
+
+func (fi plainSizeInfo) Size() int64 { return fi.size }
+
+type plainEntry struct {
+	DirEntry
+	e *encryptFS
+}
+
+func (d plainEntry) Info() (FileInfo, error) {
+	info, err := d.DirEntry.Info()
+	if err != nil {
+		return nil, err
+	}
+	return d.e.plainInfo(info), nil
+}
+
+func (e *encryptFS) Mkdir(ctx context.Context, name string) error {
+	return Mkdir(ctx, e.fsys, name)
+}
+
+func (e *encryptFS) Remove(ctx context.Context, name string) error {
+	return Remove(ctx, e.fsys, name)
+}
+
+func (e *encryptFS) Rename(
+	ctx context.Context, oldname, newname string,
+) error {
+	return Rename(ctx, e.fsys, oldname, newname)
+}
+
+func (e *encryptFS) Chmod(ctx context.Context, name string, mode Mode) error {
+	return Chmod(ctx, e.fsys, name, mode)
+}
+
+func (e *encryptFS) Chtimes(
+	ctx context.Context, name string, atime, mtime time.Time,
+) error {
+	return Chtimes(ctx, e.fsys, name, atime, mtime)
+}
+
+func (e *encryptFS) Localize(
+	ctx context.Context, name string,
+) (string, error) {
+	return Localize(ctx, e.fsys, name)
+}
+
+func (e *encryptFS) Close() error { return Close(e.fsys) }
+
+var (
+	_ ChmodFS    = (*encryptFS)(nil)
+	_ ChtimesFS  = (*encryptFS)(nil)
+	_ CreateFS   = (*encryptFS)(nil)
+	_ LocalizeFS = (*encryptFS)(nil)
+	_ MkdirFS    = (*encryptFS)(nil)
+	_ ReadDirFS  = (*encryptFS)(nil)
+	_ RemoveFS   = (*encryptFS)(nil)
+	_ RenameFS   = (*encryptFS)(nil)
+	_ StatFS     = (*encryptFS)(nil)
+)
+
+// Layout of the streams written by the GCM cipher.
+const (
+	gcmSaltSize  = 32
+	gcmChunkSize = 64 << 10
+	gcmTagSize   = 16
+)
+
+// NewGCMCipher returns a [Cipher] that encrypts with AES-GCM under key,
+// which must be 16, 24, or 32 bytes long.
+//
+// Each file begins with a random salt, from which a key for that file is
+// derived, so no two files share a key and nonce. The contents follow in
+// authenticated chunks of 64 KiB, each numbered so that chunks cannot be
+// reordered, and the last chunk is marked so that truncation is detected.
+// A file grows by 32 bytes plus 16 bytes per chunk.
+func NewGCMCipher(key []byte) (Cipher, error) {
+	if _, err := aes.NewCipher(key); err != nil {
+		return nil, err
+	}
+	return &gcmCipher{key: key}, nil
+}
+
+type gcmCipher struct {
+	key []byte
+}
+
+// aead returns the AEAD for the file with the given salt.
+func (c *gcmCipher) aead(salt []byte) (cipher.AEAD, error) {
+	key, err := hkdf.Key(sha256.New, c.key, salt, "lesiw.io/fs gcm", 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// gcmNonce returns the nonce of chunk n.
+func gcmNonce(nonce []byte, n uint64, last bool) []byte {
+	clear(nonce)
+	binary.BigEndian.PutUint64(nonce[3:11], n)
+	if last {
+		nonce[11] = 1
+	}
+	return nonce
+}
+
+func (c *gcmCipher) Encrypt(w io.Writer) io.WriteCloser {
+	return &gcmWriter{c: c, w: w}
+}
+
+func (c *gcmCipher) Decrypt(r io.Reader) io.Reader {
+	return &gcmReader{c: c, r: r}
+}
+
+func (c *gcmCipher) PlaintextSize(size int64) int64 {
+	size -= gcmSaltSize
+	full := size / (gcmChunkSize + gcmTagSize)
+	rest := size % (gcmChunkSize + gcmTagSize)
+	return max(full*gcmChunkSize+rest-gcmTagSize, 0)
+}
+
+type gcmWriter struct {
+	c     *gcmCipher
+	w     io.Writer
+	aead  cipher.AEAD
+	buf   []byte
+	nonce [12]byte
+	n     uint64
+	err   error
+}
+
+// start writes the salt and sets up the AEAD on first use.
+func (w *gcmWriter) start() error {
+	if w.aead != nil || w.err != nil {
+		return w.err
+	}
+	salt := make([]byte, gcmSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		w.err = err
+		return err
+	}
+	if w.aead, w.err = w.c.aead(salt); w.err != nil {
+		return w.err
+	}
+	w.buf = make([]byte, 0, gcmChunkSize+gcmTagSize)
+	_, w.err = w.w.Write(salt)
+	return w.err
+}
+
+// seal encrypts and writes the buffered chunk.
+func (w *gcmWriter) seal(last bool) error {
+	nonce := gcmNonce(w.nonce[:], w.n, last)
+	w.n++
+	out := w.aead.Seal(w.buf[:0], nonce, w.buf, nil)
+	_, err := w.w.Write(out)
+	w.buf = w.buf[:0]
+	return err
+}
+
+func (w *gcmWriter) Write(p []byte) (int, error) {
+	if err := w.start(); err != nil {
+		return 0, err
+	}
+	var n int
+	for len(p) > 0 {
+		k := min(len(p), gcmChunkSize-len(w.buf))
+		w.buf = append(w.buf, p[:k]...)
+		n, p = n+k, p[k:]
+		if len(w.buf) == gcmChunkSize {
+			if w.err = w.seal(false); w.err != nil {
+				return n, w.err
+			}
+		}
+	}
+	return n, nil
+}
+
+// Close writes the last chunk, which is always shorter than a full one and
+// may be empty.
+func (w *gcmWriter) Close() error {
+	if err := w.start(); err != nil {
+		return err
+	}
+	w.err = w.seal(true)
+	if w.err == nil {
+		w.err = ErrClosed
+		return nil
+	}
+	return w.err
+}
+
+type gcmReader struct {
+	c     *gcmCipher
+	r     io.Reader
+	aead  cipher.AEAD
+	buf   []byte // ciphertext of the current chunk
+	plain []byte // unread plaintext of the current chunk
+	nonce [12]byte
+	n     uint64
+	last  bool
+	err   error
+}
+
+func (r *gcmReader) Read(p []byte) (int, error) {
+	for len(r.plain) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		if r.last {
+			return 0, io.EOF
+		}
+		r.err = r.next()
+	}
+	n := copy(p, r.plain)
+	r.plain = r.plain[n:]
+	return n, nil
+}
+
+// next reads and decrypts the next chunk.
+func (r *gcmReader) next() error {
+	if r.aead == nil {
+		salt := make([]byte, gcmSaltSize)
+		if _, err := io.ReadFull(r.r, salt); err != nil {
+			return decryptError(err)
+		}
+		aead, err := r.c.aead(salt)
+		if err != nil {
+			return err
+		}
+		r.aead = aead
+		r.buf = make([]byte, gcmChunkSize+gcmTagSize)
+	}
+	n, err := io.ReadFull(r.r, r.buf)
+	switch {
+	case err == io.ErrUnexpectedEOF:
+		r.last = true
+	case err != nil:
+		return decryptError(err)
+	}
+	nonce := gcmNonce(r.nonce[:], r.n, r.last)
+	r.n++
+	plain, err := r.aead.Open(r.buf[:0], nonce, r.buf[:n], nil)
+	if err != nil {
+		return ErrDecryptFailed
+	}
+	r.plain = plain
+	return nil
+}
+
+// decryptError reports a stream that ends before its last chunk as failing
+// authentication.
+func decryptError(err error) error {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return ErrDecryptFailed
+	}
+	return err
+}
@@ -0,0 +1,154 @@
+package fs_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"lesiw.io/fs"
+	"lesiw.io/fs/memfs"
+)
+
+func newEncryptFS(t *testing.T) (backend, fsys fs.FS) {
+	t.Helper()
+	c, err := fs.NewGCMCipher(bytes.Repeat([]byte("k"), 32))
+	if err != nil {
+		t.Fatalf("NewGCMCipher() error = %v", err)
+	}
+	backend = memfs.New()
+	return backend, fs.Encrypt(backend, c)
+}
+
+func TestEncrypt(t *testing.T) {
+	tests := []struct {
+		name string
+		size int
+	}{
+		{"Empty", 0},
+		{"Small", 100},
+		{"Chunk", 64 << 10},
+		{"Chunks", 150 << 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			backend, fsys := newEncryptFS(t)
+			want := bytes.Repeat([]byte("secret!"), tt.size/7+1)[:tt.size]
+
+			if err := fs.WriteFile(ctx, fsys, "f.txt", want); err != nil {
+				t.Fatalf("WriteFile() error = %v", err)
+			}
+			raw, err := fs.ReadFile(ctx, backend, "f.txt")
+			if err != nil {
+				t.Fatalf("ReadFile(backend) error = %v", err)
+			}
+			if len(raw) <= len(want) || bytes.Contains(raw, []byte("secret!")) {
+				t.Errorf("backend holds plaintext (%d bytes)", len(raw))
+			}
+
+			got, err := fs.ReadFile(ctx, fsys, "f.txt")
+			if err != nil {
+				t.Fatalf("ReadFile() error = %v", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("ReadFile() = %d bytes, want %d", len(got), len(want))
+			}
+			info, err := fs.Stat(ctx, fsys, "f.txt")
+			if err != nil {
+				t.Fatalf("Stat() error = %v", err)
+			}
+			if got, want := info.Size(), int64(len(want)); got != want {
+				t.Errorf("Stat().Size() = %d, want %d", got, want)
+			}
+		})
+	}
+}
+
+func TestEncryptTamper(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func([]byte) []byte
+	}{
+		{"FlipByte", func(b []byte) []byte {
+			b[len(b)/2] ^= 1
+			return b
+		}},
+		{"Truncate", func(b []byte) []byte { return b[:len(b)-1] }},
+		{"DropLastChunk", func(b []byte) []byte {
+			return b[:32+(64<<10)+16]
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			backend, fsys := newEncryptFS(t)
+			data := bytes.Repeat([]byte("x"), 100<<10)
+			if err := fs.WriteFile(ctx, fsys, "f.txt", data); err != nil {
+				t.Fatalf("WriteFile() error = %v", err)
+			}
+			raw, err := fs.ReadFile(ctx, backend, "f.txt")
+			if err != nil {
+				t.Fatalf("ReadFile(backend) error = %v", err)
+			}
+			raw = tt.tamper(raw)
+			if err := fs.WriteFile(ctx, backend, "f.txt", raw); err != nil {
+				t.Fatalf("WriteFile(backend) error = %v", err)
+			}
+
+			_, err = fs.ReadFile(ctx, fsys, "f.txt")
+			if !errors.Is(err, fs.ErrDecryptFailed) {
+				t.Errorf("ReadFile() error = %v, want ErrDecryptFailed", err)
+			}
+		})
+	}
+}
+
+func TestEncryptAppend(t *testing.T) {
+	ctx := context.Background()
+	_, fsys := newEncryptFS(t)
+	for _, s := range []string{"one ", "two"} {
+		w, err := fs.Append(ctx, fsys, "f.txt")
+		if err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+		if _, err := io.WriteString(w, s); err != nil {
+			t.Fatalf("Write(%q) error = %v", s, err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatalf("Close() error = %v", err)
+		}
+	}
+	got, err := fs.ReadFile(ctx, fsys, "f.txt")
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if want := "one two"; string(got) != want {
+		t.Errorf("ReadFile() = %q, want %q", got, want)
+	}
+}
+
+func TestEncryptNoMkdir(t *testing.T) {
+	ctx := context.Background()
+	c, err := fs.NewGCMCipher(bytes.Repeat([]byte("k"), 32))
+	if err != nil {
+		t.Fatalf("NewGCMCipher() error = %v", err)
+	}
+	backend := noMkdirFS{memfs.New()}
+	fsys := fs.Encrypt(backend, c)
+
+	err = appendTar(ctx, fsys, "dir/", map[string]string{"a/b.txt": "b"})
+	if err != nil {
+		t.Fatalf("Append(dir/) error = %v", err)
+	}
+	got, err := fs.ReadFile(ctx, fsys, "dir/a/b.txt")
+	if err != nil || string(got) != "b" {
+		t.Errorf("ReadFile(dir/a/b.txt) = %q, %v, want %q", got, err, "b")
+	}
+	raw, err := fs.ReadFile(ctx, backend, "dir/a/b.txt")
+	if err != nil || string(raw) == "b" {
+		t.Errorf("backend ReadFile(dir/a/b.txt) = %q, %v, want ciphertext",
+			raw, err)
+	}
+}
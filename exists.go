@@ -0,0 +1,56 @@
+package fs
+
+import (
+	"context"
+	"errors"
+
+	"lesiw.io/fs/path"
+)
+
+// An ExistsFS is a file system with the Exists method.
+//
+// Backends that can check for a file more cheaply than with a full Stat
+// implement ExistsFS. Object stores, for example, answer with a single HEAD
+// request, whereas their Stat must also look for a virtual directory.
+type ExistsFS interface {
+	FS
+
+	// Exists reports whether the named file exists. If name ends in a
+	// separator, it reports whether the directory exists.
+	//
+	// Without a trailing separator, Exists may report false for a
+	// directory, so that backends with virtual directories need not probe
+	// for one.
+	Exists(ctx context.Context, name string) (bool, error)
+}
+
+// Exists reports whether the named file exists. A trailing slash asks
+// whether a directory exists instead.
+// Analogous to: test -e, test -d, S3 HeadObject.
+//
+// A file that does not exist is not an error: Exists returns false and a
+// nil error. Other failures, such as a permission error, are returned.
+//
+// If fsys does not implement [ExistsFS], or Exists returns
+// [ErrUnsupported], Exists falls back to [Stat].
+//
+// Requires: [ExistsFS] || [StatFS]
+func Exists(ctx context.Context, fsys FS, name string) (bool, error) {
+	var err error
+	if name, err = localizePath(ctx, fsys, name); err != nil {
+		return false, err
+	}
+	if efs, ok := fsys.(ExistsFS); ok {
+		ok, err := efs.Exists(ctx, name)
+		if !errors.Is(err, ErrUnsupported) {
+			return ok, newPathError("exists", name, err)
+		}
+	}
+	info, err := Stat(ctx, fsys, name)
+	if errors.Is(err, ErrNotExist) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return !path.IsDir(name) || info.IsDir(), nil
+}
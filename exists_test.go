@@ -0,0 +1,35 @@
+package fs_test
+
+import (
+	"context"
+	"testing"
+
+	"lesiw.io/fs"
+	"lesiw.io/fs/memfs"
+)
+
+func TestExists(t *testing.T) {
+	ctx, fsys := context.Background(), memfs.New()
+	if err := fs.WriteFile(ctx, fsys, "dir/file.txt", nil); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"dir/file.txt", true},
+		{"dir", true},
+		{"dir/", true},
+		{"dir/file.txt/", false},
+		{"missing.txt", false},
+		{"missing/", false},
+	}
+	for _, tt := range tests {
+		got, err := fs.Exists(ctx, fsys, tt.name)
+		if err != nil {
+			t.Errorf("Exists(%q) error = %v", tt.name, err)
+		} else if got != tt.want {
+			t.Errorf("Exists(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -9,6 +9,12 @@ import (
 	"lesiw.io/fs"
 )
 
+// mtimePrecision is the coarsest modification time precision accepted from
+// a backend. Many backends store times with nanosecond precision, but some
+// truncate to microseconds (SFTP v3 to seconds, FAT to two seconds), and
+// object stores may round to the second.
+const mtimePrecision = time.Second
+
 func testChtimes(ctx context.Context, t *testing.T, fsys fs.FS) {
 	t.Run("Chtimes", func(t *testing.T) {
 		fileName := "test_chtimes_file.txt"
@@ -33,22 +39,7 @@ func testChtimes(ctx context.Context, t *testing.T, fsys fs.FS) {
 			t.Fatalf("Chtimes(%q): %v", fileName, err)
 		}
 
-		info, statErr := fs.Stat(ctx, fsys, fileName)
-		if statErr != nil {
-			if errors.Is(statErr, fs.ErrUnsupported) {
-				t.Skip("Stat not supported")
-			}
-			t.Fatalf("Stat(%q): %v", fileName, statErr)
-		}
-
-		// Allow 1 second tolerance for filesystem time granularity
-		gotMtime := info.ModTime()
-		if gotMtime.Sub(mtime).Abs() > time.Second {
-			t.Errorf(
-				"Chtimes(%q): ModTime() = %v, want %v",
-				fileName, gotMtime, mtime,
-			)
-		}
+		checkMtime(ctx, t, fsys, fileName, mtime)
 
 		data, readErr := fs.ReadFile(ctx, fsys, fileName)
 		if readErr != nil {
@@ -61,5 +52,57 @@ func testChtimes(ctx context.Context, t *testing.T, fsys fs.FS) {
 				fileName, data, testData,
 			)
 		}
+
+		t.Run("SubSecond", func(t *testing.T) {
+			// The backend may drop the fraction, but not shift the time.
+			mtime := time.Date(2022, 3, 4, 5, 6, 7, 123456789, time.UTC)
+			err := fs.Chtimes(ctx, fsys, fileName, atime, mtime)
+			if err != nil {
+				t.Fatalf("Chtimes(%q): %v", fileName, err)
+			}
+			checkMtime(ctx, t, fsys, fileName, mtime)
+		})
+
+		t.Run("ZeroMtime", func(t *testing.T) {
+			// A zero mtime leaves the modification time unchanged.
+			want := time.Date(2019, 2, 3, 4, 5, 6, 0, time.UTC)
+			if err := fs.Chtimes(ctx, fsys, fileName, atime, want); err != nil {
+				t.Fatalf("Chtimes(%q): %v", fileName, err)
+			}
+			err := fs.Chtimes(ctx, fsys, fileName, atime, time.Time{})
+			if err != nil {
+				t.Fatalf("Chtimes(%q, zero mtime): %v", fileName, err)
+			}
+			checkMtime(ctx, t, fsys, fileName, want)
+		})
+
+		t.Run("ZeroAtime", func(t *testing.T) {
+			// A zero atime still sets the modification time.
+			want := time.Date(2018, 7, 8, 9, 10, 11, 0, time.UTC)
+			err := fs.Chtimes(ctx, fsys, fileName, time.Time{}, want)
+			if err != nil {
+				t.Fatalf("Chtimes(%q, zero atime): %v", fileName, err)
+			}
+			checkMtime(ctx, t, fsys, fileName, want)
+		})
 	})
 }
+
+// checkMtime reports an error if the modification time of name is not
+// within mtimePrecision of want.
+func checkMtime(
+	ctx context.Context, t *testing.T, fsys fs.FS, name string,
+	want time.Time,
+) {
+	t.Helper()
+	info, err := fs.Stat(ctx, fsys, name)
+	if err != nil {
+		if errors.Is(err, fs.ErrUnsupported) {
+			t.Skip("Stat not supported")
+		}
+		t.Fatalf("Stat(%q): %v", name, err)
+	}
+	if got := info.ModTime(); got.Sub(want).Abs() >= mtimePrecision {
+		t.Errorf("Chtimes(%q): ModTime() = %v, want %v", name, got, want)
+	}
+}
@@ -0,0 +1,68 @@
+package fstest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+
+	"lesiw.io/fs"
+	"lesiw.io/fs/path"
+)
+
+func testDeepNesting(
+	ctx context.Context, t *testing.T, fsys fs.FS, depth int,
+) {
+	if depth <= 0 {
+		t.Skip("deep nesting test disabled")
+	}
+	if _, ok := fsys.(fs.MkdirFS); !ok {
+		t.Skip("MkdirFS not supported")
+	}
+
+	root := "test_deep"
+	dir := root
+	for i := range depth {
+		dir = path.Join(dir, fmt.Sprintf("d%d", i))
+	}
+	if err := fs.MkdirAll(ctx, fsys, dir); err != nil {
+		if errors.Is(err, fs.ErrUnsupported) {
+			t.Skip("write operations not supported")
+		}
+		t.Fatalf("MkdirAll(%q): %v", dir, err)
+	}
+	cleanup(ctx, t, fsys, root)
+
+	name := path.Join(dir, "bottom.txt")
+	if err := fs.WriteFile(ctx, fsys, name, []byte("bottom")); err != nil {
+		t.Fatalf("WriteFile(%q): %v", name, err)
+	}
+
+	var found bool
+	want := normalizePath(name)
+	for e, err := range fs.Walk(ctx, fsys, root, -1) {
+		if err != nil {
+			t.Fatalf("Walk(%q) iteration: %v", root, err)
+		}
+		if slices.Equal(normalizePath(e.Path()), want) {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Walk(%q): %q not found", root, name)
+	}
+
+	if err := fs.RemoveAll(ctx, fsys, root); err != nil {
+		t.Fatalf("RemoveAll(%q): %v", root, err)
+	}
+	_, err := fs.Stat(ctx, fsys, root)
+	switch {
+	case errors.Is(err, fs.ErrUnsupported):
+	case !errors.Is(err, fs.ErrNotExist):
+		t.Errorf(
+			"Stat(%q) after RemoveAll: got %v, want ErrNotExist",
+			root, err,
+		)
+	}
+}
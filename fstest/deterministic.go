@@ -0,0 +1,216 @@
+package fstest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"lesiw.io/fs"
+	"lesiw.io/fs/path"
+)
+
+// Deterministic returns a filesystem that wraps fsys with a seeded random
+// source and a fixed clock, for golden-file tests of temporary names and
+// timestamps.
+//
+// Temp and TempDir create name-randomhex in the working directory of fsys,
+// drawing the suffix from a generator seeded with seed, so two wrappers
+// with the same seed produce the same sequence of names. A name that
+// already exists is skipped for the next one drawn, so wrappers sharing a
+// seed and a backend do not clobber each other's files. Files written
+// with Create are given now as their access and modification times when
+// closed, and a Chtimes with both times zero sets them to now, like touch.
+// Setting times requires fsys to implement [fs.ChtimesFS]; otherwise they
+// are left as fsys sets them.
+//
+// The wrapper forwards Open, Create, Append, Stat, ReadDir, Mkdir, Remove,
+// Rename, Chmod, Chtimes, Truncate, Localize, and Close to fsys. Other
+// operations fall back to the implementations built on these.
+func Deterministic(fsys fs.FS, seed int64, now time.Time) fs.FS {
+	return &deterministicFS{
+		fsys: fsys,
+		rand: rand.New(rand.NewPCG(uint64(seed), 0)),
+		now:  now,
+	}
+}
+
+// tempAttempts is how many names Temp and TempDir draw before giving up.
+const tempAttempts = 100
+
+type deterministicFS struct {
+	fsys fs.FS
+	now  time.Time
+
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+// tempName returns name with a suffix drawn from the seeded generator.
+func (d *deterministicFS) tempName(name string) string {
+	d.mu.Lock()
+	hi, lo := d.rand.Uint64(), d.rand.Uint64()
+	d.mu.Unlock()
+	if name == "" {
+		name = "tmp"
+	}
+	return fmt.Sprintf("%s-%016x%016x", name, hi, lo)
+}
+
+// touch sets the times of name to the fixed clock.
+func (d *deterministicFS) touch(ctx context.Context, name string) error {
+	err := fs.Chtimes(ctx, d.fsys, name, d.now, d.now)
+	if errors.Is(err, fs.ErrUnsupported) {
+		return nil
+	}
+	return err
+}
+
+func (d *deterministicFS) Open(
+	ctx context.Context, name string,
+) (io.ReadCloser, error) {
+	return fs.Open(ctx, d.fsys, name)
+}
+
+func (d *deterministicFS) Create(
+	ctx context.Context, name string,
+) (io.WriteCloser, error) {
+	w, err := fs.Create(ctx, d.fsys, name)
+	if err != nil || path.IsDir(name) {
+		return w, err
+	}
+	return &touchWriter{WriteCloser: w, touch: func() error {
+		return d.touch(ctx, name)
+	}}, nil
+}
+
+func (d *deterministicFS) Append(
+	ctx context.Context, name string,
+) (io.WriteCloser, error) {
+	return fs.Append(ctx, d.fsys, name)
+}
+
+func (d *deterministicFS) Stat(
+	ctx context.Context, name string,
+) (fs.FileInfo, error) {
+	return fs.Stat(ctx, d.fsys, name)
+}
+
+func (d *deterministicFS) ReadDir(
+	ctx context.Context, name string,
+) iter.Seq2[fs.DirEntry, error] {
+	return fs.ReadDir(ctx, d.fsys, name)
+}
+
+func (d *deterministicFS) Mkdir(ctx context.Context, name string) error {
+	return fs.Mkdir(ctx, d.fsys, name)
+}
+
+func (d *deterministicFS) Remove(ctx context.Context, name string) error {
+	return fs.Remove(ctx, d.fsys, name)
+}
+
+func (d *deterministicFS) Rename(
+	ctx context.Context, oldname, newname string,
+) error {
+	return fs.Rename(ctx, d.fsys, oldname, newname)
+}
+
+func (d *deterministicFS) Chmod(
+	ctx context.Context, name string, mode fs.Mode,
+) error {
+	return fs.Chmod(ctx, d.fsys, name, mode)
+}
+
+func (d *deterministicFS) Chtimes(
+	ctx context.Context, name string, atime, mtime time.Time,
+) error {
+	if atime.IsZero() && mtime.IsZero() {
+		atime, mtime = d.now, d.now
+	}
+	return fs.Chtimes(ctx, d.fsys, name, atime, mtime)
+}
+
+func (d *deterministicFS) Truncate(
+	ctx context.Context, name string, size int64,
+) error {
+	return fs.Truncate(ctx, d.fsys, name, size)
+}
+
+func (d *deterministicFS) Temp(
+	ctx context.Context, name string,
+) (string, error) {
+	for range tempAttempts {
+		tmp := d.tempName(name)
+		// Creating a file replaces any file already there, so check first.
+		ok, err := fs.Exists(ctx, d.fsys, tmp)
+		if err != nil && !errors.Is(err, fs.ErrUnsupported) {
+			return "", err
+		} else if ok {
+			continue
+		}
+		if err := fs.WriteFile(ctx, d.fsys, tmp, nil); err != nil {
+			return "", err
+		}
+		return tmp, nil
+	}
+	return "", &fs.PathError{Op: "temp", Path: name, Err: fs.ErrExist}
+}
+
+func (d *deterministicFS) TempDir(
+	ctx context.Context, name string,
+) (string, error) {
+	ctx = fs.WithDirMode(ctx, 0700)
+	for range tempAttempts {
+		tmp := d.tempName(name)
+		err := fs.Mkdir(ctx, d.fsys, tmp)
+		if errors.Is(err, fs.ErrExist) {
+			continue
+		} else if err != nil {
+			return "", err
+		}
+		return tmp, nil
+	}
+	return "", &fs.PathError{Op: "tempdir", Path: name, Err: fs.ErrExist}
+}
+
+func (d *deterministicFS) Localize(
+	ctx context.Context, name string,
+) (string, error) {
+	return fs.Localize(ctx, d.fsys, name)
+}
+
+func (d *deterministicFS) Close() error { return fs.Close(d.fsys) }
+
+// touchWriter calls touch after the underlying writer is closed.
+type touchWriter struct {
+	io.WriteCloser
+	touch func() error
+}
+
+func (w *touchWriter) Close() error {
+	if err := w.WriteCloser.Close(); err != nil {
+		return err
+	}
+	return w.touch()
+}
+
+var (
+	_ fs.AppendFS   = (*deterministicFS)(nil)
+	_ fs.ChmodFS    = (*deterministicFS)(nil)
+	_ fs.ChtimesFS  = (*deterministicFS)(nil)
+	_ fs.CreateFS   = (*deterministicFS)(nil)
+	_ fs.LocalizeFS = (*deterministicFS)(nil)
+	_ fs.MkdirFS    = (*deterministicFS)(nil)
+	_ fs.ReadDirFS  = (*deterministicFS)(nil)
+	_ fs.RemoveFS   = (*deterministicFS)(nil)
+	_ fs.RenameFS   = (*deterministicFS)(nil)
+	_ fs.StatFS     = (*deterministicFS)(nil)
+	_ fs.TempDirFS  = (*deterministicFS)(nil)
+	_ fs.TempFS     = (*deterministicFS)(nil)
+	_ fs.TruncateFS = (*deterministicFS)(nil)
+)
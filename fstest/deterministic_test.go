@@ -0,0 +1,95 @@
+package fstest_test
+
+import (
+	"testing"
+	"time"
+
+	"lesiw.io/fs"
+	"lesiw.io/fs/fstest"
+	"lesiw.io/fs/memfs"
+	"lesiw.io/fs/osfs"
+)
+
+func TestDeterministic(t *testing.T) {
+	ctx := t.Context()
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	temps := func() []string {
+		fsys := fstest.Deterministic(memfs.New(), 42, now)
+		var names []string
+		for _, name := range []string{"file", "dir/"} {
+			w, err := fs.Temp(ctx, fsys, name)
+			if err != nil {
+				t.Fatalf("Temp(%q) error = %v", name, err)
+			}
+			if err := w.Close(); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+			names = append(names, w.Path())
+		}
+		return names
+	}
+	a, b := temps(), temps()
+	for i := range a {
+		if a[i] != b[i] {
+			t.Errorf("Temp() = %q, then %q with the same seed", a[i], b[i])
+		}
+	}
+	if a[0] == a[1] {
+		t.Errorf("Temp() = %q twice", a[0])
+	}
+
+	fsys := fstest.Deterministic(osfs.NewTemp(), 42, now)
+	defer fs.Close(fsys)
+	if err := fs.WriteFile(ctx, fsys, "file.txt", []byte("x")); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	info, err := fs.Stat(ctx, fsys, "file.txt")
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	if got := info.ModTime(); !got.Equal(now) {
+		t.Errorf("ModTime() = %v, want %v", got, now)
+	}
+}
+
+func TestDeterministicShared(t *testing.T) {
+	ctx, backend := t.Context(), memfs.New()
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	var names []string
+	for _, data := range []string{"first", "second"} {
+		fsys := fstest.Deterministic(backend, 42, now)
+		for _, name := range []string{"file", "dir/"} {
+			w, err := fs.Temp(ctx, fsys, name)
+			if err != nil {
+				t.Fatalf("Temp(%q) error = %v", name, err)
+			}
+			if name == "file" {
+				if _, err := w.Write([]byte(data)); err != nil {
+					t.Fatalf("Write() error = %v", err)
+				}
+			}
+			if err := w.Close(); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+			names = append(names, w.Path())
+		}
+	}
+	if names[0] == names[2] || names[1] == names[3] {
+		t.Errorf("Temp() = %q, want distinct names on a shared backend",
+			names)
+	}
+	got, err := fs.ReadFile(ctx, backend, names[0])
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if want := "first"; string(got) != want {
+		t.Errorf("ReadFile(%q) = %q, want %q", names[0], got, want)
+	}
+}
+
+func TestDeterministicFS(t *testing.T) {
+	fsys := fstest.Deterministic(memfs.New(), 1, time.Now())
+	fstest.TestFS(t.Context(), t, fsys)
+}
@@ -0,0 +1,86 @@
+package fstest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"lesiw.io/fs"
+	"lesiw.io/fs/path"
+)
+
+func testEmptyFile(ctx context.Context, t *testing.T, fsys fs.FS) {
+	t.Run("WriteFile", func(t *testing.T) {
+		dir := "test_empty_writefile"
+		name := path.Join(dir, "empty.txt")
+		if err := fs.WriteFile(ctx, fsys, name, nil); err != nil {
+			if errors.Is(err, fs.ErrUnsupported) {
+				t.Skip("write operations not supported")
+			}
+			t.Fatalf("WriteFile(%q, nil): %v", name, err)
+		}
+		cleanup(ctx, t, fsys, dir)
+		checkEmptyFile(ctx, t, fsys, dir, "empty.txt")
+	})
+	t.Run("Create", func(t *testing.T) {
+		dir := "test_empty_create"
+		name := path.Join(dir, "empty.txt")
+		// Close without any writes must still create the file.
+		f, err := fs.Create(ctx, fsys, name)
+		if err != nil {
+			if errors.Is(err, fs.ErrUnsupported) {
+				t.Skip("write operations not supported")
+			}
+			t.Fatalf("Create(%q): %v", name, err)
+		}
+		cleanup(ctx, t, fsys, dir)
+		if err := f.Close(); err != nil {
+			t.Fatalf("Close(): %v", err)
+		}
+		checkEmptyFile(ctx, t, fsys, dir, "empty.txt")
+	})
+}
+
+// checkEmptyFile reports an error if dir/base is not an empty file.
+func checkEmptyFile(
+	ctx context.Context, t *testing.T, fsys fs.FS, dir, base string,
+) {
+	t.Helper()
+	name := path.Join(dir, base)
+
+	info, err := fs.Stat(ctx, fsys, name)
+	switch {
+	case errors.Is(err, fs.ErrUnsupported):
+	case err != nil:
+		t.Errorf("Stat(%q): %v", name, err)
+	case info.Size() != 0:
+		t.Errorf("Stat(%q).Size() = %d, want 0", name, info.Size())
+	}
+
+	data, err := fs.ReadFile(ctx, fsys, name)
+	switch {
+	case err != nil:
+		t.Errorf("ReadFile(%q): %v", name, err)
+	case data == nil:
+		t.Errorf("ReadFile(%q) = nil, want empty non-nil slice", name)
+	case len(data) != 0:
+		t.Errorf("ReadFile(%q) = %q, want empty", name, data)
+	}
+
+	var found bool
+	for e, err := range fs.ReadDir(ctx, fsys, dir) {
+		if errors.Is(err, fs.ErrUnsupported) {
+			return
+		}
+		if err != nil {
+			t.Errorf("ReadDir(%q): %v", dir, err)
+			return
+		}
+		if e.Name() == base {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("ReadDir(%q): %q not found", dir, base)
+	}
+}
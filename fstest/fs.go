@@ -24,8 +24,20 @@ type TestFSOption func(*testFSOpts)
 // testFSOpts holds configuration for TestFS.
 type testFSOpts struct {
 	expectedFiles []File
+	largeFileSize int64
+	unsupported   string
+	maxDepth      int
+	only, skip    []string
 }
 
+// defaultLargeFileSize is the size of the file written by the LargeFile
+// test when WithLargeFileSize is not given.
+const defaultLargeFileSize = 4 << 20
+
+// defaultMaxDepth is the nesting depth used by the DeepNesting test when
+// WithMaxDepth is not given.
+const defaultMaxDepth = 64
+
 // WithFiles specifies files that must exist in the filesystem.
 // When provided, TestFS validates these files exist on read-only filesystems.
 //
@@ -46,6 +58,70 @@ func WithFiles(files ...File) TestFSOption {
 	}
 }
 
+// WithLargeFileSize sets the size in bytes of the file streamed through the
+// filesystem by the LargeFile test. The default is 4 MiB.
+//
+// A size of zero or less skips the test.
+func WithLargeFileSize(n int64) TestFSOption {
+	return func(opts *testFSOpts) {
+		opts.largeFileSize = n
+	}
+}
+
+// WithUnsupportedChars declares characters the filesystem cannot store in
+// file names. Tests of names containing any of them are skipped.
+//
+// Example:
+//
+//	fstest.TestFS(ctx, t, fsys, fstest.WithUnsupportedChars("#%"))
+func WithUnsupportedChars(chars string) TestFSOption {
+	return func(opts *testFSOpts) {
+		opts.unsupported += chars
+	}
+}
+
+// WithMaxDepth sets how many directories deep the DeepNesting test nests
+// its file. The default is 64. Lower it for backends with path length
+// limits.
+//
+// A depth of zero or less skips the test.
+func WithMaxDepth(n int) TestFSOption {
+	return func(opts *testFSOpts) {
+		opts.maxDepth = n
+	}
+}
+
+// WithOnly runs only the named groups of tests, for implementers working
+// on one capability. The groups are the top-level subtests of TestFS:
+//
+//	Abs, Append, Chmod, Chown, Chtimes, Create, DeepNesting, DirFS,
+//	EmptyFile, FindUp, FindUpDotDot, Glob, LargeFile, Link, Localize,
+//	Mkdir, ReadDir, Remove, Rename, SpecialNames, Stat, Stress, Symlink,
+//	Temp, Truncate, Umask, Walk, WorkDir
+//
+// An unknown name fails the test, to catch typos. Repeated WithOnly options
+// add to the selection.
+//
+// Example:
+//
+//	fstest.TestFS(ctx, t, fsys, fstest.WithOnly("Symlink", "Link"))
+func WithOnly(names ...string) TestFSOption {
+	return func(opts *testFSOpts) {
+		opts.only = append(opts.only, names...)
+	}
+}
+
+// WithSkip skips the named groups of tests, such as Stress on a flaky
+// network backend. The group names are listed under [WithOnly], and an
+// unknown name fails the test. WithSkip takes precedence over WithOnly.
+// Both compose with [WithFiles]: on a read-only filesystem, the groups that
+// run check the files given there.
+func WithSkip(names ...string) TestFSOption {
+	return func(opts *testFSOpts) {
+		opts.skip = append(opts.skip, names...)
+	}
+}
+
 // TestFS runs a comprehensive compliance test suite on a filesystem
 // implementation.
 //
@@ -78,7 +154,10 @@ func TestFS(
 	t.Helper()
 
 	// Apply options
-	var o testFSOpts
+	o := testFSOpts{
+		largeFileSize: defaultLargeFileSize,
+		maxDepth:      defaultMaxDepth,
+	}
 	for _, opt := range opts {
 		opt(&o)
 	}
@@ -97,72 +176,112 @@ func TestFS(
 		}
 	}
 
-	t.Run("Abs", func(t *testing.T) {
-		testAbs(ctx, t, fsys)
-	})
-	t.Run("Append", func(t *testing.T) {
-		testAppend(ctx, t, fsys)
-	})
-	t.Run("Chmod", func(t *testing.T) {
-		testChmod(ctx, t, fsys)
-	})
-	t.Run("Chown", func(t *testing.T) {
-		testChown(ctx, t, fsys)
-	})
-	t.Run("Chtimes", func(t *testing.T) {
-		testChtimes(ctx, t, fsys)
-	})
-	t.Run("Create", func(t *testing.T) {
-		testCreate(ctx, t, fsys)
-	})
-	t.Run("DirFS", func(t *testing.T) {
-		testDirFS(ctx, t, fsys)
-	})
-	t.Run("Glob", func(t *testing.T) {
-		testGlob(ctx, t, fsys, files)
-	})
-	t.Run("Localize", func(t *testing.T) {
-		testLocalize(ctx, t, fsys)
-	})
-	t.Run("Mkdir", func(t *testing.T) {
-		testMkdir(ctx, t, fsys)
-	})
-	t.Run("ReadDir", func(t *testing.T) {
-		testReadDir(ctx, t, fsys, files)
-	})
-	t.Run("Remove", func(t *testing.T) {
-		testRemove(ctx, t, fsys)
-	})
-	t.Run("Rename", func(t *testing.T) {
-		testRename(ctx, t, fsys)
-	})
-	t.Run("Stat", func(t *testing.T) {
-		testStat(ctx, t, fsys, files)
-	})
-	t.Run("Stress", func(t *testing.T) {
-		testStress(ctx, t, fsys)
-	})
-	t.Run("Symlink", func(t *testing.T) {
-		testSymlink(ctx, t, fsys)
-	})
-	t.Run("Temp", func(t *testing.T) {
-		testTemp(ctx, t, fsys)
-	})
-	t.Run("Truncate", func(t *testing.T) {
-		testTruncate(ctx, t, fsys)
-	})
-	t.Run("Walk", func(t *testing.T) {
-		testWalk(ctx, t, fsys, files)
-	})
-	t.Run("FindUp", func(t *testing.T) {
-		testFindUp(ctx, t, fsys, files)
-	})
-	t.Run("FindUpDotDot", func(t *testing.T) {
-		testFindUpDotDot(ctx, t, fsys, files)
-	})
-	t.Run("WorkDir", func(t *testing.T) {
-		testWorkDir(ctx, t, fsys)
-	})
+	groups := []testGroup{
+		{"Abs", func(t *testing.T) {
+			testAbs(ctx, t, fsys)
+		}},
+		{"Append", func(t *testing.T) {
+			testAppend(ctx, t, fsys)
+		}},
+		{"Chmod", func(t *testing.T) {
+			testChmod(ctx, t, fsys)
+		}},
+		{"Chown", func(t *testing.T) {
+			testChown(ctx, t, fsys)
+		}},
+		{"Chtimes", func(t *testing.T) {
+			testChtimes(ctx, t, fsys)
+		}},
+		{"Create", func(t *testing.T) {
+			testCreate(ctx, t, fsys)
+		}},
+		{"DeepNesting", func(t *testing.T) {
+			testDeepNesting(ctx, t, fsys, o.maxDepth)
+		}},
+		{"DirFS", func(t *testing.T) {
+			testDirFS(ctx, t, fsys)
+		}},
+		{"EmptyFile", func(t *testing.T) {
+			testEmptyFile(ctx, t, fsys)
+		}},
+		{"Glob", func(t *testing.T) {
+			testGlob(ctx, t, fsys, files)
+		}},
+		{"LargeFile", func(t *testing.T) {
+			testLargeFile(ctx, t, fsys, o.largeFileSize)
+		}},
+		{"Link", func(t *testing.T) {
+			testLink(ctx, t, fsys)
+		}},
+		{"Localize", func(t *testing.T) {
+			testLocalize(ctx, t, fsys)
+		}},
+		{"Mkdir", func(t *testing.T) {
+			testMkdir(ctx, t, fsys)
+		}},
+		{"ReadDir", func(t *testing.T) {
+			testReadDir(ctx, t, fsys, files)
+		}},
+		{"Remove", func(t *testing.T) {
+			testRemove(ctx, t, fsys)
+		}},
+		{"Rename", func(t *testing.T) {
+			testRename(ctx, t, fsys)
+		}},
+		{"SpecialNames", func(t *testing.T) {
+			testSpecialNames(ctx, t, fsys, o.unsupported)
+		}},
+		{"Stat", func(t *testing.T) {
+			testStat(ctx, t, fsys, files)
+		}},
+		{"Stress", func(t *testing.T) {
+			testStress(ctx, t, fsys)
+		}},
+		{"Symlink", func(t *testing.T) {
+			testSymlink(ctx, t, fsys)
+		}},
+		{"Temp", func(t *testing.T) {
+			testTemp(ctx, t, fsys)
+		}},
+		{"Truncate", func(t *testing.T) {
+			testTruncate(ctx, t, fsys)
+		}},
+		{"Umask", func(t *testing.T) {
+			testUmask(ctx, t, fsys)
+		}},
+		{"Walk", func(t *testing.T) {
+			testWalk(ctx, t, fsys, files)
+		}},
+		{"FindUp", func(t *testing.T) {
+			testFindUp(ctx, t, fsys, files)
+		}},
+		{"FindUpDotDot", func(t *testing.T) {
+			testFindUpDotDot(ctx, t, fsys, files)
+		}},
+		{"WorkDir", func(t *testing.T) {
+			testWorkDir(ctx, t, fsys)
+		}},
+	}
+	for _, name := range slices.Concat(o.only, o.skip) {
+		if !slices.ContainsFunc(groups, func(g testGroup) bool {
+			return g.name == name
+		}) {
+			t.Fatalf("fstest: unknown test group %q", name)
+		}
+	}
+	for _, g := range groups {
+		if o.only != nil && !slices.Contains(o.only, g.name) ||
+			slices.Contains(o.skip, g.name) {
+			continue
+		}
+		t.Run(g.name, g.run)
+	}
+}
+
+// testGroup is a top-level subtest of TestFS.
+type testGroup struct {
+	name string
+	run  func(t *testing.T)
 }
 
 func normalizePath(p string) []string {
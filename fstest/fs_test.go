@@ -0,0 +1,35 @@
+package fstest_test
+
+import (
+	"slices"
+	"testing"
+
+	"lesiw.io/fs"
+	"lesiw.io/fs/fstest"
+	"lesiw.io/fs/memfs"
+)
+
+func TestFSGroups(t *testing.T) {
+	ops := func(opts ...fstest.TestFSOption) []string {
+		fsys, log := fs.Record(memfs.New())
+		fstest.TestFS(t.Context(), t, fsys, opts...)
+		var names []string
+		for _, op := range log.Ops() {
+			names = append(names, op.Op)
+		}
+		return names
+	}
+
+	got := ops(fstest.WithOnly("Truncate"))
+	if !slices.Contains(got, "truncate") {
+		t.Errorf("WithOnly(Truncate) ops = %q, want truncate", got)
+	}
+	got = ops(fstest.WithOnly("Stat"))
+	if slices.Contains(got, "truncate") {
+		t.Errorf("WithOnly(Stat) ops = %q, want no truncate", got)
+	}
+	got = ops(fstest.WithOnly("Truncate", "Stat"), fstest.WithSkip("Truncate"))
+	if slices.Contains(got, "truncate") {
+		t.Errorf("WithSkip(Truncate) ops = %q, want no truncate", got)
+	}
+}
@@ -0,0 +1,66 @@
+package fstest
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"testing"
+
+	"lesiw.io/fs"
+)
+
+// largeFileSeed seeds the data written by testLargeFile, so that a failure
+// reproduces byte for byte.
+var largeFileSeed = [32]byte([]byte("lesiw.io/fs/fstest large file!!!"))
+
+func testLargeFile(
+	ctx context.Context, t *testing.T, fsys fs.FS, size int64,
+) {
+	if size <= 0 {
+		t.Skip("large file test disabled")
+	}
+	name := "test_large_file.bin"
+
+	w, err := fs.Create(ctx, fsys, name)
+	if err != nil {
+		if errors.Is(err, fs.ErrUnsupported) {
+			t.Skip("write operations not supported")
+		}
+		t.Fatalf("Create(%q): %v", name, err)
+	}
+	cleanup(ctx, t, fsys, name)
+
+	// Hash the data on the way in and out so that neither copy is held
+	// in memory.
+	want := sha256.New()
+	src := io.TeeReader(rand.NewChaCha8(largeFileSeed), want)
+	n, err := io.CopyN(w, src, size)
+	if err != nil {
+		_ = w.Close()
+		t.Fatalf("Write(%q): wrote %d of %d bytes: %v", name, n, size, err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close(%q): %v", name, err)
+	}
+
+	r, err := fs.Open(ctx, fsys, name)
+	if err != nil {
+		t.Fatalf("Open(%q): %v", name, err)
+	}
+	defer r.Close()
+
+	got := sha256.New()
+	n, err = io.Copy(got, r)
+	if err != nil {
+		t.Fatalf("Read(%q): read %d of %d bytes: %v", name, n, size, err)
+	}
+	if n != size {
+		t.Fatalf("Read(%q) = %d bytes, want %d", name, n, size)
+	}
+	if !bytes.Equal(got.Sum(nil), want.Sum(nil)) {
+		t.Errorf("Read(%q): content differs from data written", name)
+	}
+}
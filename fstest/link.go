@@ -0,0 +1,66 @@
+package fstest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"lesiw.io/fs"
+)
+
+func testLink(ctx context.Context, t *testing.T, fsys fs.FS) {
+	t.Helper()
+
+	oldName := "test_link_old.txt"
+	if err := fs.WriteFile(ctx, fsys, oldName, []byte("old")); err != nil {
+		if errors.Is(err, fs.ErrUnsupported) {
+			t.Skip("write operations not supported")
+		}
+		t.Fatalf("WriteFile(%q): %v", oldName, err)
+	}
+	cleanup(ctx, t, fsys, oldName)
+
+	newName := "test_link_new.txt"
+	if err := fs.Link(ctx, fsys, oldName, newName); err != nil {
+		if errors.Is(err, fs.ErrUnsupported) {
+			t.Skip("Link not supported")
+		}
+		t.Fatalf("Link(%q, %q): %v", oldName, newName, err)
+	}
+	cleanup(ctx, t, fsys, newName)
+
+	want := []byte("written through the new name")
+	if err := fs.WriteFile(ctx, fsys, newName, want); err != nil {
+		t.Fatalf("WriteFile(%q): %v", newName, err)
+	}
+
+	data, err := fs.ReadFile(ctx, fsys, oldName)
+	if err != nil {
+		t.Fatalf("ReadFile(%q): %v", oldName, err)
+	}
+	if !bytes.Equal(data, want) {
+		t.Errorf(
+			"ReadFile(%q) after writing %q = %q, want %q",
+			oldName, newName, data, want,
+		)
+	}
+
+	oldInfo, err := fs.Stat(ctx, fsys, oldName)
+	if err != nil {
+		if errors.Is(err, fs.ErrUnsupported) {
+			return
+		}
+		t.Fatalf("Stat(%q): %v", oldName, err)
+	}
+	newInfo, err := fs.Stat(ctx, fsys, newName)
+	if err != nil {
+		t.Fatalf("Stat(%q): %v", newName, err)
+	}
+	if oldInfo.Size() != newInfo.Size() {
+		t.Errorf(
+			"Stat(%q).Size() = %d, Stat(%q).Size() = %d, want equal",
+			oldName, oldInfo.Size(), newName, newInfo.Size(),
+		)
+	}
+}
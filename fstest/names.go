@@ -0,0 +1,98 @@
+package fstest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"lesiw.io/fs"
+	"lesiw.io/fs/path"
+)
+
+// specialNames are file names that need escaping in URLs or other
+// encodings used by remote backends.
+var specialNames = []string{
+	"a b.txt",
+	"naïve.txt",
+	"c#d.txt",
+	"100% done.txt",
+	"e+f.txt",
+}
+
+func testSpecialNames(
+	ctx context.Context, t *testing.T, fsys fs.FS, unsupported string,
+) {
+	dir := "test_special_names"
+	for _, base := range specialNames {
+		t.Run(base, func(t *testing.T) {
+			if strings.ContainsAny(base, unsupported) {
+				t.Skipf("name %q has unsupported characters", base)
+			}
+			testSpecialName(ctx, t, fsys, dir, base)
+		})
+	}
+}
+
+func testSpecialName(
+	ctx context.Context, t *testing.T, fsys fs.FS, dir, base string,
+) {
+	name := path.Join(dir, base)
+	data := []byte("special " + base)
+
+	if err := fs.WriteFile(ctx, fsys, name, data); err != nil {
+		if errors.Is(err, fs.ErrUnsupported) {
+			t.Skip("write operations not supported")
+		}
+		t.Fatalf("WriteFile(%q): %v", name, err)
+	}
+	cleanup(ctx, t, fsys, dir)
+
+	got, err := fs.ReadFile(ctx, fsys, name)
+	if err != nil {
+		t.Fatalf("ReadFile(%q): %v", name, err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Errorf("ReadFile(%q) = %q, want %q", name, got, data)
+	}
+
+	info, err := fs.Stat(ctx, fsys, name)
+	switch {
+	case errors.Is(err, fs.ErrUnsupported):
+	case err != nil:
+		t.Errorf("Stat(%q): %v", name, err)
+	case info.Name() != base:
+		t.Errorf("Stat(%q).Name() = %q, want %q", name, info.Name(), base)
+	}
+
+	var found bool
+	for e, err := range fs.ReadDir(ctx, fsys, dir) {
+		if errors.Is(err, fs.ErrUnsupported) {
+			found = true
+			break
+		}
+		if err != nil {
+			t.Fatalf("ReadDir(%q): %v", dir, err)
+		}
+		if e.Name() == base {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("ReadDir(%q): %q not found", dir, base)
+	}
+
+	if err := fs.Remove(ctx, fsys, name); err != nil {
+		if errors.Is(err, fs.ErrUnsupported) {
+			return
+		}
+		t.Fatalf("Remove(%q): %v", name, err)
+	}
+	if _, err := fs.ReadFile(ctx, fsys, name); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf(
+			"ReadFile(%q) after Remove: got %v, want ErrNotExist",
+			name, err,
+		)
+	}
+}
@@ -0,0 +1,115 @@
+package fstest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"lesiw.io/fs"
+)
+
+func testUmask(ctx context.Context, t *testing.T, fsys fs.FS) {
+	t.Run("UmaskFile", func(t *testing.T) {
+		testUmaskFile(ctx, t, fsys)
+	})
+	t.Run("UmaskDir", func(t *testing.T) {
+		testUmaskDir(ctx, t, fsys)
+	})
+}
+
+// umaskCases pairs requested modes and umasks with the modes they should
+// produce. The second case uses bits a typical host umask of 022 leaves
+// alone, so that it fails if the context's umask is ignored.
+var umaskCases = []struct {
+	mode, umask, want fs.Mode
+}{
+	{0666, 0022, 0644},
+	{0640, 0040, 0600},
+}
+
+func testUmaskFile(ctx context.Context, t *testing.T, fsys fs.FS) {
+	// A file created 0600 shows whether the filesystem stores modes.
+	control := "test_umask_control.txt"
+	err := fs.WriteFile(fs.WithFileMode(ctx, 0600), fsys, control, nil)
+	if errors.Is(err, fs.ErrUnsupported) {
+		t.Skip("write operations not supported")
+	}
+	if err != nil {
+		t.Fatalf("WriteFile(%q): %v", control, err)
+	}
+	cleanup(ctx, t, fsys, control)
+	if perm, ok := statPerm(ctx, t, fsys, control); !ok || perm != 0600 {
+		t.Skip("file modes not stored")
+	}
+
+	for _, tc := range umaskCases {
+		name := "test_umask_file.txt"
+		mctx := fs.WithUmask(fs.WithFileMode(ctx, tc.mode), tc.umask)
+		if err := fs.WriteFile(mctx, fsys, name, nil); err != nil {
+			t.Fatalf("WriteFile(%q): %v", name, err)
+		}
+		perm, _ := statPerm(ctx, t, fsys, name)
+		if perm != tc.want {
+			t.Errorf(
+				"WriteFile(%q) with mode %o, umask %o: Mode() = %o, want %o",
+				name, tc.mode, tc.umask, perm, tc.want,
+			)
+		}
+		if err := fs.Remove(ctx, fsys, name); err != nil {
+			t.Fatalf("Remove(%q): %v", name, err)
+		}
+	}
+}
+
+func testUmaskDir(ctx context.Context, t *testing.T, fsys fs.FS) {
+	control := "test_umask_control_dir"
+	err := fs.Mkdir(fs.WithDirMode(ctx, 0700), fsys, control)
+	if errors.Is(err, fs.ErrUnsupported) {
+		t.Skip("MkdirFS not supported")
+	}
+	if err != nil {
+		t.Fatalf("Mkdir(%q): %v", control, err)
+	}
+	cleanup(ctx, t, fsys, control)
+	if perm, ok := statPerm(ctx, t, fsys, control); !ok || perm != 0700 {
+		t.Skip("directory modes not stored")
+	}
+
+	for _, tc := range umaskCases {
+		name := "test_umask_dir/sub"
+		mode := tc.mode | 0100 // Keep the directory searchable.
+		mctx := fs.WithUmask(fs.WithDirMode(ctx, mode), tc.umask)
+		if err := fs.MkdirAll(mctx, fsys, name); err != nil {
+			t.Fatalf("MkdirAll(%q): %v", name, err)
+		}
+		for _, dir := range []string{"test_umask_dir", name} {
+			perm, _ := statPerm(ctx, t, fsys, dir)
+			if want := tc.want | 0100; perm != want {
+				t.Errorf(
+					"MkdirAll(%q) with mode %o, umask %o: "+
+						"%q Mode() = %o, want %o",
+					name, mode, tc.umask, dir, perm, want,
+				)
+			}
+		}
+		if err := fs.RemoveAll(ctx, fsys, "test_umask_dir"); err != nil {
+			t.Fatalf("RemoveAll(%q): %v", "test_umask_dir", err)
+		}
+	}
+}
+
+// statPerm returns the permission bits of name, reporting false if the
+// filesystem cannot stat it.
+func statPerm(
+	ctx context.Context, t *testing.T, fsys fs.FS, name string,
+) (fs.Mode, bool) {
+	t.Helper()
+	info, err := fs.Stat(ctx, fsys, name)
+	if errors.Is(err, fs.ErrUnsupported) {
+		return 0, false
+	}
+	if err != nil {
+		t.Fatalf("Stat(%q): %v", name, err)
+	}
+	return info.Mode().Perm(), true
+}
@@ -0,0 +1,559 @@
+// Package gitfs implements lesiw.io/fs.FS over a tree in a git repository.
+//
+// The filesystem is read-only and reflects a single revision. Objects are
+// read by running the git command, which must be installed.
+package gitfs
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"iter"
+	"os/exec"
+	stdpath "path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"lesiw.io/fs"
+	"lesiw.io/fs/path"
+)
+
+// New returns a read-only filesystem over the tree of revision in the
+// repository at repoPath. The revision may be anything git rev-parse accepts
+// that names a commit, such as a branch, a tag, or a commit hash.
+//
+// The tree is indexed once, when New is called. Modes and symbolic links
+// come from the tree entries, and every file reports the commit time as its
+// modification time. Submodules are not included.
+func New(repoPath, revision string) (fs.FS, error) {
+	ctx := context.Background()
+	f := &gitFS{
+		repo:    repoPath,
+		entries: make(map[string]*entry),
+	}
+
+	out, err := f.git(ctx, "rev-parse", "--verify", "--end-of-options",
+		revision+"^{commit}")
+	if err != nil {
+		return nil, err
+	}
+	f.commit = strings.TrimSpace(string(out))
+
+	out, err = f.git(ctx, "show", "-s", "--format=%ct", f.commit)
+	if err != nil {
+		return nil, err
+	}
+	sec, err := strconv.ParseInt(strings.TrimSpace(string(out)), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("gitfs: bad commit time: %w", err)
+	}
+	f.modTime = time.Unix(sec, 0).UTC()
+
+	out, err = f.git(ctx, "ls-tree", "-r", "-t", "-l", "-z", f.commit)
+	if err != nil {
+		return nil, err
+	}
+	f.entries["."] = &entry{name: ".", mode: fs.ModeDir | 0755}
+	for line := range bytes.SplitSeq(out, []byte{0}) {
+		if len(line) == 0 {
+			continue
+		}
+		if err := f.add(string(line)); err != nil {
+			return nil, err
+		}
+	}
+	for _, e := range f.entries {
+		slices.Sort(e.children)
+	}
+	return f, nil
+}
+
+type gitFS struct {
+	repo    string
+	commit  string
+	modTime time.Time
+	entries map[string]*entry
+}
+
+// entry is an indexed tree entry.
+type entry struct {
+	name     string
+	mode     fs.Mode
+	oid      string
+	size     int64
+	children []string
+}
+
+// add indexes one line of git ls-tree -l output:
+// "<mode> <type> <object> <size>\t<path>".
+func (f *gitFS) add(line string) error {
+	meta, name, ok := strings.Cut(line, "\t")
+	fields := strings.Fields(meta)
+	if !ok || len(fields) != 4 {
+		return fmt.Errorf("gitfs: bad ls-tree entry: %q", line)
+	}
+	var mode fs.Mode
+	switch fields[0] {
+	case "040000":
+		mode = fs.ModeDir | 0755
+	case "100644":
+		mode = 0644
+	case "100755":
+		mode = 0755
+	case "120000":
+		mode = fs.ModeSymlink | 0777
+	default:
+		return nil // Submodules and unknown entry types.
+	}
+	e := &entry{name: stdpath.Base(name), mode: mode, oid: fields[2]}
+	if fields[3] != "-" {
+		size, err := strconv.ParseInt(fields[3], 10, 64)
+		if err != nil {
+			return fmt.Errorf("gitfs: bad ls-tree entry: %q", line)
+		}
+		e.size = size
+	}
+	f.entries[name] = e
+
+	// ls-tree -t lists trees before their contents.
+	dir := stdpath.Dir(name)
+	if parent, ok := f.entries[dir]; ok {
+		parent.children = append(parent.children, e.name)
+	}
+	return nil
+}
+
+// git runs a git command in the repository and returns its output.
+func (f *gitFS) git(ctx context.Context, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git",
+		append([]string{"-C", f.repo}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		return nil, fmt.Errorf("gitfs: git %s: %s: %w", args[0], msg, err)
+	}
+	return out, nil
+}
+
+// key converts name, relative to any working directory in ctx, into an
+// index key. The root of the tree is ".".
+func key(ctx context.Context, name string) string {
+	if w := fs.WorkDir(ctx); w != "" && !path.IsAbs(name) {
+		name = path.Join(w, name)
+	}
+	name = stdpath.Clean("/" + name)[1:]
+	if name == "" {
+		return "."
+	}
+	return name
+}
+
+// lookup finds the entry for name. If follow is true, symbolic links are
+// followed in every element of name; otherwise the final element is not.
+func (f *gitFS) lookup(
+	ctx context.Context, op, name string, follow bool,
+) (string, *entry, error) {
+	k, e := f.resolve(ctx, key(ctx, name), follow, 0)
+	if e == nil {
+		return k, nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+	return k, e, nil
+}
+
+func (f *gitFS) resolve(
+	ctx context.Context, k string, follow bool, depth int,
+) (string, *entry) {
+	if depth > 40 {
+		return k, nil
+	}
+	if k == "." {
+		return k, f.entries[k]
+	}
+	parts := strings.Split(k, "/")
+	for i := range parts {
+		cur := strings.Join(parts[:i+1], "/")
+		e, ok := f.entries[cur]
+		if !ok {
+			return k, nil
+		}
+		last := i == len(parts)-1
+		if e.mode&fs.ModeSymlink == 0 || (last && !follow) {
+			continue
+		}
+		target, err := f.readBlob(ctx, e.oid)
+		if err != nil {
+			return k, nil
+		}
+		next := string(target)
+		if !stdpath.IsAbs(next) {
+			next = stdpath.Join(stdpath.Dir(cur), next)
+		}
+		if rest := parts[i+1:]; len(rest) > 0 {
+			next = stdpath.Join(next, strings.Join(rest, "/"))
+		}
+		next = stdpath.Clean("/" + next)[1:]
+		if next == "" {
+			next = "."
+		}
+		return f.resolve(ctx, next, follow, depth+1)
+	}
+	return k, f.entries[k]
+}
+
+// readBlob returns the contents of a blob.
+func (f *gitFS) readBlob(ctx context.Context, oid string) ([]byte, error) {
+	return f.git(ctx, "cat-file", "blob", oid)
+}
+
+func (f *gitFS) info(e *entry) *fileInfo {
+	return &fileInfo{e, f.modTime}
+}
+
+var _ fs.FS = (*gitFS)(nil)
+
+func (f *gitFS) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	_, e, err := f.lookup(ctx, "open", name, true)
+	if err != nil {
+		return nil, err
+	}
+	if e.mode.IsDir() {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrIsDir}
+	}
+	cmd := exec.CommandContext(ctx, "git", "-C", f.repo,
+		"cat-file", "blob", e.oid)
+	r, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	br := &blobReader{r: r, cmd: cmd, name: name}
+	cmd.Stderr = &br.stderr
+	if err := cmd.Start(); err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	return br, nil
+}
+
+// blobReader streams a blob from git cat-file. At the end of the blob it
+// waits for git to exit, so that a failed or interrupted git is reported
+// rather than read as a short blob.
+type blobReader struct {
+	r      io.Reader
+	cmd    *exec.Cmd
+	stderr bytes.Buffer
+	name   string
+	done   bool
+	err    error // git's failure, valid once done
+}
+
+func (r *blobReader) Read(p []byte) (int, error) {
+	if r.done {
+		if r.err != nil {
+			return 0, r.err
+		}
+		return 0, io.EOF
+	}
+	n, err := r.r.Read(p)
+	if err == io.EOF {
+		r.done = true
+		if r.err = r.wait(); r.err != nil {
+			return n, r.err
+		}
+	}
+	return n, err
+}
+
+// wait waits for git to exit and returns an error if it failed.
+func (r *blobReader) wait() error {
+	if err := r.cmd.Wait(); err != nil {
+		msg := strings.TrimSpace(r.stderr.String())
+		return &fs.PathError{
+			Op:   "read",
+			Path: r.name,
+			Err:  fmt.Errorf("gitfs: git cat-file: %s: %w", msg, err),
+		}
+	}
+	return nil
+}
+
+func (r *blobReader) Close() error {
+	if r.done {
+		return r.err
+	}
+	r.done = true
+	// Closed before the end of the blob: git's exit status no longer
+	// matters.
+	_ = r.cmd.Process.Kill()
+	_ = r.cmd.Wait()
+	return nil
+}
+
+var _ fs.StatFS = (*gitFS)(nil)
+
+func (f *gitFS) Stat(ctx context.Context, name string) (fs.FileInfo, error) {
+	_, e, err := f.lookup(ctx, "stat", name, true)
+	if err != nil {
+		return nil, err
+	}
+	return f.info(e), nil
+}
+
+var _ fs.ReadLinkFS = (*gitFS)(nil)
+
+func (f *gitFS) Lstat(ctx context.Context, name string) (fs.FileInfo, error) {
+	_, e, err := f.lookup(ctx, "lstat", name, false)
+	if err != nil {
+		return nil, err
+	}
+	return f.info(e), nil
+}
+
+func (f *gitFS) ReadLink(ctx context.Context, name string) (string, error) {
+	_, e, err := f.lookup(ctx, "readlink", name, false)
+	if err != nil {
+		return "", err
+	}
+	if e.mode&fs.ModeSymlink == 0 {
+		return "", &fs.PathError{Op: "readlink", Path: name, Err: fs.ErrInvalid}
+	}
+	target, err := f.readBlob(ctx, e.oid)
+	if err != nil {
+		return "", &fs.PathError{Op: "readlink", Path: name, Err: err}
+	}
+	return string(target), nil
+}
+
+var _ fs.ReadDirFS = (*gitFS)(nil)
+
+func (f *gitFS) ReadDir(
+	ctx context.Context, name string,
+) iter.Seq2[fs.DirEntry, error] {
+	return func(yield func(fs.DirEntry, error) bool) {
+		k, e, err := f.lookup(ctx, "readdir", name, true)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		if !e.mode.IsDir() {
+			yield(nil, &fs.PathError{
+				Op:   "readdir",
+				Path: name,
+				Err:  fs.ErrNotDir,
+			})
+			return
+		}
+		for _, child := range e.children {
+			c := f.entries[stdpath.Join(k, child)]
+			if !yield(&dirEntry{f.info(c), ""}, nil) {
+				return
+			}
+		}
+	}
+}
+
+var _ fs.WalkFS = (*gitFS)(nil)
+
+func (f *gitFS) Walk(
+	ctx context.Context, root string, depth int,
+) iter.Seq2[fs.DirEntry, error] {
+	return func(yield func(fs.DirEntry, error) bool) {
+		k, e, err := f.lookup(ctx, "walk", root, true)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		if !e.mode.IsDir() {
+			yield(nil, &fs.PathError{Op: "walk", Path: root, Err: fs.ErrNotDir})
+			return
+		}
+
+		type item struct {
+			key, path string
+			depth     int
+		}
+		queue := []item{{k, root, 0}}
+		for len(queue) > 0 {
+			cur := queue[0]
+			queue = queue[1:]
+			for _, child := range f.entries[cur.key].children {
+				ck := stdpath.Join(cur.key, child)
+				c := f.entries[ck]
+				p := path.Join(cur.path, child)
+				if !yield(&dirEntry{f.info(c), p}, nil) {
+					return
+				}
+				next := cur.depth + 1
+				if c.mode.IsDir() && (depth <= 0 || next < depth) {
+					queue = append(queue, item{ck, p, next})
+				}
+			}
+		}
+	}
+}
+
+var _ fs.DirFS = (*gitFS)(nil)
+
+// OpenDir streams the directory as a tar archive, reading every blob
+// through a single git cat-file --batch process. The archive is compressed
+// under fs.WithCompression.
+func (f *gitFS) OpenDir(
+	ctx context.Context, dir string,
+) (io.ReadCloser, error) {
+	k, e, err := f.lookup(ctx, "opendir", dir, true)
+	if err != nil {
+		return nil, err
+	}
+	if !e.mode.IsDir() {
+		return nil, &fs.PathError{Op: "opendir", Path: dir, Err: fs.ErrNotDir}
+	}
+
+	cmd := exec.CommandContext(ctx, "git", "-C", f.repo,
+		"cat-file", "--batch")
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, &fs.PathError{Op: "opendir", Path: dir, Err: err}
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, &fs.PathError{Op: "opendir", Path: dir, Err: err}
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, &fs.PathError{Op: "opendir", Path: dir, Err: err}
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		b := &batch{stdin, bufio.NewReader(stdout)}
+		var err error
+		if fs.TarCompression(ctx) == fs.CompressionGzip {
+			gz := gzip.NewWriter(pw)
+			err = f.writeTar(gz, b, k)
+			if cerr := gz.Close(); err == nil {
+				err = cerr
+			}
+		} else {
+			err = f.writeTar(pw, b, k)
+		}
+		_ = stdin.Close()
+		_ = cmd.Wait()
+		pw.CloseWithError(err)
+	}()
+	return pr, nil
+}
+
+// writeTar writes the contents of the directory k to w as a tar stream,
+// with member names relative to the directory.
+func (f *gitFS) writeTar(w io.Writer, b *batch, k string) error {
+	tw := tar.NewWriter(w)
+	var walk func(k, prefix string) error
+	walk = func(k, prefix string) error {
+		for _, child := range f.entries[k].children {
+			ck, name := stdpath.Join(k, child), prefix+child
+			c := f.entries[ck]
+			hdr := &tar.Header{
+				Name:    name,
+				Mode:    int64(c.mode.Perm()),
+				ModTime: f.modTime,
+			}
+			var data []byte
+			switch {
+			case c.mode.IsDir():
+				hdr.Typeflag = tar.TypeDir
+				hdr.Name += "/"
+			case c.mode&fs.ModeSymlink != 0:
+				target, err := b.read(c.oid)
+				if err != nil {
+					return err
+				}
+				hdr.Typeflag = tar.TypeSymlink
+				hdr.Linkname = string(target)
+			default:
+				var err error
+				if data, err = b.read(c.oid); err != nil {
+					return err
+				}
+				hdr.Typeflag = tar.TypeReg
+				hdr.Size = int64(len(data))
+			}
+			if err := tw.WriteHeader(hdr); err != nil {
+				return err
+			}
+			if _, err := tw.Write(data); err != nil {
+				return err
+			}
+			if c.mode.IsDir() {
+				if err := walk(ck, name+"/"); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	if err := walk(k, ""); err != nil {
+		return err
+	}
+	return tw.Close()
+}
+
+// batch reads objects from a running git cat-file --batch.
+type batch struct {
+	w io.Writer
+	r *bufio.Reader
+}
+
+// read returns the contents of the object oid.
+func (b *batch) read(oid string) ([]byte, error) {
+	if _, err := io.WriteString(b.w, oid+"\n"); err != nil {
+		return nil, err
+	}
+	line, err := b.r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	// "<oid> <type> <size>" or "<oid> missing".
+	fields := strings.Fields(line)
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("gitfs: cat-file: %s", strings.TrimSpace(line))
+	}
+	size, err := strconv.ParseInt(fields[2], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("gitfs: cat-file: %s", strings.TrimSpace(line))
+	}
+	data := make([]byte, size+1) // Contents are followed by a newline.
+	if _, err := io.ReadFull(b.r, data); err != nil {
+		return nil, err
+	}
+	return data[:size], nil
+}
+
+// fileInfo implements fs.FileInfo for a tree entry.
+type fileInfo struct {
+	e       *entry
+	modTime time.Time
+}
+
+func (fi *fileInfo) Name() string       { return fi.e.name }
+func (fi *fileInfo) Size() int64        { return fi.e.size }
+func (fi *fileInfo) Mode() fs.Mode      { return fi.e.mode }
+func (fi *fileInfo) ModTime() time.Time { return fi.modTime }
+func (fi *fileInfo) IsDir() bool        { return fi.e.mode.IsDir() }
+func (fi *fileInfo) Sys() any           { return nil }
+
+// dirEntry implements fs.DirEntry for a tree entry.
+type dirEntry struct {
+	info *fileInfo
+	path string
+}
+
+func (de *dirEntry) Name() string               { return de.info.Name() }
+func (de *dirEntry) IsDir() bool                { return de.info.IsDir() }
+func (de *dirEntry) Type() fs.Mode              { return de.info.Mode().Type() }
+func (de *dirEntry) Info() (fs.FileInfo, error) { return de.info, nil }
+func (de *dirEntry) Path() string               { return de.path }
@@ -0,0 +1,223 @@
+package gitfs
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"lesiw.io/fs"
+	"lesiw.io/fs/fstest"
+)
+
+// testFiles is the tree committed at the tagged revision of the fixture.
+var testFiles = []fstest.File{
+	{Path: "a/b/c/deep.txt", Data: []byte("deep")},
+	{Path: "a/b/file.txt", Data: []byte("ab")},
+	{Path: "a/file.txt", Data: []byte("a")},
+	{Path: "dir/nested.txt", Data: []byte("nested")},
+	{Path: "dir/subdir/file.txt", Data: []byte("content")},
+	{Path: "empty/.keep", Data: []byte("")},
+	{Path: "file1.txt", Data: []byte("one")},
+	{Path: "file2.txt", Data: []byte("two")},
+	{Path: "file3.json", Data: []byte("json")},
+	{Path: "x/file.txt", Data: []byte("x")},
+	{Path: "x/y/file.txt", Data: []byte("xy")},
+	{Path: "x/y/z/file.txt", Data: []byte("xyz")},
+}
+
+// fixture creates a repository with testFiles committed and tagged v1,
+// followed by a second commit that changes file1.txt and adds a symbolic
+// link named link.
+func fixture(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+			"GIT_CONFIG_GLOBAL=/dev/null", "GIT_CONFIG_NOSYSTEM=1",
+		)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	write := func(name, data string) {
+		t.Helper()
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	git("init", "-q")
+	for _, f := range testFiles {
+		write(f.Path, string(f.Data))
+	}
+	git("add", ".")
+	git("commit", "-q", "-m", "v1")
+	git("tag", "v1")
+	write("file1.txt", "changed")
+	if err := os.Symlink("file2.txt", filepath.Join(dir, "link")); err != nil {
+		t.Fatal(err)
+	}
+	git("add", ".")
+	git("commit", "-q", "-m", "v2")
+	return dir
+}
+
+func TestFS(t *testing.T) {
+	fsys, err := New(fixture(t), "v1")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	fstest.TestFS(t.Context(), t, fsys, fstest.WithFiles(testFiles...))
+}
+
+func TestRevision(t *testing.T) {
+	repo, ctx := fixture(t), t.Context()
+
+	for rev, want := range map[string]string{"v1": "one", "HEAD": "changed"} {
+		fsys, err := New(repo, rev)
+		if err != nil {
+			t.Fatalf("New(%q) error = %v", rev, err)
+		}
+		data, err := fs.ReadFile(ctx, fsys, "file1.txt")
+		if err != nil {
+			t.Fatalf("ReadFile(%q) at %s error = %v", "file1.txt", rev, err)
+		}
+		if got := string(data); got != want {
+			t.Errorf("ReadFile(%q) at %s = %q, want %q",
+				"file1.txt", rev, got, want)
+		}
+	}
+
+	if _, err := New(repo, "no-such-rev"); err == nil {
+		t.Error("New(no-such-rev) error = nil, want error")
+	}
+}
+
+func TestReadDir(t *testing.T) {
+	fsys, err := New(fixture(t), "v1")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	ctx := t.Context()
+
+	var got []string
+	for e, err := range fs.ReadDir(ctx, fsys, "a") {
+		if err != nil {
+			t.Fatalf("ReadDir(%q) error = %v", "a", err)
+		}
+		got = append(got, e.Name())
+	}
+	if want := []string{"b", "file.txt"}; !slices.Equal(got, want) {
+		t.Errorf("ReadDir(%q) = %q, want %q", "a", got, want)
+	}
+	if _, err := fsys.Open(ctx, "a"); !errors.Is(err, fs.ErrIsDir) {
+		t.Errorf("Open(%q) error = %v, want ErrIsDir", "a", err)
+	}
+}
+
+func TestSymlink(t *testing.T) {
+	fsys, err := New(fixture(t), "HEAD")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	ctx := t.Context()
+
+	target, err := fs.ReadLink(ctx, fsys, "link")
+	if err != nil {
+		t.Fatalf("ReadLink() error = %v", err)
+	}
+	if want := "file2.txt"; target != want {
+		t.Errorf("ReadLink() = %q, want %q", target, want)
+	}
+	info, err := fs.Lstat(ctx, fsys, "link")
+	if err != nil {
+		t.Fatalf("Lstat() error = %v", err)
+	}
+	if info.Mode()&fs.ModeSymlink == 0 {
+		t.Errorf("Lstat().Mode() = %v, want symlink", info.Mode())
+	}
+	data, err := fs.ReadFile(ctx, fsys, "link")
+	if err != nil {
+		t.Fatalf("ReadFile(link) error = %v", err)
+	}
+	if got, want := string(data), "two"; got != want {
+		t.Errorf("ReadFile(link) = %q, want %q", got, want)
+	}
+}
+
+func TestOpenDirCompression(t *testing.T) {
+	fsys, err := New(fixture(t), "v1")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	ctx := fs.WithCompression(t.Context(), fs.CompressionGzip)
+
+	r, err := fs.Open(ctx, fsys, "a/")
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer r.Close()
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		t.Fatalf("gzip.NewReader() error = %v", err)
+	}
+	var got []string
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+		got = append(got, hdr.Name)
+	}
+	if !slices.Contains(got, "b/c/deep.txt") {
+		t.Errorf("tar members = %q, want b/c/deep.txt among them", got)
+	}
+}
+
+func TestOpenGitFailure(t *testing.T) {
+	fsys, err := New(fixture(t), "v1")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	ctx := t.Context()
+
+	// Point the entry at a missing object, so that git cat-file fails.
+	fsys.(*gitFS).entries["file1.txt"].oid = strings.Repeat("0", 40)
+	data, err := fs.ReadFile(ctx, fsys, "file1.txt")
+	if err == nil {
+		t.Errorf("ReadFile() = %q, nil, want git error", data)
+	}
+
+	r, err := fs.Open(ctx, fsys, "file2.txt")
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if _, err := r.Read(make([]byte, 1)); err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if err := r.Close(); err != nil {
+		t.Errorf("Close() before EOF error = %v", err)
+	}
+}
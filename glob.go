@@ -3,6 +3,8 @@ package fs
 import (
 	"context"
 	"errors"
+	"slices"
+	"strings"
 
 	"lesiw.io/fs/path"
 )
@@ -23,7 +25,15 @@ type GlobFS interface {
 // Analogous to: [io/fs.Glob], [path.Match], glob, find, 9P walk.
 //
 // The pattern syntax is the same as in [path.Match]. The pattern may
-// describe hierarchical names such as usr/*/bin/ed.
+// describe hierarchical names such as usr/*/bin/ed, with metacharacters in
+// any segment. As with [io/fs.Glob], matches are full paths in the form of
+// the pattern, so a/*/c.txt matches a/b/c.txt, and the fallback returns them
+// in lexicographical order.
+//
+// The fallback also accepts ** as a whole segment, matching zero or more
+// directories as in [path.MatchRecursive]: logs/**/*.txt matches text files
+// at any depth beneath logs, and dir/** matches every descendant of dir.
+// Subtrees under a ** are listed without following symbolic links.
 //
 // Glob ignores file system errors such as I/O errors reading directories.
 // The only possible returned error is [path.ErrBadPattern], reporting that
@@ -56,11 +66,62 @@ func Glob(ctx context.Context, fsys FS, pattern string) ([]string, error) {
 		}
 	}
 
-	return globWithLimit(ctx, fsys, pattern, 0)
+	return globWithLimit(ctx, fsys, pattern, 0, make(dirCache))
+}
+
+// GlobRel returns the names of all files matching pattern within the
+// directory base, relative to base.
+// Analogous to: cd base && glob pattern.
+//
+// GlobRel globs the pattern path.Join(base, pattern) and strips base from
+// each match with [path.Rel], so base/src/*.go yields names like
+// src/main.go. Metacharacters in base are not escaped.
+//
+// Requires: See [Glob] requirements
+func GlobRel(
+	ctx context.Context, fsys FS, base, pattern string,
+) ([]string, error) {
+	matches, err := Glob(ctx, fsys, path.Join(base, pattern))
+	if err != nil {
+		return nil, err
+	}
+	for i, m := range matches {
+		if matches[i], err = path.Rel(base, m); err != nil {
+			return nil, &PathError{Op: "glob", Path: pattern, Err: err}
+		}
+	}
+	return matches, nil
+}
+
+// dirCache holds the entries of each directory listed during a single Glob
+// call, sorted by name, so that no directory is read twice however many
+// branches of the pattern reach it. The cache is discarded when Glob
+// returns, so its results are never stale across calls.
+type dirCache map[string][]DirEntry
+
+// readDir returns the sorted entries of dir, reading it on first use.
+// I/O errors are ignored, leaving dir empty.
+func (c dirCache) readDir(ctx context.Context, fsys FS, dir string) []DirEntry {
+	entries, ok := c[dir]
+	if ok {
+		return entries
+	}
+	for e, err := range ReadDir(ctx, fsys, dir) {
+		if err != nil {
+			entries = nil // ignore I/O error
+			break
+		}
+		entries = append(entries, e)
+	}
+	slices.SortFunc(entries, func(a, b DirEntry) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+	c[dir] = entries
+	return entries
 }
 
 func globWithLimit(
-	ctx context.Context, fsys FS, pattern string, depth int,
+	ctx context.Context, fsys FS, pattern string, depth int, cache dirCache,
 ) (matches []string, err error) {
 	// This limit is added to prevent stack exhaustion issues.
 	// See CVE-2022-30630.
@@ -73,6 +134,9 @@ func globWithLimit(
 	if _, err := path.Match(pattern, ""); err != nil {
 		return nil, err
 	}
+	if prefix, rest, ok := cutDoubleStar(pattern); ok {
+		return globRecursive(ctx, fsys, prefix, rest, depth, cache)
+	}
 	if !hasMeta(pattern) {
 		if _, err = Stat(ctx, fsys, pattern); err != nil {
 			return nil, nil
@@ -87,7 +151,7 @@ func globWithLimit(
 	}
 
 	if !hasMeta(dir) {
-		return glob(ctx, fsys, dir, file, nil)
+		return glob(ctx, fsys, dir, file, nil, cache)
 	}
 
 	// Prevent infinite recursion. See issue 15879.
@@ -96,12 +160,12 @@ func globWithLimit(
 	}
 
 	var m []string
-	m, err = globWithLimit(ctx, fsys, dir, depth+1)
+	m, err = globWithLimit(ctx, fsys, dir, depth+1, cache)
 	if err != nil {
 		return nil, err
 	}
 	for _, d := range m {
-		matches, err = glob(ctx, fsys, d, file, matches)
+		matches, err = glob(ctx, fsys, d, file, matches, cache)
 		if err != nil {
 			return
 		}
@@ -115,26 +179,114 @@ func globWithLimit(
 // New matches are added in lexicographical order.
 func glob(
 	ctx context.Context, fsys FS, dir, pattern string, matches []string,
+	cache dirCache,
 ) (m []string, e error) {
 	m = matches
 
-	// Read directory using ReadDir
-	for info, err := range ReadDir(ctx, fsys, dir) {
-		if err != nil {
-			return m, nil // ignore I/O error
-		}
-		n := info.Name()
+	for _, e := range cache.readDir(ctx, fsys, dir) {
+		n := e.Name()
 		matched, matchErr := path.Match(pattern, n)
 		if matchErr != nil {
 			return m, matchErr
 		}
-		if matched {
-			m = append(m, path.Join(dir, n))
+		if !matched {
+			continue
 		}
+		m = append(m, globJoin(dir, n))
 	}
 	return
 }
 
+// cutDoubleStar splits pattern around its first ** segment, reporting
+// whether it has one.
+func cutDoubleStar(pattern string) (prefix, rest string, ok bool) {
+	segs := strings.Split(pattern, "/")
+	i := slices.Index(segs, "**")
+	if i < 0 {
+		return "", "", false
+	}
+	return strings.Join(segs[:i], "/"), strings.Join(segs[i+1:], "/"), true
+}
+
+// globRecursive returns the descendants of the directories matching prefix
+// whose paths below that directory match **/rest, as by
+// [path.MatchRecursive]. An empty rest matches every descendant.
+// Descendants are found by listing each subdirectory once, without
+// following symbolic links.
+func globRecursive(
+	ctx context.Context, fsys FS, prefix, rest string, depth int,
+	cache dirCache,
+) ([]string, error) {
+	var bases []string
+	switch {
+	case prefix == "":
+		bases = []string{"."}
+	case hasMeta(prefix):
+		var err error
+		bases, err = globWithLimit(ctx, fsys, prefix, depth+1, cache)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		if _, err := Stat(ctx, fsys, prefix); err != nil {
+			return nil, nil
+		}
+		bases = []string{prefix}
+	}
+
+	// Below each base, the ** may span any number of directories.
+	pattern := "**"
+	if rest != "" {
+		pattern += "/" + rest
+	}
+	var matches []string
+	for _, base := range bases {
+		var found []string
+		stack := []string{""}
+		for len(stack) > 0 {
+			rel := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			dir := base
+			if rel != "" {
+				dir = globJoin(base, rel)
+			}
+			for _, e := range cache.readDir(ctx, fsys, dir) {
+				r := e.Name()
+				if rel != "" {
+					r = rel + "/" + r
+				}
+				matched, err := path.MatchRecursive(pattern, r)
+				if err != nil {
+					return nil, err
+				}
+				if matched {
+					found = append(found, globJoin(base, r))
+				}
+				if e.IsDir() {
+					stack = append(stack, r)
+				}
+			}
+		}
+		slices.Sort(found)
+		matches = append(matches, found...)
+	}
+	return matches, nil
+}
+
+// globJoin joins dir and name in the form of the pattern that produced dir.
+// Like io/fs.Glob, "a/*" matches "a/b" rather than "./a/b", and matches in
+// the current directory are bare names.
+func globJoin(dir, name string) string {
+	if dir == "." {
+		return name
+	}
+	p := path.Join(dir, name)
+	if !strings.HasPrefix(dir, "./") {
+		p = strings.TrimPrefix(p, "./")
+	}
+	return p
+}
+
 // hasMeta reports whether path contains any of the magic characters
 // recognized by path.Match.
 func hasMeta(p string) bool {
@@ -3,9 +3,15 @@ package fs_test
 import (
 	"context"
 	"fmt"
+	iofs "io/fs"
+	"iter"
 	"log"
+	"slices"
+	"testing"
+	stdfstest "testing/fstest"
 
 	"lesiw.io/fs"
+	"lesiw.io/fs/memfs"
 	"lesiw.io/fs/osfs"
 )
 
@@ -28,3 +34,205 @@ func ExampleGlob() {
 	// Output:
 	// Found 2 .txt files
 }
+
+func TestGlobMultiSegment(t *testing.T) {
+	ctx, fsys := context.Background(), memfs.New()
+	files := []string{
+		"main.go",
+		"cmd/main.go",
+		"cmd/doc.txt",
+		"pkg/util.go",
+		"a/x/b/one.txt",
+		"a/y/b/two.txt",
+		"a/y/b/skip.go",
+		"a/y/c/three.txt",
+		"a/bin/c.txt",
+		"a/bar/c.txt",
+		"a/foo/c.txt",
+	}
+	ref := stdfstest.MapFS{}
+	for _, name := range files {
+		if err := fs.WriteFile(ctx, fsys, name, nil); err != nil {
+			t.Fatalf("WriteFile(%q) error = %v", name, err)
+		}
+		ref[name] = &stdfstest.MapFile{}
+	}
+
+	tests := []struct {
+		pattern string
+		want    []string
+	}{
+		{"*/*.go", []string{"cmd/main.go", "pkg/util.go"}},
+		{"a/*/b/*.txt", []string{"a/x/b/one.txt", "a/y/b/two.txt"}},
+		{"a/b*/c.txt", []string{"a/bar/c.txt", "a/bin/c.txt"}},
+		{"*/*/*/*.txt", []string{
+			"a/x/b/one.txt", "a/y/b/two.txt", "a/y/c/three.txt",
+		}},
+		{"a/[xy]/?/*", []string{
+			"a/x/b/one.txt", "a/y/b/skip.go", "a/y/b/two.txt",
+			"a/y/c/three.txt",
+		}},
+	}
+	for _, tt := range tests {
+		got, err := fs.Glob(ctx, fsys, tt.pattern)
+		if err != nil {
+			t.Fatalf("Glob(%q) error = %v", tt.pattern, err)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("Glob(%q) = %q, want %q", tt.pattern, got, tt.want)
+		}
+		std, err := iofs.Glob(ref, tt.pattern)
+		if err != nil {
+			t.Fatalf("io/fs.Glob(%q) error = %v", tt.pattern, err)
+		}
+		if !slices.Equal(got, std) {
+			t.Errorf("Glob(%q) = %q, io/fs.Glob = %q", tt.pattern, got, std)
+		}
+	}
+}
+
+// readCountFS counts how often each directory is read.
+type readCountFS struct {
+	fs.FS
+	reads map[string]int
+}
+
+func (f *readCountFS) ReadDir(
+	ctx context.Context, name string,
+) iter.Seq2[fs.DirEntry, error] {
+	f.reads[name]++
+	return fs.ReadDir(ctx, f.FS, name)
+}
+
+func (f *readCountFS) Stat(
+	ctx context.Context, name string,
+) (fs.FileInfo, error) {
+	return fs.Stat(ctx, f.FS, name)
+}
+
+func TestGlobReadsEachDirOnce(t *testing.T) {
+	ctx, mem := context.Background(), memfs.New()
+	for _, name := range []string{
+		"a/x/1.txt", "a/y/2.txt", "b/x/3.txt", "b/x/4.txt",
+	} {
+		if err := fs.WriteFile(ctx, mem, name, nil); err != nil {
+			t.Fatalf("WriteFile(%q) error = %v", name, err)
+		}
+	}
+	fsys := &readCountFS{FS: mem, reads: make(map[string]int)}
+
+	// Every branch of the pattern passes through the same directories.
+	if _, err := fs.Glob(ctx, fsys, "[ab]/[xy]/[1-4].txt"); err != nil {
+		t.Fatalf("Glob() error = %v", err)
+	}
+	for dir, n := range fsys.reads {
+		if n != 1 {
+			t.Errorf("ReadDir(%q) called %d times, want 1", dir, n)
+		}
+	}
+	if got, want := len(fsys.reads), 6; got != want {
+		t.Errorf("directories read = %d, want %d", got, want)
+	}
+
+	// The cache does not outlive a call.
+	if _, err := fs.Glob(ctx, fsys, "*"); err != nil {
+		t.Fatalf("Glob() error = %v", err)
+	}
+	if got, want := fsys.reads["."], 2; got != want {
+		t.Errorf("ReadDir(.) called %d times, want %d", got, want)
+	}
+}
+
+func TestGlobRel(t *testing.T) {
+	ctx, fsys := context.Background(), memfs.New()
+	for _, name := range []string{
+		"src/main.go", "src/util.go", "src/doc.txt", "src/sub/x.go",
+		"other.go",
+	} {
+		if err := fs.WriteFile(ctx, fsys, name, nil); err != nil {
+			t.Fatalf("WriteFile(%q) error = %v", name, err)
+		}
+	}
+
+	tests := []struct {
+		base, pattern string
+		want          []string
+	}{
+		{"src", "*.go", []string{"main.go", "util.go"}},
+		{"./src/", "*.go", []string{"main.go", "util.go"}},
+		{"src", "*/*.go", []string{"sub/x.go"}},
+		{".", "*.go", []string{"other.go"}},
+		{"src", "*.md", nil},
+	}
+	for _, tt := range tests {
+		got, err := fs.GlobRel(ctx, fsys, tt.base, tt.pattern)
+		if err != nil {
+			t.Fatalf("GlobRel(%q, %q) error = %v", tt.base, tt.pattern, err)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf(
+				"GlobRel(%q, %q) = %q, want %q",
+				tt.base, tt.pattern, got, tt.want,
+			)
+		}
+	}
+}
+
+func TestGlobDoubleStar(t *testing.T) {
+	ctx, mem := context.Background(), memfs.New()
+	for _, name := range []string{
+		"logs/a.txt",
+		"logs/x/b.txt",
+		"logs/x/y/c.txt",
+		"logs/x/y/skip.go",
+		"a/b",
+		"a/x/b",
+		"a/x/y/b",
+		"a/x/c",
+		"top.txt",
+	} {
+		if err := fs.WriteFile(ctx, mem, name, nil); err != nil {
+			t.Fatalf("WriteFile(%q) error = %v", name, err)
+		}
+	}
+	fsys := &readCountFS{FS: mem, reads: make(map[string]int)}
+
+	tests := []struct {
+		pattern string
+		want    []string
+	}{
+		{"logs/**/*.txt", []string{
+			"logs/a.txt", "logs/x/b.txt", "logs/x/y/c.txt",
+		}},
+		{"a/**/b", []string{"a/b", "a/x/b", "a/x/y/b"}},
+		{"logs/x/**", []string{
+			"logs/x/b.txt", "logs/x/y", "logs/x/y/c.txt",
+			"logs/x/y/skip.go",
+		}},
+		{"**/*.go", []string{"logs/x/y/skip.go"}},
+		{"*/**/c*", []string{"a/x/c", "logs/x/y/c.txt"}},
+		{"missing/**", nil},
+	}
+	for _, tt := range tests {
+		clear(fsys.reads)
+		got, err := fs.Glob(ctx, fsys, tt.pattern)
+		if err != nil {
+			t.Fatalf("Glob(%q) error = %v", tt.pattern, err)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("Glob(%q) = %q, want %q", tt.pattern, got, tt.want)
+		}
+		for dir, n := range fsys.reads {
+			if n != 1 {
+				t.Errorf(
+					"Glob(%q): ReadDir(%q) called %d times, want 1",
+					tt.pattern, dir, n,
+				)
+			}
+		}
+	}
+
+	if _, err := fs.Glob(ctx, fsys, "a/**/["); err == nil {
+		t.Errorf("Glob(%q) error = nil, want ErrBadPattern", "a/**/[")
+	}
+}
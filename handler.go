@@ -0,0 +1,163 @@
+package fs
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	stdpath "path"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Handler returns an [http.Handler] that serves GET and HEAD requests from
+// fsys, mapping the URL path to a path in fsys.
+// Analogous to: [http.FileServer], [http.FS].
+//
+// Files are served with Content-Type from [DetectContentType], along with
+// Last-Modified and, when the FileInfo has an ETag() string method, ETag.
+// If the reader returned by [Open] implements [io.Seeker] (see [CanSeek]),
+// Range and conditional requests are handled by [http.ServeContent];
+// otherwise the whole file is sent.
+//
+// A request for a directory returns a tar stream of its contents if the
+// Accept header includes application/x-tar, and an HTML listing otherwise.
+// Directory listings are served at a URL ending in a slash; requests without
+// one are redirected.
+//
+// Paths that do not exist are answered with 404 Not Found. Other methods are
+// answered with 405 Method Not Allowed.
+//
+// Requires: [StatFS]
+func Handler(fsys FS) http.Handler {
+	return &handler{fsys}
+}
+
+type handler struct{ fsys FS }
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx := r.Context()
+	name := strings.TrimPrefix(stdpath.Clean("/"+r.URL.Path), "/")
+	if name == "" {
+		name = "."
+	}
+
+	info, err := Stat(ctx, h.fsys, name)
+	if err != nil {
+		serveError(w, err)
+		return
+	}
+	if info.IsDir() {
+		h.serveDir(w, r, name)
+		return
+	}
+
+	ct, err := DetectContentType(ctx, h.fsys, name)
+	if err != nil {
+		serveError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", ct)
+	if et := etag(info); et != "" {
+		w.Header().Set("ETag", strconv.Quote(et))
+	}
+
+	f, err := Open(ctx, h.fsys, name)
+	if err != nil {
+		serveError(w, err)
+		return
+	}
+	defer f.Close()
+
+	if rs, ok := f.(io.ReadSeeker); ok {
+		http.ServeContent(w, r, info.Name(), info.ModTime(), rs)
+		return
+	}
+	if !info.ModTime().IsZero() {
+		w.Header().Set("Last-Modified",
+			info.ModTime().UTC().Format(http.TimeFormat))
+	}
+	w.Header().Set("Content-Length", strconv.FormatInt(info.Size(), 10))
+	if r.Method == http.MethodHead {
+		return
+	}
+	_, _ = io.Copy(w, f)
+}
+
+func (h *handler) serveDir(
+	w http.ResponseWriter, r *http.Request, name string,
+) {
+	ctx := r.Context()
+
+	if strings.Contains(r.Header.Get("Accept"), "application/x-tar") {
+		f, err := Open(ctx, h.fsys, name+"/")
+		if err != nil {
+			serveError(w, err)
+			return
+		}
+		defer f.Close()
+		w.Header().Set("Content-Type", "application/x-tar")
+		if r.Method == http.MethodHead {
+			return
+		}
+		_, _ = io.Copy(w, f)
+		return
+	}
+
+	if !strings.HasSuffix(r.URL.Path, "/") {
+		u := *r.URL
+		u.Path += "/"
+		http.Redirect(w, r, u.String(), http.StatusMovedPermanently)
+		return
+	}
+
+	var names []string
+	for e, err := range ReadDir(ctx, h.fsys, name) {
+		if err != nil {
+			serveError(w, err)
+			return
+		}
+		n := e.Name()
+		if e.IsDir() {
+			n += "/"
+		}
+		names = append(names, n)
+	}
+	slices.Sort(names)
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if r.Method == http.MethodHead {
+		return
+	}
+	fmt.Fprintln(w, "<!doctype html>")
+	fmt.Fprintln(w, `<meta name="viewport" content="width=device-width">`)
+	fmt.Fprintln(w, "<pre>")
+	for _, n := range names {
+		href := (&url.URL{Path: n}).String()
+		fmt.Fprintf(w, "<a href=\"%s\">%s</a>\n",
+			html.EscapeString(href), html.EscapeString(n))
+	}
+	fmt.Fprintln(w, "</pre>")
+}
+
+// serveError answers a request with the status that best matches err.
+func serveError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrNotExist):
+		http.Error(w, "404 page not found", http.StatusNotFound)
+	case errors.Is(err, ErrPermission):
+		http.Error(w, "403 Forbidden", http.StatusForbidden)
+	default:
+		http.Error(w, "500 Internal Server Error",
+			http.StatusInternalServerError)
+	}
+}
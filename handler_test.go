@@ -0,0 +1,110 @@
+package fs_test
+
+import (
+	"archive/tar"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"lesiw.io/fs"
+	"lesiw.io/fs/memfs"
+)
+
+func TestHandler(t *testing.T) {
+	ctx, fsys := context.Background(), memfs.New()
+	err := fs.WriteFile(ctx, fsys, "dir/file.txt", []byte("0123456789"))
+	if err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	server := httptest.NewServer(fs.Handler(fsys))
+	defer server.Close()
+
+	get := func(
+		path string, header map[string]string,
+	) (*http.Response, string) {
+		t.Helper()
+		req, err := http.NewRequest(http.MethodGet, server.URL+path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("GET %s error = %v", path, err)
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("GET %s read error = %v", path, err)
+		}
+		return resp, string(body)
+	}
+
+	resp, body := get("/dir/file.txt", nil)
+	if got, want := resp.StatusCode, http.StatusOK; got != want {
+		t.Errorf("GET status = %d, want %d", got, want)
+	}
+	if got, want := body, "0123456789"; got != want {
+		t.Errorf("GET body = %q, want %q", got, want)
+	}
+	ct := resp.Header.Get("Content-Type")
+	if !strings.HasPrefix(ct, "text/plain") {
+		t.Errorf("Content-Type = %q, want text/plain", ct)
+	}
+	if resp.Header.Get("Last-Modified") == "" {
+		t.Error("Last-Modified not set")
+	}
+
+	resp, body = get("/dir/file.txt", map[string]string{"Range": "bytes=2-5"})
+	if got, want := resp.StatusCode, http.StatusPartialContent; got != want {
+		t.Errorf("GET Range status = %d, want %d", got, want)
+	}
+	if got, want := body, "2345"; got != want {
+		t.Errorf("GET Range body = %q, want %q", got, want)
+	}
+
+	resp, _ = get("/missing.txt", nil)
+	if got, want := resp.StatusCode, http.StatusNotFound; got != want {
+		t.Errorf("GET missing status = %d, want %d", got, want)
+	}
+
+	resp, body = get("/dir", nil)
+	if got, want := resp.StatusCode, http.StatusOK; got != want {
+		t.Errorf("GET dir status = %d, want %d", got, want)
+	}
+	if !strings.Contains(body, `<a href="file.txt">file.txt</a>`) {
+		t.Errorf("GET dir body = %q, want link to file.txt", body)
+	}
+
+	resp, body = get("/dir/", map[string]string{"Accept": "application/x-tar"})
+	ct = resp.Header.Get("Content-Type")
+	if want := "application/x-tar"; ct != want {
+		t.Errorf("GET dir tar Content-Type = %q, want %q", ct, want)
+	}
+	hdr, err := tar.NewReader(strings.NewReader(body)).Next()
+	if err != nil {
+		t.Fatalf("tar Next() error = %v", err)
+	}
+	if got, want := hdr.Name, "file.txt"; got != want {
+		t.Errorf("tar member = %q, want %q", got, want)
+	}
+}
+
+func TestHandlerMethodNotAllowed(t *testing.T) {
+	server := httptest.NewServer(fs.Handler(memfs.New()))
+	defer server.Close()
+
+	resp, err := http.Post(server.URL+"/file.txt", "text/plain", nil)
+	if err != nil {
+		t.Fatalf("POST error = %v", err)
+	}
+	_ = resp.Body.Close()
+	if got, want := resp.StatusCode, http.StatusMethodNotAllowed; got != want {
+		t.Errorf("POST status = %d, want %d", got, want)
+	}
+}
@@ -0,0 +1,5 @@
+//go:build !windows
+
+package fs
+
+func sysHidden(e DirEntry) bool { return false }
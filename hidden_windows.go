@@ -0,0 +1,13 @@
+package fs
+
+import "syscall"
+
+// sysHidden reports whether e has the Windows hidden attribute.
+func sysHidden(e DirEntry) bool {
+	info, err := e.Info()
+	if err != nil || info == nil {
+		return false
+	}
+	attrs, ok := info.Sys().(*syscall.Win32FileAttributeData)
+	return ok && attrs.FileAttributes&syscall.FILE_ATTRIBUTE_HIDDEN != 0
+}
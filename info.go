@@ -0,0 +1,94 @@
+package fs
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// InfoFields is a set of [FileInfo] attributes to compare with
+// [InfoEqual] and [InfoDiff].
+type InfoFields uint
+
+// Attributes of a [FileInfo] that can be compared.
+const (
+	InfoSize    InfoFields = 1 << iota // Size
+	InfoModTime                        // ModTime
+	InfoMode                           // Mode
+	InfoETag                           // ETag, for infos with an ETag method
+
+	// InfoDefault holds the attributes compared by tools like rsync to
+	// decide whether a file needs to be copied.
+	InfoDefault = InfoSize | InfoModTime
+)
+
+// InfoEqual reports whether a and b agree on each attribute in fields.
+// Modification times must be equal; use [InfoDiff] to allow for backends
+// with coarse timestamps.
+func InfoEqual(a, b FileInfo, fields InfoFields) bool {
+	return InfoDiff(a, b, fields, 0) == 0
+}
+
+// InfoDiff returns the attributes in fields on which a and b differ.
+// Modification times are treated as equal if they are less than tolerance
+// apart, which accounts for backends that store them with second or
+// coarser precision.
+//
+// ETags are compared only when both infos have an ETag() string method
+// that returns a non-empty value, ignoring surrounding quotes. Otherwise,
+// InfoETag is reported as different, since the contents cannot be shown to
+// match.
+func InfoDiff(
+	a, b FileInfo, fields InfoFields, tolerance time.Duration,
+) InfoFields {
+	var diff InfoFields
+	if fields&InfoSize != 0 && a.Size() != b.Size() {
+		diff |= InfoSize
+	}
+	if fields&InfoModTime != 0 {
+		d := a.ModTime().Sub(b.ModTime()).Abs()
+		if d != 0 && d >= tolerance {
+			diff |= InfoModTime
+		}
+	}
+	if fields&InfoMode != 0 && a.Mode() != b.Mode() {
+		diff |= InfoMode
+	}
+	if fields&InfoETag != 0 {
+		ea, eb := etag(a), etag(b)
+		if ea == "" || ea != eb {
+			diff |= InfoETag
+		}
+	}
+	return diff
+}
+
+// etag returns the unquoted ETag of info, or "" if it has none.
+func etag(info FileInfo) string {
+	if et, ok := info.(interface{ ETag() string }); ok {
+		return strings.Trim(et.ETag(), `"`)
+	}
+	return ""
+}
+
+// ChangeToken returns a string that changes whenever the contents of the
+// file described by info change, for tools that need to detect
+// modifications between two scans.
+//
+// The token is the best one info offers: its VersionID() string method, as
+// provided by object stores with versioning, then its ETag without
+// surrounding quotes, and otherwise a composite of its size and
+// modification time. Tokens are only comparable between infos from the same
+// filesystem.
+func ChangeToken(info FileInfo) string {
+	if v, ok := info.(interface{ VersionID() string }); ok {
+		if id := v.VersionID(); id != "" {
+			return id
+		}
+	}
+	if et := etag(info); et != "" {
+		return et
+	}
+	return strconv.FormatInt(info.Size(), 10) + "-" +
+		strconv.FormatInt(info.ModTime().UnixNano(), 10)
+}
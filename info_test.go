@@ -0,0 +1,135 @@
+package fs_test
+
+import (
+	"testing"
+	"time"
+
+	"lesiw.io/fs"
+)
+
+type testInfo struct {
+	size  int64
+	mode  fs.Mode
+	mtime time.Time
+	etag  string
+}
+
+func (fi testInfo) Name() string       { return "file" }
+func (fi testInfo) Size() int64        { return fi.size }
+func (fi testInfo) Mode() fs.Mode      { return fi.mode }
+func (fi testInfo) ModTime() time.Time { return fi.mtime }
+func (fi testInfo) IsDir() bool        { return false }
+func (fi testInfo) Sys() any           { return nil }
+func (fi testInfo) ETag() string       { return fi.etag }
+
+func TestInfoDiff(t *testing.T) {
+	mtime := time.Date(2024, 5, 6, 7, 8, 9, 500_000_000, time.UTC)
+	base := testInfo{size: 10, mode: 0o644, mtime: mtime, etag: `"abc"`}
+	tests := []struct {
+		name      string
+		b         testInfo
+		fields    fs.InfoFields
+		tolerance time.Duration
+		want      fs.InfoFields
+	}{{
+		name:   "identical",
+		b:      base,
+		fields: fs.InfoSize | fs.InfoModTime | fs.InfoMode | fs.InfoETag,
+	}, {
+		name:      "mtime within tolerance",
+		b:         testInfo{10, 0o644, mtime.Truncate(time.Second), `"abc"`},
+		fields:    fs.InfoDefault,
+		tolerance: time.Second,
+	}, {
+		name:   "mtime without tolerance",
+		b:      testInfo{10, 0o644, mtime.Truncate(time.Second), `"abc"`},
+		fields: fs.InfoDefault,
+		want:   fs.InfoModTime,
+	}, {
+		name:      "mtime outside tolerance",
+		b:         testInfo{10, 0o644, mtime.Add(2 * time.Second), `"abc"`},
+		fields:    fs.InfoDefault,
+		tolerance: time.Second,
+		want:      fs.InfoModTime,
+	}, {
+		name:   "size and mode",
+		b:      testInfo{11, 0o600, mtime, `"abc"`},
+		fields: fs.InfoSize | fs.InfoMode,
+		want:   fs.InfoSize | fs.InfoMode,
+	}, {
+		name:   "masked out",
+		b:      testInfo{11, 0o600, mtime.Add(time.Hour), `"xyz"`},
+		fields: 0,
+	}, {
+		name:   "mode only",
+		b:      testInfo{11, 0o600, mtime.Add(time.Hour), `"xyz"`},
+		fields: fs.InfoMode,
+		want:   fs.InfoMode,
+	}, {
+		name:   "etag unquoted",
+		b:      testInfo{10, 0o644, mtime, "abc"},
+		fields: fs.InfoETag,
+	}, {
+		name:   "etag differs",
+		b:      testInfo{10, 0o644, mtime, `"xyz"`},
+		fields: fs.InfoETag,
+		want:   fs.InfoETag,
+	}, {
+		name:   "etag missing",
+		b:      testInfo{10, 0o644, mtime, ""},
+		fields: fs.InfoETag,
+		want:   fs.InfoETag,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := fs.InfoDiff(base, tt.b, tt.fields, tt.tolerance)
+			if got != tt.want {
+				t.Errorf("InfoDiff() = %b, want %b", got, tt.want)
+			}
+			if tt.tolerance != 0 {
+				return
+			}
+			if eq, want := fs.InfoEqual(base, tt.b, tt.fields),
+				tt.want == 0; eq != want {
+				t.Errorf("InfoEqual() = %v, want %v", eq, want)
+			}
+		})
+	}
+}
+
+type versionInfo struct {
+	testInfo
+	version string
+}
+
+func (fi versionInfo) VersionID() string { return fi.version }
+
+func TestChangeToken(t *testing.T) {
+	mtime := time.Unix(1700000000, 5)
+	tests := []struct {
+		name string
+		info fs.FileInfo
+		want string
+	}{
+		{"version", versionInfo{testInfo{etag: `"abc"`}, "v2"}, "v2"},
+		{"empty version", versionInfo{testInfo{etag: `"abc"`}, ""}, "abc"},
+		{"etag", testInfo{size: 3, mtime: mtime, etag: `"abc"`}, "abc"},
+		{
+			"size and mtime", testInfo{size: 3, mtime: mtime},
+			"3-1700000000000000005",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fs.ChangeToken(tt.info); got != tt.want {
+				t.Errorf("ChangeToken() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	a := fs.ChangeToken(testInfo{size: 3, mtime: mtime})
+	b := fs.ChangeToken(testInfo{size: 3, mtime: mtime.Add(time.Second)})
+	if a == b {
+		t.Errorf("ChangeToken() = %q for different mtimes", a)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"strings"
 	"time"
@@ -23,16 +24,112 @@ import (
 type httpFS struct {
 	baseURL string
 	client  *http.Client
+	isDir   func(*http.Response) bool
+}
+
+// An Option configures an HTTP filesystem.
+type Option func(*options)
+
+type options struct {
+	client         *http.Client
+	connectTimeout time.Duration
+	keepAlive      time.Duration
+	idleTimeout    time.Duration
+	isDir          func(*http.Response) bool
+}
+
+// WithClient sets the HTTP client used for requests, so that callers
+// control proxies, TLS, retries, and instrumentation. The default client
+// has a 30 second timeout. A client set with WithClient is used as is, so
+// the timeout options below do not apply to it.
+func WithClient(client *http.Client) Option {
+	return func(o *options) { o.client = client }
+}
+
+// WithConnectTimeout sets the maximum time to establish a connection,
+// including the TLS handshake. The default is 10 seconds.
+func WithConnectTimeout(d time.Duration) Option {
+	return func(o *options) { o.connectTimeout = d }
+}
+
+// WithKeepAlive sets the interval between TCP keepalive probes on open
+// connections. The default is 15 seconds. A negative duration disables
+// keepalives.
+func WithKeepAlive(d time.Duration) Option {
+	return func(o *options) { o.keepAlive = d }
+}
+
+// WithIdleTimeout sets how long an unused connection is kept for reuse.
+// Closing idle connections before the server or a proxy drops them avoids
+// failed requests on stale connections. The default is 90 seconds.
+func WithIdleTimeout(d time.Duration) Option {
+	return func(o *options) { o.idleTimeout = d }
+}
+
+// WithDirectoryDetector sets the function Stat uses to decide whether the
+// response to a HEAD request describes a directory. The response may be a
+// redirect if the client does not follow redirects.
+//
+// The default treats a text/html response, or a redirect to a URL ending
+// in a slash, as a directory. Supply a detector for servers that list
+// directories in another format, or that serve HTML files and error pages.
+func WithDirectoryDetector(isDir func(*http.Response) bool) Option {
+	return func(o *options) { o.isDir = isDir }
 }
 
 // New creates a new HTTP filesystem for the given base URL.
-func New(baseURL string) fs.FS {
+func New(baseURL string, opts ...Option) fs.FS {
+	o := options{
+		connectTimeout: 10 * time.Second,
+		keepAlive:      15 * time.Second,
+		idleTimeout:    90 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	client := o.client
+	if client == nil {
+		client = &http.Client{
+			Transport: transport(o),
+			Timeout:   30 * time.Second,
+		}
+	}
+	isDir := o.isDir
+	if isDir == nil {
+		isDir = isDirResponse
+	}
 	return &httpFS{
 		baseURL: strings.TrimSuffix(baseURL, "/"),
-		client: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		client:  client,
+		isDir:   isDir,
+	}
+}
+
+// isDirResponse is the default directory detector. HTTP file servers
+// typically serve directories as a text/html listing, and redirect a
+// directory URL without a trailing slash to one with it.
+func isDirResponse(resp *http.Response) bool {
+	switch resp.StatusCode {
+	case http.StatusMovedPermanently, http.StatusFound:
+		return strings.HasSuffix(resp.Header.Get("Location"), "/")
+	}
+	if req := resp.Request; req != nil && req.Response != nil {
+		// The client followed a redirect to get here.
+		if strings.HasSuffix(req.URL.Path, "/") {
+			return true
+		}
 	}
+	return strings.HasPrefix(resp.Header.Get("Content-Type"), "text/html")
+}
+
+// transport returns a copy of http.DefaultTransport with the timeouts in o.
+func transport(o options) *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	d := &net.Dialer{Timeout: o.connectTimeout, KeepAlive: o.keepAlive}
+	t.DialContext = d.DialContext
+	t.TLSHandshakeTimeout = o.connectTimeout
+	t.IdleConnTimeout = o.idleTimeout
+	return t
 }
 
 func (f *httpFS) fullURL(name string) string {
@@ -42,6 +139,34 @@ func (f *httpFS) fullURL(name string) string {
 	return f.baseURL + "/" + name
 }
 
+// do sends a request for name, carrying the User-Agent and any request ID
+// from ctx.
+func (f *httpFS) do(
+	ctx context.Context, method, name string,
+) (*http.Response, error) {
+	req, err := f.newRequest(ctx, method, name)
+	if err != nil {
+		return nil, err
+	}
+	return f.client.Do(req)
+}
+
+// newRequest builds a request for name, carrying the User-Agent and any
+// request ID from ctx.
+func (f *httpFS) newRequest(
+	ctx context.Context, method, name string,
+) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, f.fullURL(name), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", fs.UserAgent(ctx))
+	if id := fs.RequestID(ctx); id != "" {
+		req.Header.Set("X-Request-ID", id)
+	}
+	return req, nil
+}
+
 // Open implements fs.FS (read-only).
 func (f *httpFS) Open(
 	ctx context.Context, name string,
@@ -54,8 +179,7 @@ func (f *httpFS) Open(
 		}
 	}
 
-	url := f.fullURL(name)
-	resp, err := f.client.Get(url)
+	resp, err := f.do(ctx, http.MethodGet, name)
 	if err != nil {
 		return nil, convertError("open", name, err)
 	}
@@ -76,7 +200,101 @@ func (f *httpFS) Open(
 		}
 	}
 
-	return resp.Body, nil
+	if !acceptsRanges(resp) {
+		return resp.Body, nil
+	}
+	return &rangeReader{
+		ctx:  ctx,
+		fsys: f,
+		name: name,
+		body: resp.Body,
+		size: resp.ContentLength,
+	}, nil
+}
+
+var _ fs.SeekFS = (*httpFS)(nil)
+
+// CanSeek implements fs.SeekFS. It probes name with a HEAD request and
+// reports whether the server advertises byte range support.
+func (f *httpFS) CanSeek(ctx context.Context, name string) bool {
+	resp, err := f.do(ctx, http.MethodHead, name)
+	if err != nil {
+		return false
+	}
+	_ = resp.Body.Close()
+	return resp.StatusCode == http.StatusOK && acceptsRanges(resp)
+}
+
+// acceptsRanges reports whether resp advertises byte ranges for a body of
+// known length.
+func acceptsRanges(resp *http.Response) bool {
+	return resp.Header.Get("Accept-Ranges") == "bytes" &&
+		resp.ContentLength >= 0
+}
+
+// rangeReader is a seekable reader over an HTTP resource. After a Seek, the
+// next Read issues a GET with a Range header starting at the new offset.
+type rangeReader struct {
+	ctx  context.Context
+	fsys *httpFS
+	name string
+	body io.ReadCloser // nil until the next Read after a Seek
+	off  int64
+	size int64
+}
+
+func (r *rangeReader) Read(p []byte) (int, error) {
+	if r.off >= r.size {
+		return 0, io.EOF
+	}
+	if r.body == nil {
+		req, err := r.fsys.newRequest(r.ctx, http.MethodGet, r.name)
+		if err != nil {
+			return 0, err
+		}
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", r.off))
+		resp, err := r.fsys.client.Do(req)
+		if err != nil {
+			return 0, convertError("read", r.name, err)
+		}
+		if resp.StatusCode != http.StatusPartialContent {
+			_ = resp.Body.Close()
+			return 0, &fs.PathError{
+				Op:   "read",
+				Path: r.name,
+				Err:  fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status),
+			}
+		}
+		r.body = resp.Body
+	}
+	n, err := r.body.Read(p)
+	r.off += int64(n)
+	return n, err
+}
+
+func (r *rangeReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += r.off
+	case io.SeekEnd:
+		offset += r.size
+	}
+	if offset < 0 {
+		return 0, &fs.PathError{Op: "seek", Path: r.name, Err: fs.ErrInvalid}
+	}
+	if offset != r.off && r.body != nil {
+		_ = r.body.Close()
+		r.body = nil
+	}
+	r.off = offset
+	return offset, nil
+}
+
+func (r *rangeReader) Close() error {
+	if r.body == nil {
+		return nil
+	}
+	return r.body.Close()
 }
 
 // Stat implements fs.StatFS.
@@ -92,8 +310,7 @@ func (f *httpFS) Stat(
 		}, nil
 	}
 
-	url := f.fullURL(name)
-	resp, err := f.client.Head(url)
+	resp, err := f.do(ctx, http.MethodHead, name)
 	if err != nil {
 		return nil, convertError("stat", name, err)
 	}
@@ -107,7 +324,8 @@ func (f *httpFS) Stat(
 		}
 	}
 
-	if resp.StatusCode != http.StatusOK {
+	isDir := f.isDir(resp)
+	if resp.StatusCode != http.StatusOK && !isDir {
 		return nil, &fs.PathError{
 			Op:   "stat",
 			Path: name,
@@ -123,15 +341,6 @@ func (f *httpFS) Stat(
 		}
 	}
 
-	// Detect if this is a directory
-	// HTTP file servers typically serve directories with text/html content
-	// type (directory listing), while files have their actual content type
-	isDir := false
-	contentType := resp.Header.Get("Content-Type")
-	if strings.HasPrefix(contentType, "text/html") {
-		isDir = true
-	}
-
 	return &httpFileInfo{
 		name:  path.Base(name),
 		isDir: isDir,
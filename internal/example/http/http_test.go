@@ -1,12 +1,20 @@
 package http
 
 import (
+	"context"
+	"errors"
+	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
+	"lesiw.io/fs"
 	"lesiw.io/fs/fstest"
 )
 
@@ -54,3 +62,259 @@ func TestHTTPFS(t *testing.T) {
 	// Run the fstest suite with WithFiles for read-only filesystem
 	fstest.TestFS(ctx, t, fsys, fstest.WithFiles(testFiles...))
 }
+
+func TestRequestID(t *testing.T) {
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			got = r.Header.Get("X-Request-ID")
+			_, _ = w.Write([]byte("data"))
+		},
+	))
+	defer server.Close()
+
+	fsys := New(server.URL)
+	ctx := fs.WithRequestID(t.Context(), "req-123")
+
+	r, err := fsys.Open(ctx, "file.txt")
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	_ = r.Close()
+
+	if want := "req-123"; got != want {
+		t.Errorf("X-Request-ID = %q, want %q", got, want)
+	}
+}
+
+func TestStatModTimeUTC(t *testing.T) {
+	modTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Last-Modified", modTime.Format(http.TimeFormat))
+			w.Header().Set("Content-Type", "text/plain")
+		},
+	))
+	defer server.Close()
+
+	fsys := New(server.URL)
+	info, err := fs.Stat(t.Context(), fsys, "file.txt")
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	if got := info.ModTime().Location(); got != time.UTC {
+		t.Errorf("ModTime().Location() = %v, want UTC", got)
+	}
+	if got := info.ModTime(); !got.Equal(modTime) {
+		t.Errorf("ModTime() = %v, want %v", got, modTime)
+	}
+}
+
+func TestStatDirectory(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			switch strings.TrimPrefix(r.URL.Path, "/.") {
+			case "/listing":
+				w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			case "/data.json", "/moved/":
+				w.Header().Set("Content-Type", "application/json")
+			case "/moved":
+				http.Redirect(w, r, "/moved/", http.StatusMovedPermanently)
+			default:
+				http.NotFound(w, r)
+			}
+		},
+	))
+	defer server.Close()
+
+	noFollow := &http.Client{
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	jsonDirs := func(resp *http.Response) bool {
+		return resp.Header.Get("Content-Type") == "application/json"
+	}
+	tests := []struct {
+		name string
+		fsys fs.FS
+		path string
+		want bool
+	}{
+		{"HTMLListing", New(server.URL), "listing", true},
+		{"JSONFile", New(server.URL), "data.json", false},
+		{"RedirectToSlash", New(server.URL), "moved", true},
+		{
+			"RedirectNotFollowed",
+			New(server.URL, WithClient(noFollow)), "moved", true,
+		},
+		{
+			"Detector",
+			New(server.URL, WithDirectoryDetector(jsonDirs)), "data.json", true,
+		},
+		{
+			"DetectorHTML",
+			New(server.URL, WithDirectoryDetector(jsonDirs)), "listing", false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := fs.Stat(t.Context(), tt.fsys, tt.path)
+			if err != nil {
+				t.Fatalf("Stat(%q) error = %v", tt.path, err)
+			}
+			if got := info.IsDir(); got != tt.want {
+				t.Errorf(
+					"Stat(%q).IsDir() = %v, want %v", tt.path, got, tt.want,
+				)
+			}
+		})
+	}
+}
+
+func TestUserAgent(t *testing.T) {
+	var got []string
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			got = append(got, r.Header.Get("User-Agent"))
+		},
+	))
+	defer server.Close()
+
+	fsys := New(server.URL)
+	ctx := t.Context()
+	for _, ctx := range []context.Context{
+		ctx, fs.WithUserAgent(ctx, "myapp/2.0"),
+	} {
+		r, err := fsys.Open(ctx, "file.txt")
+		if err != nil {
+			t.Fatalf("Open() error = %v", err)
+		}
+		_ = r.Close()
+	}
+
+	want := []string{fs.DefaultUserAgent, "myapp/2.0"}
+	if !slices.Equal(got, want) {
+		t.Errorf("User-Agent = %q, want %q", got, want)
+	}
+}
+
+// recordingTransport records the method of each request it sends.
+type recordingTransport struct {
+	methods []string
+}
+
+func (rt *recordingTransport) RoundTrip(
+	req *http.Request,
+) (*http.Response, error) {
+	rt.methods = append(rt.methods, req.Method)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestWithClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/plain")
+			_, _ = w.Write([]byte("data"))
+		},
+	))
+	defer server.Close()
+
+	rt := &recordingTransport{}
+	fsys := New(server.URL, WithClient(&http.Client{Transport: rt}))
+	ctx := t.Context()
+
+	r, err := fsys.Open(ctx, "file.txt")
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	_ = r.Close()
+	if _, err := fs.Stat(ctx, fsys, "file.txt"); err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+
+	want := []string{http.MethodGet, http.MethodHead}
+	if !slices.Equal(rt.methods, want) {
+		t.Errorf("transport methods = %v, want %v", rt.methods, want)
+	}
+}
+
+func TestCanSeek(t *testing.T) {
+	modTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if strings.HasSuffix(r.URL.Path, "stream.txt") {
+				_, _ = w.Write([]byte("stream"))
+				return
+			}
+			http.ServeContent(w, r, "file.txt", modTime,
+				strings.NewReader("0123456789"))
+		},
+	))
+	defer server.Close()
+
+	fsys := New(server.URL)
+	ctx := t.Context()
+
+	if fs.CanSeek(ctx, fsys, "stream.txt") {
+		t.Errorf("CanSeek(%q) = true, want false", "stream.txt")
+	}
+	if !fs.CanSeek(ctx, fsys, "file.txt") {
+		t.Fatalf("CanSeek(%q) = false, want true", "file.txt")
+	}
+
+	r, err := fs.Open(ctx, fsys, "file.txt")
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer r.Close()
+	rs, ok := r.(io.ReadSeekCloser)
+	if !ok {
+		t.Fatalf("Open() = %T, want io.ReadSeekCloser", r)
+	}
+	if _, err := rs.Seek(-4, io.SeekEnd); err != nil {
+		t.Fatalf("Seek() error = %v", err)
+	}
+	data, err := io.ReadAll(rs)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	if got, want := string(data), "6789"; got != want {
+		t.Errorf("ReadAll() = %q, want %q", got, want)
+	}
+}
+
+func TestConnectTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("data"))
+		},
+	))
+	defer server.Close()
+	ctx := t.Context()
+
+	fsys := New(server.URL,
+		WithConnectTimeout(30*time.Second),
+		WithIdleTimeout(time.Minute),
+	)
+	if _, err := fs.ReadFile(ctx, fsys, "file.txt"); err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	tr := fsys.(*httpFS).client.Transport.(*http.Transport)
+	if got, want := tr.IdleConnTimeout, time.Minute; got != want {
+		t.Errorf("IdleConnTimeout = %v, want %v", got, want)
+	}
+
+	start := time.Now()
+	fsys = New(server.URL, WithConnectTimeout(time.Nanosecond))
+	_, err := fs.ReadFile(ctx, fsys, "file.txt")
+	var nerr net.Error
+	if !errors.As(err, &nerr) || !nerr.Timeout() {
+		t.Fatalf("ReadFile() with 1ns connect timeout error = %v, "+
+			"want timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("ReadFile() with 1ns connect timeout took %v, "+
+			"want fast failure", elapsed)
+	}
+}
@@ -8,10 +8,13 @@ import (
 
 // s3FileInfo implements fs.FileInfo for S3 objects
 type s3FileInfo struct {
-	name string
-	size int64
-	mode fs.Mode
-	time time.Time
+	name        string
+	size        int64
+	mode        fs.Mode
+	time        time.Time
+	contentType string
+	etag        string
+	versionID   string
 }
 
 func (fi *s3FileInfo) Name() string       { return fi.name }
@@ -22,12 +25,26 @@ func (fi *s3FileInfo) Sys() any           { return nil }
 
 func (fi *s3FileInfo) IsDir() bool { return fi.mode.IsDir() }
 
+// BlockSize reports the minimum multipart upload part size, 5 MiB.
+func (fi *s3FileInfo) BlockSize() int64 { return 5 << 20 }
+
+// ContentType reports the object's stored Content-Type.
+func (fi *s3FileInfo) ContentType() string { return fi.contentType }
+
+// ETag reports the object's ETag.
+func (fi *s3FileInfo) ETag() string { return fi.etag }
+
+// VersionID reports the object's version ID, if the bucket is versioned.
+// Only Stat reports it; ReadDir lists the latest objects without versions.
+func (fi *s3FileInfo) VersionID() string { return fi.versionID }
+
 // s3DirEntry implements fs.DirEntry for S3 objects
 type s3DirEntry struct {
 	name  string
 	isDir bool
 	size  int64
 	time  time.Time
+	etag  string
 }
 
 func (de *s3DirEntry) Name() string { return de.name }
@@ -49,6 +66,7 @@ func (de *s3DirEntry) Info() (fs.FileInfo, error) {
 		size: de.size,
 		mode: mode,
 		time: de.time,
+		etag: de.etag,
 	}, nil
 }
 
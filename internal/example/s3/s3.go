@@ -10,11 +10,18 @@ package s3
 
 import (
 	"bytes"
+	"cmp"
 	"context"
 	"fmt"
 	"io"
 	"iter"
+	"mime"
+	"net"
+	"net/http"
+	stdpath "path"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
@@ -29,6 +36,35 @@ type s3FS struct {
 	bucket string
 }
 
+// An Option configures an S3 filesystem.
+type Option func(*options)
+
+type options struct {
+	connectTimeout time.Duration
+	keepAlive      time.Duration
+	idleTimeout    time.Duration
+}
+
+// WithConnectTimeout sets the maximum time to establish a connection,
+// including the TLS handshake. The default is 10 seconds.
+func WithConnectTimeout(d time.Duration) Option {
+	return func(o *options) { o.connectTimeout = d }
+}
+
+// WithKeepAlive sets the interval between TCP keepalive probes on open
+// connections. The default is 15 seconds. A negative duration disables
+// keepalives.
+func WithKeepAlive(d time.Duration) Option {
+	return func(o *options) { o.keepAlive = d }
+}
+
+// WithIdleTimeout sets how long an unused connection is kept for reuse.
+// Closing idle connections before the server or a proxy drops them avoids
+// failed requests on stale connections. The default is 90 seconds.
+func WithIdleTimeout(d time.Duration) Option {
+	return func(o *options) { o.idleTimeout = d }
+}
+
 // New creates a new S3 filesystem.
 //
 // endpoint: S3 endpoint (e.g., "localhost:9000" for MinIO)
@@ -38,10 +74,28 @@ type s3FS struct {
 // useSSL: whether to use HTTPS
 func New(
 	endpoint, bucket, accessKey, secretKey string, useSSL bool,
+	opts ...Option,
 ) (fs.FS, error) {
+	o := options{
+		connectTimeout: 10 * time.Second,
+		keepAlive:      15 * time.Second,
+		idleTimeout:    90 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	transport, err := minio.DefaultTransport(useSSL)
+	if err != nil {
+		return nil, fmt.Errorf("creating transport: %w", err)
+	}
+	d := &net.Dialer{Timeout: o.connectTimeout, KeepAlive: o.keepAlive}
+	transport.DialContext = d.DialContext
+	transport.TLSHandshakeTimeout = o.connectTimeout
+	transport.IdleConnTimeout = o.idleTimeout
 	client, err := minio.New(endpoint, &minio.Options{
-		Creds:  credentials.NewStaticV4(accessKey, secretKey, ""),
-		Secure: useSSL,
+		Creds:     credentials.NewStaticV4(accessKey, secretKey, ""),
+		Secure:    useSSL,
+		Transport: headerTransport{transport},
 	})
 	if err != nil {
 		return nil, fmt.Errorf("creating minio client: %w", err)
@@ -53,8 +107,31 @@ func New(
 	}, nil
 }
 
+// headerTransport adds the User-Agent and any request ID carried by the
+// request context to outbound requests.
+type headerTransport struct {
+	base http.RoundTripper
+}
+
+func (t headerTransport) RoundTrip(
+	req *http.Request,
+) (*http.Response, error) {
+	ctx := req.Context()
+	req = req.Clone(ctx)
+	req.Header.Set("User-Agent", fs.UserAgent(ctx))
+	if id := fs.RequestID(ctx); id != "" {
+		req.Header.Set("X-Request-ID", id)
+	}
+	return t.base.RoundTrip(req)
+}
+
+// resolveName converts name to an object key.
 func (f *s3FS) resolveName(name string) string {
 	if !path.IsAbs(name) {
+		// Keys have no "./" prefix; fs helpers pass cleaned relative paths.
+		if key := strings.TrimPrefix(name, "./"); key != "" {
+			return key
+		}
 		return name
 	}
 	root := "s3://" + f.bucket
@@ -84,19 +161,223 @@ func (f *s3FS) Open(ctx context.Context, name string) (io.ReadCloser, error) {
 	return obj, nil
 }
 
+var _ fs.OpenRangeFS = (*s3FS)(nil)
+
+// OpenRange reads length bytes of the object starting at offset with a
+// ranged GET, so that only the requested bytes are transferred.
+//
+// The reader implements io.Seeker within the range, with offsets relative
+// to its start. A Seek drops the current response, and the next Read
+// requests the rest of the range from the new position.
+func (f *s3FS) OpenRange(
+	ctx context.Context, name string, offset, length int64,
+) (io.ReadCloser, error) {
+	name = f.resolveName(name)
+	r := &s3RangeReader{
+		ctx:   ctx,
+		fsys:  f,
+		name:  name,
+		start: offset,
+		off:   offset,
+		end:   -1,
+	}
+	if length >= 0 {
+		r.end = offset + length
+	}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// s3RangeReader is a seekable reader over a byte range of an S3 object.
+// Offsets are absolute within the object.
+type s3RangeReader struct {
+	ctx   context.Context
+	fsys  *s3FS
+	name  string
+	body  io.ReadCloser // nil until the next Read after a Seek
+	start int64
+	off   int64
+	end   int64 // -1 for the end of the object, until a Seek finds it
+}
+
+// open requests the bytes from r.off to the end of the range.
+func (r *s3RangeReader) open() error {
+	if r.end >= 0 && r.off >= r.end {
+		r.body = http.NoBody
+		return nil
+	}
+	var opts minio.GetObjectOptions
+	var err error
+	switch {
+	case r.end >= 0:
+		err = opts.SetRange(r.off, r.end-1)
+	case r.off > 0:
+		err = opts.SetRange(r.off, 0)
+	}
+	if err != nil {
+		return &fs.PathError{Op: "open", Path: r.name, Err: err}
+	}
+	obj, err := r.fsys.client.GetObject(r.ctx, r.fsys.bucket, r.name, opts)
+	if err == nil {
+		// Stat sends the request, so that errors surface here.
+		_, err = obj.Stat()
+	}
+	if err != nil {
+		if obj != nil {
+			_ = obj.Close()
+		}
+		switch minio.ToErrorResponse(err).Code {
+		case "InvalidRange":
+			// The range starts past the end of the object.
+			r.body = http.NoBody
+			return nil
+		case "NoSuchKey":
+			err = fs.ErrNotExist
+		}
+		return &fs.PathError{Op: "open", Path: r.name, Err: err}
+	}
+	r.body = obj
+	return nil
+}
+
+func (r *s3RangeReader) Read(p []byte) (int, error) {
+	if r.body == nil {
+		if err := r.open(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.body.Read(p)
+	r.off += int64(n)
+	return n, err
+}
+
+func (r *s3RangeReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+		offset += r.start
+	case io.SeekCurrent:
+		offset += r.off
+	case io.SeekEnd:
+		if r.end < 0 {
+			info, err := r.fsys.client.StatObject(
+				r.ctx, r.fsys.bucket, r.name, minio.StatObjectOptions{},
+			)
+			if err != nil {
+				return 0, &fs.PathError{Op: "seek", Path: r.name, Err: err}
+			}
+			r.end = info.Size
+		}
+		offset += r.end
+	}
+	if offset < r.start {
+		return 0, &fs.PathError{Op: "seek", Path: r.name, Err: fs.ErrInvalid}
+	}
+	if offset != r.off && r.body != nil {
+		_ = r.body.Close()
+		r.body = nil
+	}
+	r.off = offset
+	return offset - r.start, nil
+}
+
+func (r *s3RangeReader) Close() error {
+	if r.body == nil {
+		return nil
+	}
+	return r.body.Close()
+}
+
 var _ fs.CreateFS = (*s3FS)(nil)
 
+// Create streams the object to S3 as it is written, through a pipe read by
+// a background upload. Callers must Close the writer: the object is not
+// stored until then, and Close reports whether the upload succeeded.
+// Objects no larger than one part are buffered and sent with a single PUT;
+// larger ones are sent as a multipart upload.
+//
+// The upload is tied to ctx. If ctx is canceled, the upload is abandoned,
+// and pending and later writes fail instead of blocking forever.
 func (f *s3FS) Create(
 	ctx context.Context, name string,
 ) (io.WriteCloser, error) {
 	name = f.resolveName(name)
-	return &s3WriteCloser{
-		ctx:        ctx,
-		client:     f.client,
-		bucket:     f.bucket,
-		name:       name,
-		mustUpload: true,
-	}, nil
+	pr, pw := io.Pipe()
+	up := &upload{done: make(chan struct{})}
+	stop := context.AfterFunc(ctx, func() {
+		_ = pr.CloseWithError(ctx.Err())
+	})
+	go func() {
+		defer close(up.done)
+		up.err = f.put(ctx, name, pr)
+		// Unblock any writer still waiting on the pipe.
+		_ = pr.CloseWithError(cmp.Or(up.err, io.ErrClosedPipe))
+	}()
+	return &s3StreamWriter{name: name, pw: pw, up: up, stop: stop}, nil
+}
+
+// partSize is the part size of multipart uploads, and the most Create
+// buffers before choosing between a single PUT and a multipart upload.
+// Left unset for a stream of unknown length, minio-go sizes parts for the
+// largest object S3 allows and buffers 528 MiB per upload.
+const partSize = 16 << 20
+
+// put uploads the contents of r as the named object. A single PUT keeps the
+// object's ETag the MD5 of its contents, so it is used whenever the contents
+// fit in one part.
+func (f *s3FS) put(ctx context.Context, name string, r io.Reader) error {
+	var head bytes.Buffer
+	n, err := io.CopyN(&head, r, partSize+1)
+	if err != nil && err != io.EOF {
+		return err
+	}
+	opts := putOptions(ctx, name)
+	if err == io.EOF {
+		_, err = f.client.PutObject(ctx, f.bucket, name, &head, n, opts)
+		return err
+	}
+	_, err = f.client.PutObject(
+		ctx, f.bucket, name, io.MultiReader(&head, r), -1, opts,
+	)
+	return err
+}
+
+// upload is the result of a streaming upload. It is shared with the upload
+// goroutine, which must not reference the writer, so that a writer that is
+// never closed can still be garbage collected.
+type upload struct {
+	done chan struct{}
+	err  error // valid after done is closed
+}
+
+// s3StreamWriter pipes writes to a PutObject running in the background.
+type s3StreamWriter struct {
+	name   string
+	pw     *io.PipeWriter
+	up     *upload
+	stop   func() bool
+	closed bool
+}
+
+// Path returns the object key being written.
+func (w *s3StreamWriter) Path() string { return w.name }
+
+func (w *s3StreamWriter) Write(p []byte) (int, error) {
+	return w.pw.Write(p)
+}
+
+// Close ends the stream and waits for the upload to finish, returning its
+// error.
+func (w *s3StreamWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	_ = w.pw.Close()
+	<-w.up.done
+	w.stop()
+	return w.up.err
 }
 
 var _ fs.AppendFS = (*s3FS)(nil)
@@ -149,6 +430,9 @@ type s3WriteCloser struct {
 	mustUpload bool
 }
 
+// Path returns the object key being written.
+func (w *s3WriteCloser) Path() string { return w.name }
+
 func (w *s3WriteCloser) Write(p []byte) (int, error) {
 	if w.buf == nil {
 		w.buf = &bytes.Buffer{}
@@ -174,13 +458,44 @@ func (w *s3WriteCloser) Close() error {
 		w.name,
 		w.buf,
 		int64(w.buf.Len()),
-		minio.PutObjectOptions{
-			ContentType: "application/octet-stream",
-		},
+		putOptions(w.ctx, w.name),
 	)
 	return err
 }
 
+// An OptionKey identifies an S3-specific option set per operation with
+// fs.WithBackendOption.
+type OptionKey int
+
+const (
+	// StorageClass selects the storage class of uploaded objects, such as
+	// "STANDARD_IA" or "GLACIER". The value is a string; if it is unset or
+	// empty, the bucket's default class is used.
+	StorageClass OptionKey = iota
+)
+
+// putOptions returns the options for uploading the named object.
+func putOptions(ctx context.Context, name string) minio.PutObjectOptions {
+	contentType := mime.TypeByExtension(stdpath.Ext(name))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	opts := minio.PutObjectOptions{
+		ContentType:     contentType,
+		ContentEncoding: fs.ContentEncoding(ctx),
+		PartSize:        partSize,
+	}
+	if class, ok := fs.BackendOption(ctx, StorageClass).(string); ok {
+		opts.StorageClass = class
+	}
+	if key := fs.IdempotencyKey(ctx); key != "" {
+		// Sent as x-amz-meta-idempotency-key, so that a retried upload
+		// can be matched against the object it already produced.
+		opts.UserMetadata = map[string]string{"Idempotency-Key": key}
+	}
+	return opts
+}
+
 var _ fs.StatFS = (*s3FS)(nil)
 
 func (f *s3FS) Stat(ctx context.Context, name string) (fs.FileInfo, error) {
@@ -191,45 +506,8 @@ func (f *s3FS) Stat(ctx context.Context, name string) (fs.FileInfo, error) {
 	if err != nil {
 		errResp := minio.ToErrorResponse(err)
 		if errResp.Code == "NoSuchKey" {
-			// Check if this is a virtual directory by looking for objects
-			// with this prefix
-			prefix := name
-			if prefix == "." {
-				prefix = ""
-			} else if !strings.HasSuffix(prefix, "/") {
-				prefix += "/"
-			}
-
-			// List one object with this prefix to see if dir exists
-			for obj := range f.client.ListObjects(
-				ctx, f.bucket, minio.ListObjectsOptions{
-					Prefix:    prefix,
-					Recursive: false,
-					MaxKeys:   1,
-				},
-			) {
-				if obj.Err != nil {
-					return nil, &fs.PathError{
-						Op:   "stat",
-						Path: name,
-						Err:  obj.Err,
-					}
-				}
-				// Found an object with this prefix - it's a directory
-				return &s3FileInfo{
-					name: path.Base(name),
-					size: 0,
-					mode: 0755 | fs.ModeDir,
-					time: obj.LastModified,
-				}, nil
-			}
-
-			// Not a file and not a directory
-			return nil, &fs.PathError{
-				Op:   "stat",
-				Path: name,
-				Err:  fs.ErrNotExist,
-			}
+			// Not a file, but it may be a virtual directory.
+			return f.statDir(ctx, name)
 		}
 		return nil, &fs.PathError{
 			Op:   "stat",
@@ -239,13 +517,67 @@ func (f *s3FS) Stat(ctx context.Context, name string) (fs.FileInfo, error) {
 	}
 
 	return &s3FileInfo{
-		name: path.Base(name),
-		size: info.Size,
-		mode: 0644,
-		time: info.LastModified,
+		name:        path.Base(name),
+		size:        info.Size,
+		mode:        0644,
+		time:        info.LastModified,
+		contentType: info.ContentType,
+		etag:        info.ETag,
+		versionID:   info.VersionID,
 	}, nil
 }
 
+// statDir reports name as a virtual directory if any key lies under it.
+// Unlike ReadDir, which lists and sorts the whole prefix, it stops at the
+// first key, which a one-key listing returns in a single request.
+func (f *s3FS) statDir(ctx context.Context, name string) (fs.FileInfo, error) {
+	prefix := strings.TrimSuffix(name, "/") + "/"
+	if name == "." {
+		prefix = ""
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	for obj := range f.client.ListObjects(
+		ctx, f.bucket, minio.ListObjectsOptions{Prefix: prefix, MaxKeys: 1},
+	) {
+		if obj.Err != nil {
+			return nil, &fs.PathError{Op: "stat", Path: name, Err: obj.Err}
+		}
+		if obj.Key == prefix {
+			// An empty directory marker, as ReadDir skips it.
+			continue
+		}
+		return &s3FileInfo{
+			name: path.Base(strings.TrimSuffix(name, "/")),
+			mode: fs.ModeDir | 0755,
+		}, nil
+	}
+	return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+}
+
+var _ fs.ExistsFS = (*s3FS)(nil)
+
+// Exists checks for an object with a single HEAD request. Unlike Stat, it
+// does not list the prefix to look for a virtual directory, so a name
+// without a trailing slash exists only if it is an object. Directory names
+// fall back to Stat.
+func (f *s3FS) Exists(ctx context.Context, name string) (bool, error) {
+	if path.IsDir(name) {
+		return false, fs.ErrUnsupported
+	}
+	name = f.resolveName(name)
+	_, err := f.client.StatObject(
+		ctx, f.bucket, name, minio.StatObjectOptions{},
+	)
+	if err == nil {
+		return true, nil
+	}
+	if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+		return false, nil
+	}
+	return false, &fs.PathError{Op: "exists", Path: name, Err: err}
+}
+
 var _ fs.ReadDirFS = (*s3FS)(nil)
 
 func (f *s3FS) ReadDir(
@@ -253,9 +585,13 @@ func (f *s3FS) ReadDir(
 ) iter.Seq2[fs.DirEntry, error] {
 	name = f.resolveName(name)
 	return func(yield func(fs.DirEntry, error) bool) {
-		// Check if this is a file (not a directory)
-		info, statErr := f.Stat(ctx, name)
-		if statErr == nil && !info.IsDir() {
+		// Check if this is a file (not a directory). Stat would recurse
+		// back into ReadDir to detect virtual directories, so query the
+		// object directly.
+		_, statErr := f.client.StatObject(
+			ctx, f.bucket, name, minio.StatObjectOptions{},
+		)
+		if statErr == nil {
 			yield(nil, &fs.PathError{
 				Op:   "readdir",
 				Path: name,
@@ -271,6 +607,9 @@ func (f *s3FS) ReadDir(
 			prefix += "/"
 		}
 
+		// ListObjects interleaves keys and common prefixes, so collect
+		// the entries and sort them by name before yielding.
+		var entries []*s3DirEntry
 		for obj := range f.client.ListObjects(
 			ctx, f.bucket, minio.ListObjectsOptions{
 				Prefix:    prefix,
@@ -297,18 +636,38 @@ func (f *s3FS) ReadDir(
 				continue
 			}
 
-			if !yield(&s3DirEntry{
+			entries = append(entries, &s3DirEntry{
 				name:  strings.TrimSuffix(relName, "/"),
 				isDir: strings.HasSuffix(obj.Key, "/"),
 				size:  obj.Size,
 				time:  obj.LastModified,
-			}, nil) {
+				etag:  obj.ETag,
+			})
+		}
+
+		slices.SortFunc(entries, func(a, b *s3DirEntry) int {
+			return strings.Compare(a.name, b.name)
+		})
+		for _, e := range entries {
+			if !yield(e, nil) {
 				return
 			}
 		}
 	}
 }
 
+var _ fs.CountFS = (*s3FS)(nil)
+
+// Count reports ErrUnsupported: S3 has no way to count the keys under a
+// prefix without listing them, so fs.Count falls back to ReadDir.
+func (f *s3FS) Count(ctx context.Context, dir string) (int, error) {
+	return 0, &fs.PathError{
+		Op:   "count",
+		Path: f.resolveName(dir),
+		Err:  fs.ErrUnsupported,
+	}
+}
+
 var _ fs.RemoveFS = (*s3FS)(nil)
 
 func (f *s3FS) Remove(ctx context.Context, name string) error {
@@ -351,6 +710,93 @@ func (f *s3FS) Remove(ctx context.Context, name string) error {
 	return nil
 }
 
+var _ fs.CopyFS = (*s3FS)(nil)
+
+// Copy copies an object within the bucket with CopyObject, so that its
+// data never leaves the server. The copy keeps the source's metadata.
+// Directories are left to the tar stream fallback in fs.Copy.
+func (f *s3FS) Copy(ctx context.Context, src, dst string) error {
+	if path.IsDir(src) || path.IsDir(dst) {
+		return fs.ErrUnsupported
+	}
+	src, dst = f.resolveName(src), f.resolveName(dst)
+	_, err := f.client.CopyObject(
+		ctx,
+		minio.CopyDestOptions{Bucket: f.bucket, Object: dst},
+		minio.CopySrcOptions{Bucket: f.bucket, Object: src},
+	)
+	if err != nil {
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			err = fs.ErrNotExist
+		}
+		return &fs.PathError{Op: "copy", Path: src, Err: err}
+	}
+	return nil
+}
+
+var _ fs.MultipartFS = (*s3FS)(nil)
+
+// CreateMultipart starts an S3 multipart upload. S3 rejects parts smaller
+// than 5 MiB, other than the last, when the upload is completed.
+func (f *s3FS) CreateMultipart(
+	ctx context.Context, name string,
+) (string, error) {
+	name = f.resolveName(name)
+	id, err := f.core().NewMultipartUpload(
+		ctx, f.bucket, name, putOptions(ctx, name),
+	)
+	if err != nil {
+		return "", &fs.PathError{Op: "upload", Path: name, Err: err}
+	}
+	return id, nil
+}
+
+func (f *s3FS) UploadPart(
+	ctx context.Context, name, uploadID string, number int,
+	r io.Reader, size int64,
+) (fs.Part, error) {
+	name = f.resolveName(name)
+	part, err := f.core().PutObjectPart(
+		ctx, f.bucket, name, uploadID, number, r, size,
+		minio.PutObjectPartOptions{},
+	)
+	if err != nil {
+		return fs.Part{}, &fs.PathError{Op: "upload", Path: name, Err: err}
+	}
+	return fs.Part{Number: part.PartNumber, ETag: part.ETag}, nil
+}
+
+func (f *s3FS) CompleteMultipart(
+	ctx context.Context, name, uploadID string, parts []fs.Part,
+) error {
+	name = f.resolveName(name)
+	complete := make([]minio.CompletePart, len(parts))
+	for i, p := range parts {
+		complete[i] = minio.CompletePart{PartNumber: p.Number, ETag: p.ETag}
+	}
+	_, err := f.core().CompleteMultipartUpload(
+		ctx, f.bucket, name, uploadID, complete, putOptions(ctx, name),
+	)
+	if err != nil {
+		return &fs.PathError{Op: "upload", Path: name, Err: err}
+	}
+	return nil
+}
+
+func (f *s3FS) AbortMultipart(
+	ctx context.Context, name, uploadID string,
+) error {
+	name = f.resolveName(name)
+	err := f.core().AbortMultipartUpload(ctx, f.bucket, name, uploadID)
+	if err != nil {
+		return &fs.PathError{Op: "upload", Path: name, Err: err}
+	}
+	return nil
+}
+
+// core exposes the low-level multipart API of the client.
+func (f *s3FS) core() minio.Core { return minio.Core{Client: f.client} }
+
 var _ fs.LocalizeFS = (*s3FS)(nil)
 
 func (f *s3FS) Localize(ctx context.Context, name string) (string, error) {
@@ -1,10 +1,19 @@
 package s3
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"crypto/md5"
+	"errors"
 	"fmt"
+	"io"
+	"maps"
+	"net/http"
 	"os"
 	"runtime"
+	"slices"
+	"sync"
 	"testing"
 	"time"
 
@@ -13,6 +22,7 @@ import (
 
 	"lesiw.io/ctrctl"
 	"lesiw.io/defers"
+	"lesiw.io/fs"
 	"lesiw.io/fs/fstest"
 )
 
@@ -59,6 +69,567 @@ func TestS3FS(t *testing.T) {
 	fstest.TestFS(ctx, t, fsys)
 }
 
+func TestCreatePath(t *testing.T) {
+	if testEndpoint == "" {
+		t.Skip("MinIO not available")
+	}
+
+	fsys, err := New(
+		testEndpoint, "test-bucket", "minioadmin", "minioadmin", false,
+	)
+	if err != nil {
+		t.Fatalf("Failed to create S3 filesystem: %v", err)
+	}
+	ctx := t.Context()
+
+	w, err := fs.Create(ctx, fsys, "./a/b")
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	t.Cleanup(func() { _ = fs.Remove(ctx, fsys, "a/b") })
+
+	if got, want := w.Path(), "a/b"; got != want {
+		t.Errorf("Create().Path() = %q, want %q", got, want)
+	}
+	if _, err := fsys.(*s3FS).client.StatObject(
+		ctx, "test-bucket", w.Path(), minio.StatObjectOptions{},
+	); err != nil {
+		t.Errorf("StatObject(%q) error = %v", w.Path(), err)
+	}
+}
+
+func TestCreateCancel(t *testing.T) {
+	if testEndpoint == "" {
+		t.Skip("MinIO not available")
+	}
+
+	fsys, err := New(
+		testEndpoint, "test-bucket", "minioadmin", "minioadmin", false,
+	)
+	if err != nil {
+		t.Fatalf("Failed to create S3 filesystem: %v", err)
+	}
+	ctx, cancel := context.WithCancel(t.Context())
+
+	w, err := fs.Create(ctx, fsys, "stuck.bin")
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if _, err := w.Write([]byte("partial")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	cancel()
+
+	closed := make(chan error, 1)
+	go func() { closed <- w.Close() }()
+	select {
+	case err := <-closed:
+		if err == nil {
+			t.Error("Close() after cancel error = nil, want error")
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Close() still blocked 10s after cancel")
+	}
+	if _, err := w.Write([]byte("more")); err == nil {
+		t.Error("Write() after cancel error = nil, want error")
+	}
+	_, err = fs.Stat(t.Context(), fsys, "stuck.bin")
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Stat() error = %v, want ErrNotExist", err)
+	}
+}
+
+// detectLeak returns a channel that receives the name of w if w is garbage
+// collected without having been closed.
+func detectLeak(w *s3StreamWriter) <-chan string {
+	leaked := make(chan string, 1)
+	runtime.SetFinalizer(w, func(w *s3StreamWriter) {
+		if !w.closed {
+			leaked <- w.name
+		}
+	})
+	return leaked
+}
+
+func TestCreateLeak(t *testing.T) {
+	if testEndpoint == "" {
+		t.Skip("MinIO not available")
+	}
+
+	fsys, err := New(
+		testEndpoint, "test-bucket", "minioadmin", "minioadmin", false,
+	)
+	if err != nil {
+		t.Fatalf("Failed to create S3 filesystem: %v", err)
+	}
+	// Canceling ctx at the end of the test ends the abandoned upload.
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	var leaked <-chan string
+	func() {
+		w, err := fsys.(*s3FS).Create(ctx, "leak.bin")
+		if err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		leaked = detectLeak(w.(*s3StreamWriter))
+		if _, err := w.Write([]byte("never closed")); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}()
+
+	for range 50 {
+		runtime.GC()
+		select {
+		case name := <-leaked:
+			if name != "leak.bin" {
+				t.Errorf("leaked writer = %q, want %q", name, "leak.bin")
+			}
+			return
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	t.Fatal("writer that was never closed was not detected")
+}
+
+func TestUploadParallel(t *testing.T) {
+	if testEndpoint == "" {
+		t.Skip("MinIO not available")
+	}
+
+	fsys, err := New(
+		testEndpoint, "test-bucket", "minioadmin", "minioadmin", false,
+	)
+	if err != nil {
+		t.Fatalf("Failed to create S3 filesystem: %v", err)
+	}
+	ctx := t.Context()
+
+	// S3 requires every part but the last to be at least 5 MiB.
+	data := bytes.Repeat([]byte("0123456789abcdef"), 4*5<<20/16)
+	data = append(data, "tail"...)
+
+	for _, parts := range []int{4, 1} {
+		name := fmt.Sprintf("upload-%d.bin", parts)
+		err := fs.UploadParallel(ctx, fsys, name,
+			bytes.NewReader(data), int64(len(data)), parts)
+		if err != nil {
+			t.Fatalf("UploadParallel(%d parts) error = %v", parts, err)
+		}
+		t.Cleanup(func() { _ = fs.Remove(ctx, fsys, name) })
+
+		got, err := fs.ReadFile(ctx, fsys, name)
+		if err != nil {
+			t.Fatalf("ReadFile(%q) error = %v", name, err)
+		}
+		if !bytes.Equal(got, data) {
+			t.Errorf("ReadFile(%q) = %d bytes, want %d matching bytes",
+				name, len(got), len(data))
+		}
+	}
+}
+
+func TestStorageClass(t *testing.T) {
+	for _, class := range []string{"STANDARD_IA", "GLACIER"} {
+		ctx := fs.WithBackendOption(t.Context(), StorageClass, class)
+		if got := putOptions(ctx, "cold.bin").StorageClass; got != class {
+			t.Errorf("putOptions().StorageClass = %q, want %q", got, class)
+		}
+	}
+	if got := putOptions(t.Context(), "hot.bin").StorageClass; got != "" {
+		t.Errorf("putOptions().StorageClass = %q, want default", got)
+	}
+}
+
+func TestContentEncoding(t *testing.T) {
+	if testEndpoint == "" {
+		t.Skip("MinIO not available")
+	}
+
+	fsys, err := New(
+		testEndpoint, "test-bucket", "minioadmin", "minioadmin", false,
+	)
+	if err != nil {
+		t.Fatalf("Failed to create S3 filesystem: %v", err)
+	}
+	ctx := fs.WithContentEncoding(t.Context(), "gzip")
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte("hello, world")); err != nil {
+		t.Fatalf("gzip Write() error = %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("gzip Close() error = %v", err)
+	}
+
+	name := "encoding/hello.txt"
+	if err := fs.WriteFile(ctx, fsys, name, buf.Bytes()); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	t.Cleanup(func() { _ = fs.Remove(ctx, fsys, name) })
+
+	s3fs := fsys.(*s3FS)
+	info, err := s3fs.client.StatObject(
+		ctx, s3fs.bucket, name, minio.StatObjectOptions{},
+	)
+	if err != nil {
+		t.Fatalf("StatObject() error = %v", err)
+	}
+	if got := info.Metadata.Get("Content-Encoding"); got != "gzip" {
+		t.Errorf("Content-Encoding = %q, want %q", got, "gzip")
+	}
+	if got := putOptions(t.Context(), name).ContentEncoding; got != "" {
+		t.Errorf("putOptions().ContentEncoding = %q, want default", got)
+	}
+}
+
+// countingTransport counts the requests made through it by method.
+type countingTransport struct {
+	base http.RoundTripper
+
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (t *countingTransport) RoundTrip(
+	req *http.Request,
+) (*http.Response, error) {
+	t.mu.Lock()
+	t.counts[req.Method]++
+	t.mu.Unlock()
+	return t.base.RoundTrip(req)
+}
+
+func (t *countingTransport) reset() map[string]int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	counts := t.counts
+	t.counts = make(map[string]int)
+	return counts
+}
+
+func TestExistsSingleHead(t *testing.T) {
+	if testEndpoint == "" {
+		t.Skip("MinIO not available")
+	}
+
+	base, err := minio.DefaultTransport(false)
+	if err != nil {
+		t.Fatalf("DefaultTransport() error = %v", err)
+	}
+	rt := &countingTransport{base: base, counts: make(map[string]int)}
+	client, err := minio.New(testEndpoint, &minio.Options{
+		Creds:     credentials.NewStaticV4("minioadmin", "minioadmin", ""),
+		Transport: rt,
+	})
+	if err != nil {
+		t.Fatalf("minio.New() error = %v", err)
+	}
+	fsys := &s3FS{client: client, bucket: "test-bucket"}
+	ctx := t.Context()
+
+	if err := fs.WriteFile(ctx, fsys, "exists/file.txt", nil); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	t.Cleanup(func() { _ = fs.Remove(ctx, fsys, "exists/file.txt") })
+
+	for name, want := range map[string]bool{
+		"exists/file.txt":    true,
+		"exists/missing.txt": false,
+	} {
+		rt.reset()
+		got, err := fs.Exists(ctx, fsys, name)
+		if err != nil {
+			t.Fatalf("Exists(%q) error = %v", name, err)
+		}
+		if got != want {
+			t.Errorf("Exists(%q) = %v, want %v", name, got, want)
+		}
+		counts := rt.reset()
+		if want := map[string]int{"HEAD": 1}; !maps.Equal(counts, want) {
+			t.Errorf("Exists(%q) requests = %v, want %v", name, counts, want)
+		}
+	}
+}
+
+func TestCreateSinglePut(t *testing.T) {
+	if got := putOptions(t.Context(), "f").PartSize; got != partSize {
+		t.Errorf("putOptions().PartSize = %d, want %d", got, partSize)
+	}
+	if testEndpoint == "" {
+		t.Skip("MinIO not available")
+	}
+
+	base, err := minio.DefaultTransport(false)
+	if err != nil {
+		t.Fatalf("DefaultTransport() error = %v", err)
+	}
+	rt := &countingTransport{base: base, counts: make(map[string]int)}
+	client, err := minio.New(testEndpoint, &minio.Options{
+		Creds:     credentials.NewStaticV4("minioadmin", "minioadmin", ""),
+		Transport: rt,
+	})
+	if err != nil {
+		t.Fatalf("minio.New() error = %v", err)
+	}
+	fsys := &s3FS{client: client, bucket: "test-bucket"}
+	ctx := t.Context()
+
+	data := []byte("hello")
+	rt.reset()
+	if err := fs.WriteFile(ctx, fsys, "single/hello.txt", data); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	t.Cleanup(func() { _ = fs.Remove(ctx, fsys, "single/hello.txt") })
+	counts := rt.reset()
+	if want := map[string]int{"PUT": 1}; !maps.Equal(counts, want) {
+		t.Errorf("WriteFile() requests = %v, want %v", counts, want)
+	}
+	info, err := client.StatObject(
+		ctx, "test-bucket", "single/hello.txt", minio.StatObjectOptions{},
+	)
+	if err != nil {
+		t.Fatalf("StatObject() error = %v", err)
+	}
+	if want := fmt.Sprintf("%x", md5.Sum(data)); info.ETag != want {
+		t.Errorf("ETag = %q, want MD5 %q", info.ETag, want)
+	}
+}
+
+// rangeTransport records the Range header of each GET sent through it.
+type rangeTransport struct {
+	base http.RoundTripper
+
+	mu     sync.Mutex
+	ranges []string
+}
+
+func (t *rangeTransport) RoundTrip(
+	req *http.Request,
+) (*http.Response, error) {
+	if req.Method == http.MethodGet {
+		t.mu.Lock()
+		t.ranges = append(t.ranges, req.Header.Get("Range"))
+		t.mu.Unlock()
+	}
+	return t.base.RoundTrip(req)
+}
+
+func TestOpenRange(t *testing.T) {
+	if testEndpoint == "" {
+		t.Skip("MinIO not available")
+	}
+
+	base, err := minio.DefaultTransport(false)
+	if err != nil {
+		t.Fatalf("DefaultTransport() error = %v", err)
+	}
+	rt := &rangeTransport{base: base}
+	client, err := minio.New(testEndpoint, &minio.Options{
+		Creds:     credentials.NewStaticV4("minioadmin", "minioadmin", ""),
+		Transport: rt,
+	})
+	if err != nil {
+		t.Fatalf("minio.New() error = %v", err)
+	}
+	fsys := &s3FS{client: client, bucket: "test-bucket"}
+	ctx := t.Context()
+
+	data := make([]byte, 4<<20)
+	for i := range data {
+		data[i] = byte(i % 251)
+	}
+	name := "range/large.bin"
+	if err := fs.WriteFile(ctx, fsys, name, data); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	t.Cleanup(func() { _ = fs.Remove(ctx, fsys, name) })
+	rt.mu.Lock()
+	rt.ranges = nil
+	rt.mu.Unlock()
+
+	const off, n = 2 << 20, 1000
+	r, err := fs.OpenRange(ctx, fsys, name, off, n)
+	if err != nil {
+		t.Fatalf("OpenRange() error = %v", err)
+	}
+	defer r.Close()
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	if !bytes.Equal(got, data[off:off+n]) {
+		t.Errorf(
+			"ReadAll() = %d bytes, want data[%d:%d]", len(got), off, off+n,
+		)
+	}
+
+	rs, ok := r.(io.ReadSeeker)
+	if !ok {
+		t.Fatalf("OpenRange() = %T, want io.ReadSeeker", r)
+	}
+	if pos, err := rs.Seek(-10, io.SeekEnd); err != nil || pos != n-10 {
+		t.Fatalf("Seek(-10, SeekEnd) = %d, %v, want %d", pos, err, n-10)
+	}
+	got, err = io.ReadAll(rs)
+	if err != nil {
+		t.Fatalf("ReadAll() after Seek error = %v", err)
+	}
+	if want := data[off+n-10 : off+n]; !bytes.Equal(got, want) {
+		t.Errorf("ReadAll() after Seek = %v, want %v", got, want)
+	}
+
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	want := []string{
+		fmt.Sprintf("bytes=%d-%d", off, off+n-1),
+		fmt.Sprintf("bytes=%d-%d", off+n-10, off+n-1),
+	}
+	if !slices.Equal(rt.ranges, want) {
+		t.Errorf("GET ranges = %q, want %q", rt.ranges, want)
+	}
+}
+
+func TestCopyServerSide(t *testing.T) {
+	if testEndpoint == "" {
+		t.Skip("MinIO not available")
+	}
+
+	base, err := minio.DefaultTransport(false)
+	if err != nil {
+		t.Fatalf("DefaultTransport() error = %v", err)
+	}
+	rt := &countingTransport{base: base, counts: make(map[string]int)}
+	client, err := minio.New(testEndpoint, &minio.Options{
+		Creds:     credentials.NewStaticV4("minioadmin", "minioadmin", ""),
+		Transport: rt,
+	})
+	if err != nil {
+		t.Fatalf("minio.New() error = %v", err)
+	}
+	fsys := &s3FS{client: client, bucket: "test-bucket"}
+	ctx := t.Context()
+
+	src, dst := "copy/src.txt", "copy/nested/dst.txt"
+	if err := fs.WriteFile(ctx, fsys, src, []byte("payload")); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	t.Cleanup(func() {
+		_ = fs.Remove(ctx, fsys, src)
+		_ = fs.Remove(ctx, fsys, dst)
+	})
+
+	rt.reset()
+	if err := fs.Copy(ctx, fsys, src, dst); err != nil {
+		t.Fatalf("Copy() error = %v", err)
+	}
+	// CopyObject is a single PUT; the data is never downloaded.
+	counts := rt.reset()
+	if want := map[string]int{"PUT": 1}; !maps.Equal(counts, want) {
+		t.Errorf("Copy() requests = %v, want %v", counts, want)
+	}
+
+	data, err := fs.ReadFile(ctx, fsys, dst)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if got, want := string(data), "payload"; got != want {
+		t.Errorf("ReadFile() = %q, want %q", got, want)
+	}
+}
+
+func TestChangeToken(t *testing.T) {
+	if testEndpoint == "" {
+		t.Skip("MinIO not available")
+	}
+
+	fsys, err := New(
+		testEndpoint, "test-bucket", "minioadmin", "minioadmin", false,
+	)
+	if err != nil {
+		t.Fatalf("Failed to create S3 filesystem: %v", err)
+	}
+	ctx := t.Context()
+
+	name := "token/file.txt"
+	t.Cleanup(func() { _ = fs.Remove(ctx, fsys, name) })
+	entryToken := func() string {
+		t.Helper()
+		for e, err := range fs.ReadDir(ctx, fsys, "token") {
+			if err != nil {
+				t.Fatalf("ReadDir() error = %v", err)
+			}
+			info, err := e.Info()
+			if err != nil {
+				t.Fatalf("Info() error = %v", err)
+			}
+			return fs.ChangeToken(info)
+		}
+		t.Fatal("ReadDir() yielded no entries")
+		return ""
+	}
+
+	var tokens []string
+	for _, data := range []string{"first", "second"} {
+		if err := fs.WriteFile(ctx, fsys, name, []byte(data)); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+		info, err := fs.Stat(ctx, fsys, name)
+		if err != nil {
+			t.Fatalf("Stat() error = %v", err)
+		}
+		stat, entry := fs.ChangeToken(info), entryToken()
+		if stat == "" || entry != stat {
+			t.Errorf(
+				"ChangeToken() = %q from Stat, %q from ReadDir",
+				stat, entry,
+			)
+		}
+		tokens = append(tokens, stat)
+	}
+	if tokens[0] == tokens[1] {
+		t.Errorf("ChangeToken() = %q after modification", tokens[1])
+	}
+}
+
+func TestReadDirOrder(t *testing.T) {
+	if testEndpoint == "" {
+		t.Skip("MinIO not available")
+	}
+
+	fsys, err := New(
+		testEndpoint, "test-bucket", "minioadmin", "minioadmin", false,
+	)
+	if err != nil {
+		t.Fatalf("Failed to create S3 filesystem: %v", err)
+	}
+	ctx := t.Context()
+
+	for _, name := range []string{
+		"order/b/file.txt", "order/a.txt", "order/c/file.txt",
+	} {
+		if err := fs.WriteFile(ctx, fsys, name, []byte(name)); err != nil {
+			t.Fatalf("WriteFile(%q) error = %v", name, err)
+		}
+		t.Cleanup(func() { _ = fs.Remove(ctx, fsys, name) })
+	}
+
+	var got []string
+	for e, err := range fs.ReadDir(ctx, fsys, "order") {
+		if err != nil {
+			t.Fatalf("ReadDir() error = %v", err)
+		}
+		got = append(got, e.Name())
+	}
+	if want := []string{"a.txt", "b", "c"}; !slices.Equal(got, want) {
+		t.Errorf("ReadDir() = %v, want %v", got, want)
+	}
+}
+
 // setupMinIO starts a MinIO container and returns the endpoint.
 // Cleanup is registered with defers.Add().
 func setupMinIO() (string, error) {
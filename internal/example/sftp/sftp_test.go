@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"runtime"
+	"strings"
 	"testing"
 	"time"
 
@@ -63,6 +64,107 @@ func TestSFTPFS(t *testing.T) {
 	fstest.TestFS(ctx, t, fsys)
 }
 
+func TestConnectTimeout(t *testing.T) {
+	if testAddr == "" {
+		t.Skip("SFTP not available")
+	}
+
+	fsys, err := New(
+		testAddr, "testuser", "testpass",
+		WithConnectTimeout(30*time.Second),
+	)
+	if err != nil {
+		t.Fatalf("New(WithConnectTimeout(30s)) error = %v", err)
+	}
+	_ = fs.Close(fsys)
+
+	start := time.Now()
+	_, err = New(
+		testAddr, "testuser", "testpass",
+		WithConnectTimeout(time.Nanosecond),
+	)
+	if err == nil {
+		t.Fatal("New(WithConnectTimeout(1ns)) error = nil, want timeout")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("New(WithConnectTimeout(1ns)) took %v, want fast failure",
+			elapsed)
+	}
+}
+
+func TestCreateModeAtomic(t *testing.T) {
+	if testAddr == "" {
+		t.Skip("SFTP not available")
+	}
+
+	fsys, err := New(testAddr, "testuser", "testpass")
+	if err != nil {
+		t.Fatalf("Failed to create SFTP filesystem: %v", err)
+	}
+	t.Cleanup(func() { _ = fs.Close(fsys) })
+	fsys.(*sftpFS).SetBasePath("upload")
+	ctx := fs.WithFileMode(t.Context(), 0600)
+
+	dir := "atomic"
+	if err := fs.MkdirAll(ctx, fsys, dir); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	t.Cleanup(func() { _ = fs.RemoveAll(ctx, fsys, dir) })
+
+	// Watch the directory while files are created, and record any file
+	// seen under its final name with a mode other than 0600.
+	done := make(chan struct{})
+	seen := make(chan string, 1)
+	go func() {
+		defer close(seen)
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			for e, err := range fs.ReadDir(ctx, fsys, dir) {
+				if err != nil || strings.HasPrefix(e.Name(), ".") {
+					continue
+				}
+				info, err := e.Info()
+				if err == nil && info.Mode().Perm() != 0600 {
+					seen <- fmt.Sprintf("%s: %v", e.Name(), info.Mode())
+					return
+				}
+			}
+		}
+	}()
+	for i := range 50 {
+		name := fmt.Sprintf("%s/file%d.txt", dir, i)
+		if err := fs.WriteFile(ctx, fsys, name, []byte("data")); err != nil {
+			t.Fatalf("WriteFile(%q) error = %v", name, err)
+		}
+	}
+	close(done)
+	if s, ok := <-seen; ok {
+		t.Errorf("observed file with wrong mode: %s", s)
+	}
+
+	var names []string
+	for e, err := range fs.ReadDir(ctx, fsys, dir) {
+		if err != nil {
+			t.Fatalf("ReadDir() error = %v", err)
+		}
+		names = append(names, e.Name())
+		info, err := e.Info()
+		if err != nil {
+			t.Fatalf("Info(%q) error = %v", e.Name(), err)
+		}
+		if got := info.Mode().Perm(); got != 0600 {
+			t.Errorf("Mode(%q) = %v, want 0600", e.Name(), got)
+		}
+	}
+	if len(names) != 50 {
+		t.Errorf("ReadDir() = %q, want 50 files and no temporaries", names)
+	}
+}
+
 // setupSFTP starts an SFTP server container and returns the address.
 // Cleanup is registered with defers.Add().
 func setupSFTP() (string, error) {
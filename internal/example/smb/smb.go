@@ -28,19 +28,79 @@ type smbFS struct {
 	share   *smb2.Share
 }
 
+// An Option configures the SMB connection.
+type Option func(*options)
+
+type options struct {
+	connectTimeout time.Duration
+	keepAlive      time.Duration
+	idleTimeout    time.Duration
+	requireSigning bool
+}
+
+// WithConnectTimeout sets the maximum time to establish the connection,
+// including SMB negotiation and authentication. The default is 10 seconds.
+func WithConnectTimeout(d time.Duration) Option {
+	return func(o *options) { o.connectTimeout = d }
+}
+
+// WithKeepAlive sets the interval between TCP keepalive probes, so that
+// idle connections are not dropped by NAT gateways and dead peers are
+// detected. The default is 15 seconds. A negative duration disables
+// keepalives.
+func WithKeepAlive(d time.Duration) Option {
+	return func(o *options) { o.keepAlive = d }
+}
+
+// WithIdleTimeout closes the connection once nothing has been received
+// from the server for d. SMB has no keepalive request of its own, so an
+// idle timeout also closes healthy connections that go unused for d; it
+// suits short-lived clients that would rather fail than hang. The default
+// is 0, which disables the idle timeout.
+func WithIdleTimeout(d time.Duration) Option {
+	return func(o *options) { o.idleTimeout = d }
+}
+
+// WithRequireSigning requires the server to sign SMB messages. SMB3
+// encryption is negotiated by the protocol itself when the server requires
+// it; there is no separate TLS layer to configure.
+func WithRequireSigning() Option {
+	return func(o *options) { o.requireSigning = true }
+}
+
 // New creates a new SMB filesystem client.
 //
 // addr: SMB server address (e.g., "localhost:445")
 // shareName: Share name to connect to (e.g., "public")
 // user: Username for authentication
 // password: Password for authentication
-func New(addr, shareName, user, password string) (fs.FS, error) {
-	conn, err := net.Dial("tcp", addr)
+func New(
+	addr, shareName, user, password string, opts ...Option,
+) (fs.FS, error) {
+	o := options{
+		connectTimeout: 10 * time.Second,
+		keepAlive:      15 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	nd := net.Dialer{Timeout: o.connectTimeout, KeepAlive: o.keepAlive}
+	conn, err := nd.Dial("tcp", addr)
 	if err != nil {
 		return nil, err
 	}
+	if o.connectTimeout > 0 {
+		_ = conn.SetDeadline(time.Now().Add(o.connectTimeout))
+	}
+	if o.idleTimeout > 0 {
+		conn = &idleConn{Conn: conn, timeout: o.idleTimeout}
+	}
 
 	d := &smb2.Dialer{
+		Negotiator: smb2.Negotiator{
+			RequireMessageSigning: o.requireSigning,
+		},
 		Initiator: &smb2.NTLMInitiator{
 			User:     user,
 			Password: password,
@@ -58,6 +118,7 @@ func New(addr, shareName, user, password string) (fs.FS, error) {
 		_ = session.Logoff()
 		return nil, err
 	}
+	_ = conn.SetDeadline(time.Time{})
 
 	return &smbFS{
 		session: session,
@@ -65,6 +126,19 @@ func New(addr, shareName, user, password string) (fs.FS, error) {
 	}, nil
 }
 
+// idleConn fails a read once nothing has arrived for timeout.
+type idleConn struct {
+	net.Conn
+	timeout time.Duration
+}
+
+func (c *idleConn) Read(p []byte) (int, error) {
+	if err := c.Conn.SetReadDeadline(time.Now().Add(c.timeout)); err != nil {
+		return 0, err
+	}
+	return c.Conn.Read(p)
+}
+
 // Close closes the SMB share and session.
 func (f *smbFS) Close() error {
 	if err := f.share.Umount(); err != nil {
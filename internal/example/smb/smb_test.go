@@ -55,6 +55,34 @@ func TestSMBFS(t *testing.T) {
 	fstest.TestFS(ctx, t, fsys)
 }
 
+func TestConnectTimeout(t *testing.T) {
+	if testAddr == "" {
+		t.Skip("SMB not available")
+	}
+
+	fsys, err := New(
+		testAddr, "public", "testuser", "testpass",
+		WithConnectTimeout(30*time.Second),
+	)
+	if err != nil {
+		t.Fatalf("New(WithConnectTimeout(30s)) error = %v", err)
+	}
+	_ = fs.Close(fsys)
+
+	start := time.Now()
+	_, err = New(
+		testAddr, "public", "testuser", "testpass",
+		WithConnectTimeout(time.Nanosecond),
+	)
+	if err == nil {
+		t.Fatal("New(WithConnectTimeout(1ns)) error = nil, want timeout")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("New(WithConnectTimeout(1ns)) took %v, want fast failure",
+			elapsed)
+	}
+}
+
 // setupSMB starts a Samba server container and returns the address.
 // Cleanup is registered with defers.Add().
 func setupSMB() (string, error) {
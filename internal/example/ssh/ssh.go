@@ -10,8 +10,11 @@ package ssh
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"iter"
+	"math/rand/v2"
+	"net"
 	"os"
 	"path"
 	"time"
@@ -29,18 +32,70 @@ type sshFS struct {
 	prefix string
 }
 
+// An Option configures the SSH connection.
+type Option func(*options)
+
+type options struct {
+	config      *ssh.ClientConfig
+	keepAlive   time.Duration
+	idleTimeout time.Duration
+}
+
+// WithConnectTimeout sets the maximum time to establish the connection,
+// including the SSH handshake. The default is 10 seconds.
+func WithConnectTimeout(d time.Duration) Option {
+	return func(o *options) { o.config.Timeout = d }
+}
+
+// WithKeepAlive sets the interval between keepalive requests sent to the
+// server, both as TCP keepalive probes and as SSH keepalive@openssh.com
+// requests, so that idle connections are not dropped by the server or by
+// NAT gateways along the way. The default is 15 seconds. A negative
+// duration disables keepalives.
+func WithKeepAlive(d time.Duration) Option {
+	return func(o *options) { o.keepAlive = d }
+}
+
+// WithIdleTimeout closes the connection once nothing has been received
+// from the server for d, so that operations on a dead connection fail
+// instead of hanging. Replies to keepalive requests count as traffic, so
+// d should be longer than the keepalive interval. The default is 0, which
+// disables the idle timeout.
+func WithIdleTimeout(d time.Duration) Option {
+	return func(o *options) { o.idleTimeout = d }
+}
+
+// WithSSHConfig sets the cipher, key exchange, and MAC preferences.
+func WithSSHConfig(config ssh.Config) Option {
+	return func(o *options) { o.config.Config = config }
+}
+
+// WithHostKeyCallback sets the host key verification callback. By default,
+// host keys are not verified.
+func WithHostKeyCallback(cb ssh.HostKeyCallback) Option {
+	return func(o *options) { o.config.HostKeyCallback = cb }
+}
+
 // New creates a new SSHFS instance connected to the given SSH server.
-func New(addr, user, password string) (fs.FS, error) {
-	config := &ssh.ClientConfig{
-		User: user,
-		Auth: []ssh.AuthMethod{
-			ssh.Password(password),
+func New(
+	addr, user, password string, opts ...Option,
+) (fs.FS, error) {
+	o := options{
+		config: &ssh.ClientConfig{
+			User: user,
+			Auth: []ssh.AuthMethod{
+				ssh.Password(password),
+			},
+			HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+			Timeout:         10 * time.Second,
 		},
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
-		Timeout:         10 * time.Second,
+		keepAlive: 15 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(&o)
 	}
 
-	conn, err := ssh.Dial("tcp", addr, config)
+	conn, err := dial(addr, o)
 	if err != nil {
 		return nil, err
 	}
@@ -58,6 +113,69 @@ func New(addr, user, password string) (fs.FS, error) {
 	}, nil
 }
 
+// dial connects to addr and completes the SSH handshake within the
+// connect timeout, then starts sending keepalive requests.
+func dial(addr string, o options) (*ssh.Client, error) {
+	d := net.Dialer{Timeout: o.config.Timeout, KeepAlive: o.keepAlive}
+	nc, err := d.Dial("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	if o.config.Timeout > 0 {
+		_ = nc.SetDeadline(time.Now().Add(o.config.Timeout))
+	}
+	if o.idleTimeout > 0 {
+		nc = &idleConn{Conn: nc, timeout: o.idleTimeout}
+	}
+	c, chans, reqs, err := ssh.NewClientConn(nc, addr, o.config)
+	if err != nil {
+		_ = nc.Close()
+		return nil, err
+	}
+	_ = nc.SetDeadline(time.Time{})
+	client := ssh.NewClient(c, chans, reqs)
+	if o.keepAlive > 0 {
+		go keepAlive(client, o.keepAlive)
+	}
+	return client, nil
+}
+
+// keepAlive sends a keepalive request every interval until the connection
+// is closed.
+func keepAlive(client *ssh.Client, interval time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		_ = client.Wait()
+		close(done)
+	}()
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-t.C:
+			_, _, err := client.SendRequest("keepalive@openssh.com", true, nil)
+			if err != nil {
+				return
+			}
+		}
+	}
+}
+
+// idleConn fails a read once nothing has arrived for timeout.
+type idleConn struct {
+	net.Conn
+	timeout time.Duration
+}
+
+func (c *idleConn) Read(p []byte) (int, error) {
+	if err := c.Conn.SetReadDeadline(time.Now().Add(c.timeout)); err != nil {
+		return 0, err
+	}
+	return c.Conn.Read(p)
+}
+
 // SetPrefix sets a path prefix for all operations.
 // This is useful when the SFTP server restricts access to a subdirectory.
 func (f *sshFS) SetPrefix(prefix string) {
@@ -115,19 +233,62 @@ func (f *sshFS) Create(
 		}
 	}
 
-	file, err := f.client.OpenFile(
-		f.fullPath(ctx, name), os.O_WRONLY|os.O_CREATE|os.O_TRUNC,
-	)
+	mode := os.FileMode(fs.FileMode(ctx))
+	file, err := f.createFile(f.fullPath(ctx, name), mode)
 	if err != nil {
 		return nil, convertError("create", name, err)
 	}
 
-	if err := file.Chmod(os.FileMode(fs.FileMode(ctx))); err != nil {
+	return file, nil
+}
+
+// createFile opens name for writing with mode, truncating it if it exists.
+//
+// SFTP can set attributes when a file is opened, but pkg/sftp does not
+// expose them, so a new file would briefly have the server's default mode
+// before a Chmod. Instead, a new file is created under a temporary name,
+// given its mode, and renamed into place. An SFTP rename never replaces an
+// existing file, so if name appears in the meantime, it is opened instead.
+func (f *sshFS) createFile(
+	name string, mode os.FileMode,
+) (*sftp.File, error) {
+	for {
+		file, err := f.client.OpenFile(name, os.O_WRONLY|os.O_TRUNC)
+		if err == nil {
+			if err := file.Chmod(mode); err != nil {
+				_ = file.Close()
+				return nil, err
+			}
+			return file, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+
+		tmp := path.Join(
+			path.Dir(name),
+			fmt.Sprintf(".%s.%016x.tmp", path.Base(name), rand.Uint64()),
+		)
+		file, err = f.client.OpenFile(
+			tmp, os.O_WRONLY|os.O_CREATE|os.O_EXCL,
+		)
+		if err != nil {
+			return nil, err
+		}
+		err = file.Chmod(mode)
+		if err == nil {
+			err = f.client.Rename(tmp, name)
+			if err == nil {
+				return file, nil
+			}
+		}
 		_ = file.Close()
-		return nil, convertError("chmod", name, err)
+		_ = f.client.Remove(tmp)
+		if _, statErr := f.client.Lstat(name); statErr != nil {
+			return nil, err
+		}
+		// Another client created name first; truncate theirs instead.
 	}
-
-	return file, nil
 }
 
 // Append implements fs.AppendFS.
@@ -352,6 +513,26 @@ func (f *sshFS) Symlink(
 	return nil
 }
 
+// Link implements fs.LinkFS.
+func (f *sshFS) Link(
+	ctx context.Context, oldname, newname string,
+) error {
+	if oldname == "" || newname == "" {
+		return &fs.PathError{
+			Op:   "link",
+			Path: newname,
+			Err:  fs.ErrInvalid,
+		}
+	}
+
+	err := f.client.Link(f.fullPath(ctx, oldname), f.fullPath(ctx, newname))
+	if err != nil {
+		return convertError("link", newname, err)
+	}
+
+	return nil
+}
+
 // ReadLink implements fs.ReadLinkFS.
 func (f *sshFS) ReadLink(ctx context.Context, name string) (string, error) {
 	if name == "" {
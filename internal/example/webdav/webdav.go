@@ -13,6 +13,8 @@ import (
 	"fmt"
 	"io"
 	"iter"
+	"net"
+	"net/http"
 	"path"
 	"strings"
 	"time"
@@ -27,13 +29,72 @@ type webdavFS struct {
 	client *gowebdav.Client
 }
 
+// An Option configures a WebDAV filesystem.
+type Option func(*options)
+
+type options struct {
+	transport      http.RoundTripper
+	connectTimeout time.Duration
+	keepAlive      time.Duration
+	idleTimeout    time.Duration
+}
+
+// WithTransport sets the HTTP transport used for requests, so that callers
+// control proxies, TLS, and instrumentation. A transport set with
+// WithTransport is used as is, so the timeout options below do not apply
+// to it.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(o *options) { o.transport = rt }
+}
+
+// WithConnectTimeout sets the maximum time to establish a connection,
+// including the TLS handshake. The default is 10 seconds.
+func WithConnectTimeout(d time.Duration) Option {
+	return func(o *options) { o.connectTimeout = d }
+}
+
+// WithKeepAlive sets the interval between TCP keepalive probes on open
+// connections. The default is 15 seconds. A negative duration disables
+// keepalives.
+func WithKeepAlive(d time.Duration) Option {
+	return func(o *options) { o.keepAlive = d }
+}
+
+// WithIdleTimeout sets how long an unused connection is kept for reuse.
+// Closing idle connections before the server or a proxy drops them avoids
+// failed requests on stale connections. The default is 90 seconds.
+func WithIdleTimeout(d time.Duration) Option {
+	return func(o *options) { o.idleTimeout = d }
+}
+
 // New creates a new WebDAV filesystem.
 //
 // url: WebDAV server URL (e.g., "http://localhost:8080/webdav")
 // user: Username for authentication
 // password: Password for authentication
-func New(url, user, password string) (fs.FS, error) {
+func New(url, user, password string, opts ...Option) (fs.FS, error) {
+	o := options{
+		connectTimeout: 10 * time.Second,
+		keepAlive:      15 * time.Second,
+		idleTimeout:    90 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.transport == nil {
+		t := http.DefaultTransport.(*http.Transport).Clone()
+		d := &net.Dialer{Timeout: o.connectTimeout, KeepAlive: o.keepAlive}
+		t.DialContext = d.DialContext
+		t.TLSHandshakeTimeout = o.connectTimeout
+		t.IdleConnTimeout = o.idleTimeout
+		o.transport = t
+	}
+
 	client := gowebdav.NewClient(url, user, password)
+	client.SetTransport(o.transport)
+	// gowebdav requests do not carry a context, so a User-Agent from
+	// fs.WithUserAgent cannot be applied per operation.
+	client.SetHeader("User-Agent", fs.DefaultUserAgent)
 
 	// Test connection
 	if err := client.Connect(); err != nil {
@@ -71,6 +132,9 @@ func (f *webdavFS) Open(
 }
 
 // Create implements fs.CreateFS
+//
+// The encoding from fs.WithContentEncoding is not sent: gowebdav writes
+// with the client's fixed headers, so it cannot vary per upload.
 func (f *webdavFS) Create(
 	ctx context.Context, name string,
 ) (io.WriteCloser, error) {
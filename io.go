@@ -5,6 +5,11 @@ import "io"
 // Pather is the interface that wraps the Path method.
 //
 // Path returns the native filesystem path for this resource.
+//
+// Readers and writers returned by a backend may implement Pather to report
+// the path or key they actually refer to, such as a normalized object key.
+// The Path method of streams returned by [Open], [Create], and [Append]
+// reports that path when available.
 type Pather interface {
 	Path() string
 }
@@ -29,16 +34,57 @@ type pather string
 
 func (p pather) Path() string { return string(p) }
 
-// readPathCloser composes an io.ReadCloser with a path.
+// readPathCloser composes an io.ReadCloser with a path. If rc reports its
+// own path, that path is used instead. If rc implements io.Seeker or
+// io.WriterTo, so does the result, so that callers can seek and io.Copy can
+// take zero-copy paths such as sendfile.
 func readPathCloser(rc io.ReadCloser, p string) ReadPathCloser {
+	if pr, ok := rc.(Pather); ok && pr.Path() != "" {
+		p = pr.Path()
+	}
+	s, isSeeker := rc.(io.Seeker)
+	wt, isWriterTo := rc.(io.WriterTo)
+	switch {
+	case isSeeker && isWriterTo:
+		return struct {
+			io.ReadCloser
+			io.Seeker
+			io.WriterTo
+			pather
+		}{rc, s, wt, pather(p)}
+	case isSeeker:
+		return struct {
+			io.ReadCloser
+			io.Seeker
+			pather
+		}{rc, s, pather(p)}
+	case isWriterTo:
+		return struct {
+			io.ReadCloser
+			io.WriterTo
+			pather
+		}{rc, wt, pather(p)}
+	}
 	return struct {
 		io.ReadCloser
 		pather
 	}{rc, pather(p)}
 }
 
-// writePathCloser composes an io.WriteCloser with a path.
+// writePathCloser composes an io.WriteCloser with a path. If wc reports its
+// own path, that path is used instead. If wc implements io.ReaderFrom, so
+// does the result.
 func writePathCloser(wc io.WriteCloser, p string) WritePathCloser {
+	if pw, ok := wc.(Pather); ok && pw.Path() != "" {
+		p = pw.Path()
+	}
+	if rf, ok := wc.(io.ReaderFrom); ok {
+		return struct {
+			io.WriteCloser
+			io.ReaderFrom
+			pather
+		}{wc, rf, pather(p)}
+	}
 	return struct {
 		io.WriteCloser
 		pather
@@ -0,0 +1,208 @@
+package fs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"iter"
+	stdpath "path"
+	"slices"
+	"strings"
+	"time"
+)
+
+// AsIOFSDirEntry returns e as an [io/fs.DirEntry].
+//
+// The returned entry passes Name, IsDir, Type, and Info through to e. The
+// Path method is not exposed, so the result behaves like an entry returned
+// by [io/fs.ReadDir].
+func AsIOFSDirEntry(e DirEntry) fs.DirEntry {
+	if e == nil {
+		return nil
+	}
+	return ioDirEntry{e}
+}
+
+// FromIOFSDirEntry returns e as a [DirEntry].
+//
+// The returned entry passes Name, IsDir, Type, and Info through to e.
+// Path() returns an empty string, matching entries returned by [ReadDir].
+func FromIOFSDirEntry(e fs.DirEntry) DirEntry {
+	if e == nil {
+		return nil
+	}
+	return dirEntry{e}
+}
+
+// ioDirEntry hides the Path method of a DirEntry.
+type ioDirEntry struct{ e DirEntry }
+
+func (de ioDirEntry) Name() string               { return de.e.Name() }
+func (de ioDirEntry) IsDir() bool                { return de.e.IsDir() }
+func (de ioDirEntry) Type() fs.FileMode          { return de.e.Type() }
+func (de ioDirEntry) Info() (fs.FileInfo, error) { return de.e.Info() }
+
+func (de ioDirEntry) String() string {
+	return fs.FormatDirEntry(de)
+}
+
+// dirEntry adds an empty Path method to an io/fs.DirEntry.
+type dirEntry struct{ fs.DirEntry }
+
+func (dirEntry) Path() string { return "" }
+
+// ToIOFS returns fsys as an [io/fs.FS], for packages such as net/http,
+// html/template, and testing/fstest that consume the standard interface.
+// Every operation runs with ctx.
+//
+// Opening a directory returns an [io/fs.ReadDirFile] whose ReadDir method
+// reads entries lazily from [ReadDir], rather than the tar stream that
+// [Open] returns for a directory, so that http.FileServer can list it.
+// Directories are recognized with [Stat], so fsys must implement [StatFS]
+// for them to open this way. Opened files implement [io.Seeker] only when
+// the underlying stream does. Without it, http.FileServer can still serve a
+// file whose content type follows from its extension, but not a range of
+// one.
+//
+// The result also implements [io/fs.StatFS] and [io/fs.ReadDirFS].
+func ToIOFS(ctx context.Context, fsys FS) fs.FS {
+	return ioFS{ctx, fsys}
+}
+
+type ioFS struct {
+	ctx  context.Context
+	fsys FS
+}
+
+func (f ioFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &PathError{Op: "open", Path: name, Err: ErrInvalid}
+	}
+	info, err := Stat(f.ctx, f.fsys, name)
+	if err != nil && !errors.Is(err, ErrUnsupported) {
+		return nil, err
+	}
+	if info != nil && info.IsDir() {
+		next, stop := iter.Pull2(ReadDir(f.ctx, f.fsys, name))
+		return &ioDir{
+			ctx: f.ctx, name: name, info: info, next: next, stop: stop,
+		}, nil
+	}
+	r, err := Open(f.ctx, f.fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	if info == nil {
+		info = ioFileInfo(stdpath.Base(name))
+	}
+	file := &ioFile{ReadCloser: r, info: info}
+	if s, ok := r.(io.Seeker); ok {
+		return ioSeekFile{file, s}, nil
+	}
+	return file, nil
+}
+
+func (f ioFS) Stat(name string) (FileInfo, error) {
+	if !fs.ValidPath(name) {
+		return nil, &PathError{Op: "stat", Path: name, Err: ErrInvalid}
+	}
+	return Stat(f.ctx, f.fsys, name)
+}
+
+func (f ioFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &PathError{Op: "readdir", Path: name, Err: ErrInvalid}
+	}
+	var entries []fs.DirEntry
+	for e, err := range ReadDir(f.ctx, f.fsys, name) {
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, statEntry{ioDirEntry{e}, f.ctx})
+	}
+	slices.SortFunc(entries, func(a, b fs.DirEntry) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+	return entries, nil
+}
+
+// ioFile is a file opened through ToIOFS.
+type ioFile struct {
+	io.ReadCloser
+	info FileInfo
+}
+
+func (f *ioFile) Stat() (FileInfo, error) { return f.info, nil }
+
+// ioSeekFile is an ioFile whose stream can seek. Only these files implement
+// io.Seeker: net/http seeks any file that does, and fails the request if
+// the seek does.
+type ioSeekFile struct {
+	*ioFile
+	io.Seeker
+}
+
+// ioDir is a directory opened through ToIOFS.
+type ioDir struct {
+	ctx  context.Context
+	name string
+	info FileInfo
+	next func() (DirEntry, error, bool)
+	stop func()
+}
+
+func (d *ioDir) Stat() (FileInfo, error) { return d.info, nil }
+
+func (d *ioDir) Read([]byte) (int, error) {
+	return 0, &PathError{Op: "read", Path: d.name, Err: ErrIsDir}
+}
+
+func (d *ioDir) Close() error {
+	d.stop()
+	return nil
+}
+
+// ReadDir follows the contract of [io/fs.ReadDirFile].
+func (d *ioDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	var entries []fs.DirEntry
+	for n <= 0 || len(entries) < n {
+		e, err, ok := d.next()
+		if !ok {
+			break
+		}
+		if err != nil {
+			return entries, err
+		}
+		entries = append(entries, statEntry{ioDirEntry{e}, d.ctx})
+	}
+	if n > 0 && len(entries) == 0 {
+		return nil, io.EOF
+	}
+	return entries, nil
+}
+
+// statEntry normalizes the FileInfo of an entry as Stat does, so that it
+// matches the FileInfo of the opened file.
+type statEntry struct {
+	ioDirEntry
+	ctx context.Context
+}
+
+func (de statEntry) Info() (fs.FileInfo, error) {
+	info, err := de.e.Info()
+	if err != nil {
+		return nil, err
+	}
+	return normalizeInfo(de.ctx, info), nil
+}
+
+// ioFileInfo describes a file by name alone, for backends without StatFS.
+type ioFileInfo string
+
+func (fi ioFileInfo) Name() string       { return string(fi) }
+func (fi ioFileInfo) Size() int64        { return 0 }
+func (fi ioFileInfo) Mode() Mode         { return 0444 }
+func (fi ioFileInfo) ModTime() time.Time { return time.Time{} }
+func (fi ioFileInfo) IsDir() bool        { return false }
+func (fi ioFileInfo) Sys() any           { return nil }
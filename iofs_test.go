@@ -0,0 +1,216 @@
+package fs_test
+
+import (
+	"context"
+	"io"
+	iofs "io/fs"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"lesiw.io/fs"
+	"lesiw.io/fs/memfs"
+)
+
+func TestAsIOFSDirEntry(t *testing.T) {
+	ctx, fsys := context.Background(), memfs.New()
+
+	err := fs.WriteFile(ctx, fsys, "dir/file.txt", []byte("hi"))
+	if err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	got := make(map[string]iofs.DirEntry)
+	for e, err := range fs.ReadDir(ctx, fsys, "dir") {
+		if err != nil {
+			t.Fatalf("ReadDir() error = %v", err)
+		}
+		got[e.Name()] = fs.AsIOFSDirEntry(e)
+	}
+	for e, err := range fs.ReadDir(ctx, fsys, ".") {
+		if err != nil {
+			t.Fatalf("ReadDir() error = %v", err)
+		}
+		got[e.Name()] = fs.AsIOFSDirEntry(e)
+	}
+
+	file, dir := got["file.txt"], got["dir"]
+	if file == nil || dir == nil {
+		t.Fatalf("ReadDir() entries = %v, want file.txt and dir", got)
+	}
+	if _, ok := file.(fs.DirEntry); ok {
+		t.Errorf("AsIOFSDirEntry() exposes Path()")
+	}
+	if file.IsDir() || file.Type() != 0 {
+		t.Errorf("file IsDir() = %v, Type() = %v, want false, 0",
+			file.IsDir(), file.Type())
+	}
+	if !dir.IsDir() || dir.Type() != fs.ModeDir {
+		t.Errorf("dir IsDir() = %v, Type() = %v, want true, %v",
+			dir.IsDir(), dir.Type(), fs.ModeDir)
+	}
+	info, err := file.Info()
+	if err != nil {
+		t.Fatalf("Info() error = %v", err)
+	}
+	if info.Name() != "file.txt" || info.Size() != 2 {
+		t.Errorf("Info() = %q (%d bytes), want %q (2 bytes)",
+			info.Name(), info.Size(), "file.txt")
+	}
+}
+
+func TestFromIOFSDirEntry(t *testing.T) {
+	fsys := fstest.MapFS{
+		"dir/file.txt": &fstest.MapFile{Data: []byte("hi"), Mode: 0600},
+	}
+
+	entries, err := iofs.ReadDir(fsys, "dir")
+	if err != nil {
+		t.Fatalf("ReadDir() error = %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("ReadDir() = %d entries, want 1", len(entries))
+	}
+	file := fs.FromIOFSDirEntry(entries[0])
+	if got, want := file.Name(), "file.txt"; got != want {
+		t.Errorf("Name() = %q, want %q", got, want)
+	}
+	if file.IsDir() || file.Type() != 0 {
+		t.Errorf("IsDir() = %v, Type() = %v, want false, 0",
+			file.IsDir(), file.Type())
+	}
+	if got := file.Path(); got != "" {
+		t.Errorf("Path() = %q, want empty", got)
+	}
+	info, err := file.Info()
+	if err != nil {
+		t.Fatalf("Info() error = %v", err)
+	}
+	if info.Mode() != 0600 || info.Size() != 2 {
+		t.Errorf("Info() mode = %v, size = %d, want %v, 2",
+			info.Mode(), info.Size(), iofs.FileMode(0600))
+	}
+
+	entries, err = iofs.ReadDir(fsys, ".")
+	if err != nil {
+		t.Fatalf("ReadDir() error = %v", err)
+	}
+	dir := fs.FromIOFSDirEntry(entries[0])
+	if !dir.IsDir() || dir.Type() != fs.ModeDir {
+		t.Errorf("dir IsDir() = %v, Type() = %v, want true, %v",
+			dir.IsDir(), dir.Type(), fs.ModeDir)
+	}
+}
+
+func TestToIOFS(t *testing.T) {
+	ctx, fsys := context.Background(), memfs.New()
+	files := map[string]string{
+		"index.txt":        "index",
+		"dir/a.txt":        "a",
+		"dir/b.txt":        "b",
+		"dir/sub/deep.txt": "deep",
+	}
+	for name, data := range files {
+		if err := fs.WriteFile(ctx, fsys, name, []byte(data)); err != nil {
+			t.Fatalf("WriteFile(%q) error = %v", name, err)
+		}
+	}
+
+	err := fstest.TestFS(fs.ToIOFS(ctx, fsys),
+		"index.txt", "dir/a.txt", "dir/b.txt", "dir/sub/deep.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestToIOFSFileServer(t *testing.T) {
+	ctx, fsys := context.Background(), memfs.New()
+	for _, name := range []string{"dir/a.txt", "dir/b.txt"} {
+		if err := fs.WriteFile(ctx, fsys, name, []byte(name)); err != nil {
+			t.Fatalf("WriteFile(%q) error = %v", name, err)
+		}
+	}
+	srv := httptest.NewServer(http.FileServer(http.FS(fs.ToIOFS(ctx, fsys))))
+	t.Cleanup(srv.Close)
+
+	get := func(path string) string {
+		t.Helper()
+		resp, err := http.Get(srv.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s error = %v", path, err)
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("GET %s read error = %v", path, err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("GET %s status = %d, body %q", path, resp.StatusCode,
+				body)
+		}
+		return string(body)
+	}
+
+	listing := get("/dir/")
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if !strings.Contains(listing, `href="`+name+`"`) {
+			t.Errorf("GET /dir/ = %q, want link to %s", listing, name)
+		}
+	}
+	if got, want := get("/dir/a.txt"), "dir/a.txt"; got != want {
+		t.Errorf("GET /dir/a.txt = %q, want %q", got, want)
+	}
+}
+
+// noSeekFS is a filesystem whose readers cannot seek, as with object
+// storage.
+type noSeekFS struct{ fs.FS }
+
+func (f noSeekFS) Open(
+	ctx context.Context, name string,
+) (io.ReadCloser, error) {
+	r, err := f.FS.Open(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return struct{ io.ReadCloser }{r}, nil
+}
+
+func (f noSeekFS) Stat(ctx context.Context, name string) (fs.FileInfo, error) {
+	return fs.Stat(ctx, f.FS, name)
+}
+
+func TestToIOFSFileServerNoSeek(t *testing.T) {
+	ctx, mem := context.Background(), memfs.New()
+	if err := fs.WriteFile(ctx, mem, "a.txt", []byte("stream")); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	fsys := fs.ToIOFS(ctx, noSeekFS{mem})
+
+	f, err := fsys.Open("a.txt")
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if _, ok := f.(io.Seeker); ok {
+		t.Errorf("Open() = %T, want no io.Seeker", f)
+	}
+	_ = f.Close()
+
+	srv := httptest.NewServer(http.FileServer(http.FS(fsys)))
+	t.Cleanup(srv.Close)
+	resp, err := http.Get(srv.URL + "/a.txt")
+	if err != nil {
+		t.Fatalf("GET /a.txt error = %v", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("GET /a.txt read error = %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || string(body) != "stream" {
+		t.Errorf("GET /a.txt = %d %q, want %d %q",
+			resp.StatusCode, body, http.StatusOK, "stream")
+	}
+}
@@ -0,0 +1,509 @@
+package fs
+
+import (
+	"context"
+	"io"
+	"iter"
+	stdpath "path"
+	"strings"
+	"time"
+)
+
+// Jail returns a filesystem that forwards to fsys only the operations on
+// paths under one of the allowed prefixes, and fails every other operation
+// with [ErrPermission].
+//
+// Each path is cleaned before it is checked, so "data/../secret" is
+// checked as "secret", and a path that climbs out with ".." is never
+// allowed. Relative paths are resolved against [WorkDir] before they are
+// checked. Backslashes are treated as separators while checking. Allowed
+// paths are forwarded unchanged; unlike a subtree view, Jail does not
+// rewrite paths, and it may permit several unrelated roots. An allowed
+// prefix of "." permits every relative path that stays below the working
+// directory.
+//
+// Jail checks names, not files: it does not resolve symbolic links that
+// already exist inside an allowed tree. It does check the target of links
+// created through Symlink, and results from Glob are filtered to allowed
+// paths. Temporary files are created inside the jail by the fallbacks of
+// [Temp], not in the backend's own temporary location.
+//
+// Like [WithDeadlineWrapper], the wrapper implements every optional
+// interface, and operations that fsys does not implement natively run
+// through the package-level helpers.
+func Jail(fsys FS, allowed ...string) FS {
+	j := &jailFS{fsys: fsys}
+	for _, p := range allowed {
+		j.allowed = append(j.allowed, cleanJailPath(p))
+	}
+	return j
+}
+
+type jailFS struct {
+	fsys    FS
+	allowed []string
+}
+
+// cleanJailPath returns name in the form in which it is checked: cleaned,
+// with forward slashes, and without a leading "./" or trailing slash.
+func cleanJailPath(name string) string {
+	return stdpath.Clean(strings.ReplaceAll(name, `\`, "/"))
+}
+
+// allows reports whether name is under one of the allowed prefixes. A
+// relative name is resolved against the working directory of ctx first,
+// since that is the path the backend will use.
+func (j *jailFS) allows(ctx context.Context, name string) bool {
+	name = cleanJailPath(name)
+	if w := WorkDir(ctx); w != "" && !isAbsJailPath(name) {
+		name = cleanJailPath(stdpath.Join(w, name))
+	}
+	for _, p := range j.allowed {
+		if p == "." {
+			if !escapes(name) {
+				return true
+			}
+			continue
+		}
+		dir := strings.TrimSuffix(p, "/") + "/"
+		if name == p || strings.HasPrefix(name, dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// escapes reports whether the cleaned path name leaves the working
+// directory: by climbing out with "..", or by being absolute, including
+// paths with a Windows volume name.
+func escapes(name string) bool {
+	return name == ".." || strings.HasPrefix(name, "../") ||
+		isAbsJailPath(name)
+}
+
+// isAbsJailPath reports whether the cleaned path name is absolute,
+// including paths with a Windows volume name.
+func isAbsJailPath(name string) bool {
+	return stdpath.IsAbs(name) || len(name) >= 2 && name[1] == ':'
+}
+
+// check returns a PathError with ErrPermission if name is not allowed.
+func (j *jailFS) check(ctx context.Context, op, name string) error {
+	if j.allows(ctx, name) {
+		return nil
+	}
+	return &PathError{Op: op, Path: name, Err: ErrPermission}
+}
+
+// denied yields a single error, for iterators over disallowed paths.
+func denied[T any](err error) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		yield(zero, err)
+	}
+}
+
+func (j *jailFS) Open(
+	ctx context.Context, name string,
+) (io.ReadCloser, error) {
+	if err := j.check(ctx, "open", name); err != nil {
+		return nil, err
+	}
+	return Open(ctx, j.fsys, name)
+}
+
+func (j *jailFS) OpenDir(
+	ctx context.Context, dir string,
+) (io.ReadCloser, error) {
+	if err := j.check(ctx, "opendir", dir); err != nil {
+		return nil, err
+	}
+	dfs, ok := j.fsys.(DirFS)
+	if !ok {
+		return nil, &PathError{Op: "opendir", Path: dir, Err: ErrUnsupported}
+	}
+	return dfs.OpenDir(ctx, dir)
+}
+
+func (j *jailFS) OpenRange(
+	ctx context.Context, name string, offset, length int64,
+) (io.ReadCloser, error) {
+	if err := j.check(ctx, "open", name); err != nil {
+		return nil, err
+	}
+	return OpenRange(ctx, j.fsys, name, offset, length)
+}
+
+func (j *jailFS) CanSeek(ctx context.Context, name string) bool {
+	return j.allows(ctx, name) && CanSeek(ctx, j.fsys, name)
+}
+
+func (j *jailFS) Create(
+	ctx context.Context, name string,
+) (io.WriteCloser, error) {
+	if err := j.check(ctx, "create", name); err != nil {
+		return nil, err
+	}
+	return Create(ctx, j.fsys, name)
+}
+
+func (j *jailFS) Append(
+	ctx context.Context, name string,
+) (io.WriteCloser, error) {
+	if err := j.check(ctx, "append", name); err != nil {
+		return nil, err
+	}
+	return Append(ctx, j.fsys, name)
+}
+
+func (j *jailFS) AppendDir(
+	ctx context.Context, dir string,
+) (io.WriteCloser, error) {
+	if err := j.check(ctx, "appenddir", dir); err != nil {
+		return nil, err
+	}
+	afs, ok := j.fsys.(AppendDirFS)
+	if !ok {
+		return nil, &PathError{
+			Op:   "appenddir",
+			Path: dir,
+			Err:  ErrUnsupported,
+		}
+	}
+	return afs.AppendDir(ctx, dir)
+}
+
+func (j *jailFS) Stat(ctx context.Context, name string) (FileInfo, error) {
+	if err := j.check(ctx, "stat", name); err != nil {
+		return nil, err
+	}
+	return Stat(ctx, j.fsys, name)
+}
+
+func (j *jailFS) StatMany(
+	ctx context.Context, names []string,
+) ([]FileInfo, error) {
+	for _, name := range names {
+		if err := j.check(ctx, "stat", name); err != nil {
+			return nil, err
+		}
+	}
+	sfs, ok := j.fsys.(StatManyFS)
+	if !ok {
+		return nil, &PathError{Op: "stat", Err: ErrUnsupported}
+	}
+	return sfs.StatMany(ctx, names)
+}
+
+func (j *jailFS) Lstat(ctx context.Context, name string) (FileInfo, error) {
+	if err := j.check(ctx, "lstat", name); err != nil {
+		return nil, err
+	}
+	return Lstat(ctx, j.fsys, name)
+}
+
+func (j *jailFS) ReadLink(
+	ctx context.Context, name string,
+) (string, error) {
+	if err := j.check(ctx, "readlink", name); err != nil {
+		return "", err
+	}
+	return ReadLink(ctx, j.fsys, name)
+}
+
+func (j *jailFS) Link(
+	ctx context.Context, oldname, newname string,
+) error {
+	if err := j.check(ctx, "link", oldname); err != nil {
+		return err
+	}
+	if err := j.check(ctx, "link", newname); err != nil {
+		return err
+	}
+	return Link(ctx, j.fsys, oldname, newname)
+}
+
+func (j *jailFS) SameFile(
+	ctx context.Context, a, b string,
+) (bool, error) {
+	if err := j.check(ctx, "samefile", a); err != nil {
+		return false, err
+	}
+	if err := j.check(ctx, "samefile", b); err != nil {
+		return false, err
+	}
+	return SameFile(ctx, j.fsys, a, b)
+}
+
+// Symlink also checks the link target, resolved against the directory of
+// newname, so that links out of the jail cannot be planted.
+func (j *jailFS) Symlink(
+	ctx context.Context, oldname, newname string,
+) error {
+	if err := j.check(ctx, "symlink", newname); err != nil {
+		return err
+	}
+	target := oldname
+	if !stdpath.IsAbs(cleanJailPath(oldname)) {
+		target = stdpath.Join(stdpath.Dir(cleanJailPath(newname)), oldname)
+	}
+	if !j.allows(ctx, target) {
+		return &PathError{Op: "symlink", Path: oldname, Err: ErrPermission}
+	}
+	return Symlink(ctx, j.fsys, oldname, newname)
+}
+
+func (j *jailFS) ReadDir(
+	ctx context.Context, name string,
+) iter.Seq2[DirEntry, error] {
+	if err := j.check(ctx, "readdir", name); err != nil {
+		return denied[DirEntry](err)
+	}
+	return ReadDir(ctx, j.fsys, name)
+}
+
+func (j *jailFS) Walk(
+	ctx context.Context, root string, depth int,
+) iter.Seq2[DirEntry, error] {
+	if err := j.check(ctx, "walk", root); err != nil {
+		return denied[DirEntry](err)
+	}
+	return Walk(ctx, j.fsys, root, depth)
+}
+
+// Glob drops matches outside the jail, so that patterns cannot be used to
+// probe for files the jail hides.
+func (j *jailFS) Glob(
+	ctx context.Context, pattern string,
+) ([]string, error) {
+	matches, err := Glob(ctx, j.fsys, pattern)
+	if err != nil {
+		return nil, err
+	}
+	allowed := matches[:0]
+	for _, m := range matches {
+		if j.allows(ctx, m) {
+			allowed = append(allowed, m)
+		}
+	}
+	return allowed, nil
+}
+
+func (j *jailFS) Count(ctx context.Context, dir string) (int, error) {
+	if err := j.check(ctx, "count", dir); err != nil {
+		return 0, err
+	}
+	return Count(ctx, j.fsys, dir)
+}
+
+func (j *jailFS) Exists(ctx context.Context, name string) (bool, error) {
+	if err := j.check(ctx, "exists", name); err != nil {
+		return false, err
+	}
+	return Exists(ctx, j.fsys, name)
+}
+
+func (j *jailFS) IsMountPoint(
+	ctx context.Context, name string,
+) (bool, error) {
+	if err := j.check(ctx, "ismountpoint", name); err != nil {
+		return false, err
+	}
+	return IsMountPoint(ctx, j.fsys, name)
+}
+
+// Mkdir reports [ErrUnsupported] before it checks name if fsys cannot make
+// directories, so that the fallbacks of [MkdirAll] and the archive
+// extractors treat directories as virtual even where they climb out of the
+// jail looking for an existing parent.
+func (j *jailFS) Mkdir(ctx context.Context, name string) error {
+	if _, ok := j.fsys.(MkdirFS); !ok {
+		return &PathError{Op: "mkdir", Path: name, Err: ErrUnsupported}
+	}
+	if err := j.check(ctx, "mkdir", name); err != nil {
+		return err
+	}
+	return Mkdir(ctx, j.fsys, name)
+}
+
+func (j *jailFS) MkdirAll(ctx context.Context, name string) error {
+	if err := j.check(ctx, "mkdir", name); err != nil {
+		return err
+	}
+	return MkdirAll(ctx, j.fsys, name)
+}
+
+func (j *jailFS) Remove(ctx context.Context, name string) error {
+	if err := j.check(ctx, "remove", name); err != nil {
+		return err
+	}
+	return Remove(ctx, j.fsys, name)
+}
+
+func (j *jailFS) RemoveAll(ctx context.Context, name string) error {
+	if err := j.check(ctx, "removeall", name); err != nil {
+		return err
+	}
+	return RemoveAll(ctx, j.fsys, name)
+}
+
+func (j *jailFS) Rename(
+	ctx context.Context, oldname, newname string,
+) error {
+	if err := j.check(ctx, "rename", oldname); err != nil {
+		return err
+	}
+	if err := j.check(ctx, "rename", newname); err != nil {
+		return err
+	}
+	return Rename(ctx, j.fsys, oldname, newname)
+}
+
+func (j *jailFS) Copy(ctx context.Context, src, dst string) error {
+	if err := j.check(ctx, "copy", src); err != nil {
+		return err
+	}
+	if err := j.check(ctx, "copy", dst); err != nil {
+		return err
+	}
+	return Copy(ctx, j.fsys, src, dst)
+}
+
+// Trash reports ErrUnsupported, since a native trash would move the file
+// out of the jail. The fallback in [Trash] then runs through the wrapper's
+// checked Rename and MkdirAll, so [TrashDir] must be allowed as well.
+func (j *jailFS) Trash(ctx context.Context, name string) error {
+	return &PathError{Op: "trash", Path: name, Err: ErrUnsupported}
+}
+
+func (j *jailFS) Chmod(ctx context.Context, name string, mode Mode) error {
+	if err := j.check(ctx, "chmod", name); err != nil {
+		return err
+	}
+	return Chmod(ctx, j.fsys, name, mode)
+}
+
+func (j *jailFS) Mknod(
+	ctx context.Context, name string, mode Mode, dev uint64,
+) error {
+	if err := j.check(ctx, "mknod", name); err != nil {
+		return err
+	}
+	return Mknod(ctx, j.fsys, name, mode, dev)
+}
+
+func (j *jailFS) Chown(
+	ctx context.Context, name string, uid, gid int,
+) error {
+	if err := j.check(ctx, "chown", name); err != nil {
+		return err
+	}
+	return Chown(ctx, j.fsys, name, uid, gid)
+}
+
+func (j *jailFS) Chtimes(
+	ctx context.Context, name string, atime, mtime time.Time,
+) error {
+	if err := j.check(ctx, "chtimes", name); err != nil {
+		return err
+	}
+	return Chtimes(ctx, j.fsys, name, atime, mtime)
+}
+
+func (j *jailFS) Truncate(
+	ctx context.Context, name string, size int64,
+) error {
+	if err := j.check(ctx, "truncate", name); err != nil {
+		return err
+	}
+	return Truncate(ctx, j.fsys, name, size)
+}
+
+func (j *jailFS) TruncateDir(ctx context.Context, dir string) error {
+	if err := j.check(ctx, "truncate", dir); err != nil {
+		return err
+	}
+	tfs, ok := j.fsys.(TruncateDirFS)
+	if !ok {
+		return &PathError{Op: "truncate", Path: dir, Err: ErrUnsupported}
+	}
+	return tfs.TruncateDir(ctx, dir)
+}
+
+// Temp reports ErrUnsupported, so that [Temp] creates the file inside the
+// jail rather than in the backend's temporary location.
+func (j *jailFS) Temp(ctx context.Context, name string) (string, error) {
+	return "", &PathError{Op: "temp", Path: name, Err: ErrUnsupported}
+}
+
+// TempDir reports ErrUnsupported for the same reason as Temp.
+func (j *jailFS) TempDir(
+	ctx context.Context, name string,
+) (string, error) {
+	return "", &PathError{Op: "tempdir", Path: name, Err: ErrUnsupported}
+}
+
+// Localize is lexical and touches no files, so it is not checked.
+func (j *jailFS) Localize(
+	ctx context.Context, path string,
+) (string, error) {
+	return Localize(ctx, j.fsys, path)
+}
+
+func (j *jailFS) Abs(ctx context.Context, name string) (string, error) {
+	if err := j.check(ctx, "abs", name); err != nil {
+		return "", err
+	}
+	return Abs(ctx, j.fsys, name)
+}
+
+func (j *jailFS) SysConn(
+	ctx context.Context, name string,
+) (RawConn, error) {
+	if err := j.check(ctx, "sysconn", name); err != nil {
+		return nil, err
+	}
+	return SysConn(ctx, j.fsys, name)
+}
+
+func (j *jailFS) Close() error { return Close(j.fsys) }
+
+var (
+	_ AbsFS         = (*jailFS)(nil)
+	_ AppendFS      = (*jailFS)(nil)
+	_ AppendDirFS   = (*jailFS)(nil)
+	_ ChmodFS       = (*jailFS)(nil)
+	_ ChownFS       = (*jailFS)(nil)
+	_ ChtimesFS     = (*jailFS)(nil)
+	_ CopyFS        = (*jailFS)(nil)
+	_ CountFS       = (*jailFS)(nil)
+	_ CreateFS      = (*jailFS)(nil)
+	_ DirFS         = (*jailFS)(nil)
+	_ ExistsFS      = (*jailFS)(nil)
+	_ GlobFS        = (*jailFS)(nil)
+	_ LinkFS        = (*jailFS)(nil)
+	_ LocalizeFS    = (*jailFS)(nil)
+	_ MkdirFS       = (*jailFS)(nil)
+	_ MkdirAllFS    = (*jailFS)(nil)
+	_ MknodFS       = (*jailFS)(nil)
+	_ MountFS       = (*jailFS)(nil)
+	_ OpenRangeFS   = (*jailFS)(nil)
+	_ ReadDirFS     = (*jailFS)(nil)
+	_ ReadLinkFS    = (*jailFS)(nil)
+	_ RemoveFS      = (*jailFS)(nil)
+	_ RemoveAllFS   = (*jailFS)(nil)
+	_ RenameFS      = (*jailFS)(nil)
+	_ SameFileFS    = (*jailFS)(nil)
+	_ SeekFS        = (*jailFS)(nil)
+	_ StatFS        = (*jailFS)(nil)
+	_ StatManyFS    = (*jailFS)(nil)
+	_ SymlinkFS     = (*jailFS)(nil)
+	_ SysFS         = (*jailFS)(nil)
+	_ TempFS        = (*jailFS)(nil)
+	_ TempDirFS     = (*jailFS)(nil)
+	_ TrashFS       = (*jailFS)(nil)
+	_ TruncateFS    = (*jailFS)(nil)
+	_ TruncateDirFS = (*jailFS)(nil)
+	_ WalkFS        = (*jailFS)(nil)
+	_ io.Closer     = (*jailFS)(nil)
+)
@@ -0,0 +1,143 @@
+package fs_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"lesiw.io/fs"
+	"lesiw.io/fs/memfs"
+)
+
+func TestJail(t *testing.T) {
+	ctx, mem := context.Background(), memfs.New()
+	for _, name := range []string{
+		"public/index.html", "uploads/a.txt", "secret/key", "top.txt",
+	} {
+		if err := fs.WriteFile(ctx, mem, name, []byte(name)); err != nil {
+			t.Fatalf("WriteFile(%q) error = %v", name, err)
+		}
+	}
+	fsys := fs.Jail(mem, "public", "uploads/")
+
+	for _, name := range []string{
+		"public/index.html",
+		"./public/index.html",
+		"uploads/a.txt",
+		"uploads/../public/index.html",
+	} {
+		if _, err := fs.ReadFile(ctx, fsys, name); err != nil {
+			t.Errorf("ReadFile(%q) error = %v", name, err)
+		}
+	}
+	for _, name := range []string{
+		"secret/key",
+		"top.txt",
+		"public/../secret/key",
+		"uploads/../../secret/key",
+		"../public/index.html",
+		`public\..\secret\key`,
+		"publicity.txt",
+	} {
+		_, err := fs.ReadFile(ctx, fsys, name)
+		if !errors.Is(err, fs.ErrPermission) {
+			t.Errorf("ReadFile(%q) error = %v, want ErrPermission", name, err)
+		}
+	}
+
+	err := fs.WriteFile(ctx, fsys, "uploads/b.txt", []byte("b"))
+	if err != nil {
+		t.Errorf("WriteFile(uploads/b.txt) error = %v", err)
+	}
+	err = fs.WriteFile(ctx, fsys, "uploads/../top.txt", []byte("x"))
+	if !errors.Is(err, fs.ErrPermission) {
+		t.Errorf("WriteFile(escape) error = %v, want ErrPermission", err)
+	}
+	err = fs.Rename(ctx, fsys, "uploads/b.txt", "secret/b.txt")
+	if !errors.Is(err, fs.ErrPermission) {
+		t.Errorf("Rename(out of jail) error = %v, want ErrPermission", err)
+	}
+	err = fs.Symlink(ctx, fsys, "../secret/key", "uploads/key")
+	if !errors.Is(err, fs.ErrPermission) {
+		t.Errorf("Symlink(out of jail) error = %v, want ErrPermission", err)
+	}
+	if err := fs.RemoveAll(ctx, fsys, "secret"); err == nil {
+		t.Error("RemoveAll(secret) error = nil, want ErrPermission")
+	}
+	if _, err := fs.Stat(ctx, mem, "secret/key"); err != nil {
+		t.Errorf("Stat(secret/key) error = %v after denied RemoveAll", err)
+	}
+
+	for _, err := range fs.ReadDir(ctx, fsys, ".") {
+		if !errors.Is(err, fs.ErrPermission) {
+			t.Errorf("ReadDir(.) error = %v, want ErrPermission", err)
+		}
+	}
+	got, err := fs.Glob(ctx, fsys, "*/*")
+	if err != nil {
+		t.Fatalf("Glob() error = %v", err)
+	}
+	want := []string{"public/index.html", "uploads/a.txt", "uploads/b.txt"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Glob(*/*) = %v, want %v", got, want)
+	}
+}
+
+func TestJailDot(t *testing.T) {
+	ctx, mem := context.Background(), memfs.New()
+	if err := fs.WriteFile(ctx, mem, "a/b.txt", nil); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	fsys := fs.Jail(mem, ".")
+
+	if _, err := fs.Stat(ctx, fsys, "a/../a/b.txt"); err != nil {
+		t.Errorf("Stat(a/../a/b.txt) error = %v", err)
+	}
+	for _, name := range []string{"..", "a/../../x", "/etc/passwd", "C:/x"} {
+		_, err := fs.Stat(ctx, fsys, name)
+		if !errors.Is(err, fs.ErrPermission) {
+			t.Errorf("Stat(%q) error = %v, want ErrPermission", name, err)
+		}
+	}
+}
+
+func TestJailWorkDir(t *testing.T) {
+	ctx, mem := context.Background(), memfs.New()
+	for _, name := range []string{"pub/a.txt", "priv/pub/secret.txt"} {
+		if err := fs.WriteFile(ctx, mem, name, []byte(name)); err != nil {
+			t.Fatalf("WriteFile(%q) error = %v", name, err)
+		}
+	}
+	fsys := fs.Jail(mem, "pub")
+
+	_, err := fs.ReadFile(fs.WithWorkDir(ctx, "priv"), fsys, "pub/secret.txt")
+	if !errors.Is(err, fs.ErrPermission) {
+		t.Errorf("ReadFile(priv: pub/secret.txt) error = %v, "+
+			"want ErrPermission", err)
+	}
+	got, err := fs.ReadFile(fs.WithWorkDir(ctx, "pub"), fsys, "a.txt")
+	if err != nil {
+		t.Errorf("ReadFile(pub: a.txt) error = %v", err)
+	} else if string(got) != "pub/a.txt" {
+		t.Errorf("ReadFile(pub: a.txt) = %q, want %q", got, "pub/a.txt")
+	}
+}
+
+func TestJailNoMkdir(t *testing.T) {
+	ctx := context.Background()
+	fsys := fs.Jail(noMkdirFS{memfs.New()}, "out")
+
+	err := appendTar(ctx, fsys, "out/", map[string]string{"a/b.txt": "b"})
+	if err != nil {
+		t.Fatalf("Append(out/) error = %v", err)
+	}
+	got, err := fs.ReadFile(ctx, fsys, "out/a/b.txt")
+	if err != nil || string(got) != "b" {
+		t.Errorf("ReadFile(out/a/b.txt) = %q, %v, want %q", got, err, "b")
+	}
+	err = fs.Mkdir(ctx, fsys, "../x")
+	if !errors.Is(err, fs.ErrUnsupported) {
+		t.Errorf("Mkdir(../x) error = %v, want ErrUnsupported", err)
+	}
+}
@@ -0,0 +1,36 @@
+package fs
+
+import (
+	"context"
+	"errors"
+)
+
+// A LinkFS is a file system with the Link method.
+type LinkFS interface {
+	FS
+
+	// Link creates newname as a hard link to the file oldname.
+	Link(ctx context.Context, oldname, newname string) error
+}
+
+// Link creates newname as a hard link to the file oldname. Unlike
+// [Symlink], oldname is a path in fsys and must exist.
+// Analogous to: [os.Link], ln, SFTP hardlink@openssh.com.
+//
+// Requires: [LinkFS]
+func Link(ctx context.Context, fsys FS, oldname, newname string) error {
+	var err error
+	if oldname, err = localizePath(ctx, fsys, oldname); err != nil {
+		return err
+	}
+	if newname, err = localizePath(ctx, fsys, newname); err != nil {
+		return err
+	}
+	if lfs, ok := fsys.(LinkFS); ok {
+		err := lfs.Link(ctx, oldname, newname)
+		if !errors.Is(err, ErrUnsupported) {
+			return newPathError("link", newname, err)
+		}
+	}
+	return &PathError{Op: "link", Path: newname, Err: ErrUnsupported}
+}
@@ -0,0 +1,21 @@
+package fs_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"lesiw.io/fs"
+	"lesiw.io/fs/memfs"
+)
+
+func TestLinkUnsupported(t *testing.T) {
+	ctx, fsys := context.Background(), memfs.New()
+	if err := fs.WriteFile(ctx, fsys, "a.txt", []byte("a")); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	err := fs.Link(ctx, fsys, "a.txt", "b.txt")
+	if !errors.Is(err, fs.ErrUnsupported) {
+		t.Errorf("Link() error = %v, want ErrUnsupported", err)
+	}
+}
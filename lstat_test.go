@@ -2,10 +2,15 @@ package fs_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"os"
+	"path/filepath"
+	"testing"
 
 	"lesiw.io/fs"
+	"lesiw.io/fs/memfs"
 	"lesiw.io/fs/osfs"
 )
 
@@ -29,3 +34,95 @@ func ExampleLstat() {
 	// Output:
 	// IsSymlink: true
 }
+
+func TestLinkInfo(t *testing.T) {
+	fsys, ctx := osfs.NewTemp(), context.Background()
+	t.Cleanup(func() { fs.Close(fsys) })
+
+	err := fs.WriteFile(ctx, fsys, "target.txt", []byte("content"))
+	if err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if err := fs.Symlink(ctx, fsys, "target.txt", "link.txt"); err != nil {
+		t.Fatalf("Symlink() error = %v", err)
+	}
+
+	target, info, err := fs.LinkInfo(ctx, fsys, "link.txt")
+	if err != nil {
+		t.Fatalf("LinkInfo(link.txt) error = %v", err)
+	}
+	if want := "target.txt"; target != want {
+		t.Errorf("LinkInfo(link.txt) target = %q, want %q", target, want)
+	}
+	if info.Mode()&fs.ModeSymlink == 0 {
+		t.Errorf("LinkInfo(link.txt) mode = %v, want symlink", info.Mode())
+	}
+
+	target, info, err = fs.LinkInfo(ctx, fsys, "target.txt")
+	if err != nil {
+		t.Fatalf("LinkInfo(target.txt) error = %v", err)
+	}
+	if target != "" {
+		t.Errorf("LinkInfo(target.txt) target = %q, want empty", target)
+	}
+	if got, want := info.Size(), int64(len("content")); got != want {
+		t.Errorf("LinkInfo(target.txt) size = %d, want %d", got, want)
+	}
+}
+
+func TestReadLinkResolved(t *testing.T) {
+	fsys, ctx := osfs.NewTemp(), context.Background()
+	t.Cleanup(func() { fs.Close(fsys) })
+
+	for _, name := range []string{"a/b/target.txt", "top.txt"} {
+		if err := fs.WriteFile(ctx, fsys, name, nil); err != nil {
+			t.Fatalf("WriteFile(%q) error = %v", name, err)
+		}
+	}
+	abs, err := fs.Abs(ctx, fsys, "top.txt")
+	if err != nil {
+		t.Fatalf("Abs() error = %v", err)
+	}
+	tests := []struct {
+		target, link, want string
+	}{
+		{"target.txt", "a/b/same.txt", "./a/b/target.txt"},
+		{"../../top.txt", "a/b/up.txt", "./top.txt"},
+		{"b/target.txt", "a/down.txt", "./a/b/target.txt"},
+		{"a/b/target.txt", "root.txt", "./a/b/target.txt"},
+		{abs, "a/abs.txt", abs},
+	}
+	for _, tt := range tests {
+		// fs.Symlink localizes its target, which rejects "..", so create
+		// the links directly.
+		link, err := fs.Abs(ctx, fsys, tt.link)
+		if err != nil {
+			t.Fatalf("Abs(%q) error = %v", tt.link, err)
+		}
+		if err := os.Symlink(filepath.FromSlash(tt.target), link); err != nil {
+			t.Fatalf("Symlink(%q, %q) error = %v", tt.target, tt.link, err)
+		}
+		got, err := fs.ReadLinkResolved(ctx, fsys, tt.link)
+		if err != nil {
+			t.Errorf("ReadLinkResolved(%q) error = %v", tt.link, err)
+			continue
+		}
+		if got != filepath.FromSlash(tt.want) {
+			t.Errorf("ReadLinkResolved(%q) = %q, want %q",
+				tt.link, got, tt.want)
+		}
+		if _, err := fs.Stat(ctx, fsys, got); err != nil {
+			t.Errorf("Stat(%q) error = %v", got, err)
+		}
+	}
+}
+
+func TestLinkInfoUnsupported(t *testing.T) {
+	ctx := context.Background()
+	fsys := &streamFS{FS: memfs.New()}
+
+	_, _, err := fs.LinkInfo(ctx, fsys, "file.txt")
+	if !errors.Is(err, fs.ErrUnsupported) {
+		t.Errorf("LinkInfo() error = %v, want ErrUnsupported", err)
+	}
+}
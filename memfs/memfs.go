@@ -15,7 +15,7 @@ import (
 )
 
 var (
-	errIsDir       = errors.New("is a directory")
+	errIsDir       = fs.ErrIsDir
 	errDirNotEmpty = errors.New("directory not empty")
 )
 
@@ -113,6 +113,10 @@ func (f *memFS) resolve(name string, follow bool, depth int) (*node, bool) {
 }
 
 func (f *memFS) walkDir(name string) (*node, string, bool) {
+	if len(name) > 1 {
+		// A trailing slash names the directory itself.
+		name = strings.TrimSuffix(name, "/")
+	}
 	dir, base := path.Split(name)
 	parent, ok := f.walk(dir)
 	if !ok || !parent.dir {
@@ -136,9 +140,15 @@ func (f *memFS) Open(ctx context.Context, name string) (io.ReadCloser, error) {
 		return nil, &fs.PathError{Op: "open", Path: name, Err: errIsDir}
 	}
 
-	return io.NopCloser(bytes.NewReader(n.data)), nil
+	return reader{bytes.NewReader(n.data)}, nil
 }
 
+// reader is a file opened for reading. It supports io.Seeker and
+// io.ReaderAt through the embedded bytes.Reader.
+type reader struct{ *bytes.Reader }
+
+func (reader) Close() error { return nil }
+
 type writer struct {
 	*memFS
 	*node
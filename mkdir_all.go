@@ -3,14 +3,15 @@ package fs
 import (
 	"context"
 	"errors"
+	"slices"
 
 	"lesiw.io/fs/path"
 )
 
 // A MkdirAllFS is a file system with the MkdirAll method.
 //
-// If not implemented, MkdirAll falls back to recursive creation using
-// MkdirFS and StatFS.
+// If not implemented, MkdirAll falls back to creating each missing
+// directory in turn using MkdirFS and StatFS.
 type MkdirAllFS interface {
 	FS
 
@@ -29,6 +30,11 @@ type MkdirAllFS interface {
 //	ctx = fs.WithDirMode(ctx, 0700)
 //	fs.MkdirAll(ctx, fsys, "a/b/c")  // All created with mode 0700
 //
+// Intermediate directories use [ParentDirMode](ctx) instead, which
+// defaults to DirMode. When the two differ, MkdirAll creates the parents
+// and the final directory in separate steps, so that a backend's native
+// MkdirAll never applies one mode to both.
+//
 // If name is already a directory, MkdirAll does nothing and returns nil.
 //
 // Requires: [MkdirAllFS] || ([MkdirFS] && [StatFS])
@@ -37,6 +43,9 @@ func MkdirAll(ctx context.Context, fsys FS, name string) error {
 	if name, err = localizePath(ctx, fsys, name); err != nil {
 		return err
 	}
+	if mode := ParentDirMode(ctx); mode != DirMode(ctx) {
+		return mkdirAllParentMode(ctx, fsys, name, mode)
+	}
 
 	mafs, ok := fsys.(MkdirAllFS)
 	if !ok {
@@ -55,6 +64,32 @@ func MkdirAll(ctx context.Context, fsys FS, name string) error {
 	return mkdirAllFallback(ctx, fsys, name)
 }
 
+// mkdirAllParentMode creates the parents of name with mode, then name
+// itself with DirMode.
+func mkdirAllParentMode(
+	ctx context.Context, fsys FS, name string, mode Mode,
+) error {
+	if info, err := Stat(ctx, fsys, name); err == nil {
+		if info.IsDir() {
+			return nil
+		}
+		return &PathError{Op: "mkdir", Path: name, Err: ErrNotDir}
+	}
+	if parent := path.Dir(name); parent != "" && parent != name {
+		if err := MkdirAll(WithDirMode(ctx, mode), fsys, parent); err != nil {
+			return err
+		}
+	}
+	err := Mkdir(ctx, fsys, name)
+	if errors.Is(err, ErrExist) {
+		return nil
+	}
+	if mafs, ok := fsys.(MkdirAllFS); ok && errors.Is(err, ErrUnsupported) {
+		return mafs.MkdirAll(ctx, name)
+	}
+	return err
+}
+
 // mkdirAllFallback implements MkdirAll using MkdirFS and StatFS.
 func mkdirAllFallback(ctx context.Context, fsys FS, name string) error {
 	// Check if fallback is possible - requires MkdirFS and StatFS
@@ -69,42 +104,53 @@ func mkdirAllFallback(ctx context.Context, fsys FS, name string) error {
 		}
 	}
 
-	// Check if it already exists
-	info, err := Stat(ctx, fsys, name)
-	if err == nil {
-		if info.IsDir() {
-			return nil
+	// Climb to the nearest existing ancestor, collecting the directories
+	// to create along the way.
+	var missing []string
+	for dir := name; ; {
+		info, err := Stat(ctx, fsys, dir)
+		if err == nil {
+			if info.IsDir() {
+				break
+			}
+			return &PathError{
+				Op:   "mkdir",
+				Path: dir,
+				Err:  ErrNotDir,
+			}
 		}
-		return &PathError{
-			Op:   "mkdir",
-			Path: name,
-			Err:  ErrNotDir,
+		missing = append(missing, dir)
+		parent := path.Dir(dir)
+		if parent == "" || parent == "." || parent == dir {
+			break
 		}
+		dir = parent
 	}
 
-	// Try to create the directory
-	err = mfs.Mkdir(ctx, name)
-	if err == nil || errors.Is(err, ErrExist) {
-		return nil
-	}
-
-	// Mkdir failed - try to create parent directories
-	// Most commonly this is because the parent doesn't exist,
-	// but we recurse regardless of the error type
-	parent := path.Dir(name)
-	if parent == "." || parent == name {
-		return err
+	// Create them top-down (ignore ErrExist in case of a concurrent create)
+	for _, dir := range slices.Backward(missing) {
+		err := mfs.Mkdir(ctx, dir)
+		if err != nil && !errors.Is(err, ErrExist) {
+			return err
+		}
 	}
+	return nil
+}
 
-	// Recursively create parent
-	if err := MkdirAll(ctx, fsys, parent); err != nil {
-		return err
+// mkdirAllOrVirtual creates dir and any missing parents if fsys can create
+// directories, and reports whether it did. If fsys cannot, because it does
+// not implement [MkdirFS] or a wrapper reports [ErrUnsupported] for the
+// backend beneath it, directories are virtual: mkdirAllOrVirtual returns
+// false and no error, and dir exists implicitly once files are put in it.
+func mkdirAllOrVirtual(
+	ctx context.Context, fsys FS, dir string,
+) (bool, error) {
+	if _, ok := fsys.(MkdirFS); !ok {
+		return false, nil
 	}
-
-	// Try again (ignore ErrExist in case created by parent)
-	err = mfs.Mkdir(ctx, name)
-	if err == nil || errors.Is(err, ErrExist) {
-		return nil
+	err := MkdirAll(ctx, fsys, dir)
+	if errors.Is(err, ErrUnsupported) {
+		return false, nil
 	}
-	return err
+	return err == nil, err
 }
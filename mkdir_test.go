@@ -2,10 +2,16 @@ package fs_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"runtime/debug"
+	"strings"
+	"testing"
 
 	"lesiw.io/fs"
+	"lesiw.io/fs/memfs"
 	"lesiw.io/fs/osfs"
 )
 
@@ -42,3 +48,101 @@ func ExampleMkdirAll() {
 	// Output:
 	// Created: true
 }
+
+func TestMkdirAllParentDirMode(t *testing.T) {
+	ctx, fsys := context.Background(), memfs.New()
+	ctx = fs.WithDirMode(ctx, 0700)
+	ctx = fs.WithParentDirMode(ctx, 0755)
+
+	if err := fs.MkdirAll(ctx, fsys, "a/b/c"); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	for name, want := range map[string]fs.Mode{
+		"a": 0755, "a/b": 0755, "a/b/c": 0700,
+	} {
+		info, err := fs.Stat(ctx, fsys, name)
+		if err != nil {
+			t.Fatalf("Stat(%q) error = %v", name, err)
+		}
+		if got := info.Mode().Perm(); got != want {
+			t.Errorf("Stat(%q).Mode().Perm() = %v, want %v", name, got, want)
+		}
+	}
+	if err := fs.MkdirAll(ctx, fsys, "a/b/c"); err != nil {
+		t.Errorf("MkdirAll() on existing directory error = %v", err)
+	}
+}
+
+func TestMkdirAllDefaultParentDirMode(t *testing.T) {
+	ctx, fsys := context.Background(), memfs.New()
+	ctx = fs.WithDirMode(ctx, 0700)
+
+	if err := fs.MkdirAll(ctx, fsys, "a/b"); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	for _, name := range []string{"a", "a/b"} {
+		info, err := fs.Stat(ctx, fsys, name)
+		if err != nil {
+			t.Fatalf("Stat(%q) error = %v", name, err)
+		}
+		if got, want := info.Mode().Perm(), fs.Mode(0700); got != want {
+			t.Errorf("Stat(%q).Mode().Perm() = %v, want %v", name, got, want)
+		}
+	}
+}
+
+// mkdirStatFS exposes only the operations MkdirAll's fallback needs.
+type mkdirStatFS struct{ fsys fs.FS }
+
+func (f mkdirStatFS) Open(ctx context.Context, name string) (
+	io.ReadCloser, error,
+) {
+	return fs.Open(ctx, f.fsys, name)
+}
+
+func (f mkdirStatFS) Mkdir(ctx context.Context, name string) error {
+	return fs.Mkdir(ctx, f.fsys, name)
+}
+
+func (f mkdirStatFS) Stat(ctx context.Context, name string) (
+	fs.FileInfo, error,
+) {
+	return fs.Stat(ctx, f.fsys, name)
+}
+
+func TestMkdirAllDeep(t *testing.T) {
+	ctx, fsys := context.Background(), mkdirStatFS{memfs.New()}
+
+	// A recursive fallback would need far more than this for 1000 levels.
+	defer debug.SetMaxStack(debug.SetMaxStack(128 << 10))
+
+	name := strings.Repeat("d/", 1000) + "leaf"
+	if err := fs.MkdirAll(ctx, fsys, name); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	info, err := fs.Stat(ctx, fsys, name)
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	if !info.IsDir() {
+		t.Errorf("Stat().IsDir() = false, want true")
+	}
+	if err := fs.MkdirAll(ctx, fsys, name); err != nil {
+		t.Errorf("MkdirAll() on existing directory error = %v", err)
+	}
+}
+
+func TestMkdirAllFile(t *testing.T) {
+	ctx, mem := context.Background(), memfs.New()
+	fsys := mkdirStatFS{mem}
+	if err := fs.WriteFile(ctx, mem, "a/file", nil); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	for _, name := range []string{"a/file", "a/file/b/c"} {
+		err := fs.MkdirAll(ctx, fsys, name)
+		if !errors.Is(err, fs.ErrNotDir) {
+			t.Errorf("MkdirAll(%q) error = %v, want ErrNotDir", name, err)
+		}
+	}
+}
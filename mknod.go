@@ -0,0 +1,65 @@
+package fs
+
+import (
+	"context"
+	"errors"
+	"runtime"
+)
+
+// A MknodFS is a file system with the Mknod method.
+type MknodFS interface {
+	FS
+
+	// Mknod creates a special file: a named pipe, a socket, or a device.
+	// The type bits of mode select the kind of file: [ModeNamedPipe],
+	// [ModeSocket], [ModeDevice] for a block device, or [ModeDevice] with
+	// [ModeCharDevice] for a character device. dev is the device number
+	// for device files and is ignored otherwise.
+	Mknod(ctx context.Context, name string, mode Mode, dev uint64) error
+}
+
+// Mknod creates name as a special file.
+// Analogous to: [syscall.Mknod], mknod.
+//
+// The type bits of mode select a named pipe, socket, block device, or
+// character device, as described in [MknodFS]. This is typically a
+// Unix-specific operation, and creating devices usually requires
+// privileges.
+//
+// Requires: [MknodFS]
+func Mknod(
+	ctx context.Context, fsys FS, name string, mode Mode, dev uint64,
+) error {
+	var err error
+	if name, err = localizePath(ctx, fsys, name); err != nil {
+		return err
+	}
+	if mfs, ok := fsys.(MknodFS); ok {
+		err := mfs.Mknod(ctx, name, mode, dev)
+		if !errors.Is(err, ErrUnsupported) {
+			return newPathError("mknod", name, err)
+		}
+	}
+	return &PathError{Op: "mknod", Path: name, Err: ErrUnsupported}
+}
+
+// Mkfifo creates name as a named pipe with the permissions in mode.
+// Analogous to: [syscall.Mkfifo], mkfifo.
+//
+// Requires: [MknodFS]
+func Mkfifo(ctx context.Context, fsys FS, name string, mode Mode) error {
+	return Mknod(ctx, fsys, name, ModeNamedPipe|mode.Perm(), 0)
+}
+
+// makedev combines a device's major and minor numbers into the device
+// number passed to [MknodFS], in the encoding of the running system. Darwin
+// uses major<<24 | minor; everywhere else, the Linux encoding is used.
+func makedev(major, minor int64) uint64 {
+	maj, mnr := uint64(major), uint64(minor)
+	switch runtime.GOOS {
+	case "darwin", "ios":
+		return maj<<24 | mnr
+	}
+	return (maj&0xfffff000)<<32 | (maj&0xfff)<<8 |
+		(mnr&0xffffff00)<<12 | mnr&0xff
+}
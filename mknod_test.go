@@ -0,0 +1,18 @@
+package fs_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"lesiw.io/fs"
+	"lesiw.io/fs/memfs"
+)
+
+func TestMknodUnsupported(t *testing.T) {
+	ctx, fsys := context.Background(), memfs.New()
+	err := fs.Mkfifo(ctx, fsys, "pipe", 0o600)
+	if !errors.Is(err, fs.ErrUnsupported) {
+		t.Errorf("Mkfifo() error = %v, want ErrUnsupported", err)
+	}
+}
@@ -0,0 +1,41 @@
+package fs
+
+import (
+	"context"
+	"errors"
+)
+
+// A MountFS is a file system with the IsMountPoint method.
+//
+// Local file systems that can see mount boundaries, usually by comparing
+// the device of a directory with that of its parent, implement MountFS.
+type MountFS interface {
+	FS
+
+	// IsMountPoint reports whether the named directory is the root of a
+	// file system mounted on its parent.
+	IsMountPoint(ctx context.Context, name string) (bool, error)
+}
+
+// IsMountPoint reports whether the named directory is a mount point, the
+// boundary between two file systems.
+// Analogous to: mountpoint, find -xdev, du -x.
+//
+// File systems that do not implement [MountFS], such as remote object
+// stores, have no mount boundaries, so IsMountPoint reports false for
+// them.
+//
+// Requires: [MountFS] for detection; other file systems report false.
+func IsMountPoint(ctx context.Context, fsys FS, name string) (bool, error) {
+	var err error
+	if name, err = localizePath(ctx, fsys, name); err != nil {
+		return false, err
+	}
+	if mfs, ok := fsys.(MountFS); ok {
+		ok, err := mfs.IsMountPoint(ctx, name)
+		if !errors.Is(err, ErrUnsupported) {
+			return ok, newPathError("ismountpoint", name, err)
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,236 @@
+package fs
+
+import (
+	"archive/zip"
+	"context"
+	"io"
+	"iter"
+	"slices"
+
+	"lesiw.io/fs/path"
+)
+
+// MountArchive returns a read-only filesystem over the contents of a tar or
+// zip archive file stored in fsys.
+//
+// The archive format is determined by the extension of archivePath, as in
+// [OpenArchiveMember]. The archive is indexed once when it is mounted: zip
+// archives through their central directory, and tar archives by scanning
+// their headers. Directories missing from the archive are synthesized from
+// the paths of their children.
+//
+// The returned filesystem implements [FS], [StatFS], and [ReadDirFS], so
+// [Walk] and [Glob] work through their fallbacks. It also implements
+// [io.Closer]; call [Close] to release the underlying archive file.
+//
+// Opening a zip member reads only that member when the archive supports
+// random access. Opening a tar member scans the archive from the start.
+//
+// Requires: [FS]
+func MountArchive(
+	ctx context.Context, fsys FS, archivePath string,
+) (FS, error) {
+	var err error
+	if archivePath, err = localizePath(ctx, fsys, archivePath); err != nil {
+		return nil, err
+	}
+
+	afs := &archiveFS{
+		fsys:   fsys,
+		name:   archivePath,
+		format: detectArchiveFormat(archivePath),
+		nodes:  make(map[string]*archiveNode),
+	}
+	afs.nodes["."] = &archiveNode{info: &virtualDirInfo{name: "."}}
+
+	switch afs.format {
+	case formatZip:
+		zr, c, err := openZip(ctx, fsys, archivePath)
+		if err != nil {
+			return nil, err
+		}
+		afs.closer = c
+		for _, f := range zr.File {
+			afs.add(f.Name, f.FileInfo(), f)
+		}
+
+	case formatTar, formatTarGzip:
+		tr, c, err := openTar(ctx, fsys, archivePath, afs.format)
+		if err != nil {
+			return nil, err
+		}
+		for {
+			hdr, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				_ = c.Close()
+				return nil, &PathError{
+					Op:   "mount",
+					Path: archivePath,
+					Err:  err,
+				}
+			}
+			afs.add(hdr.Name, hdr.FileInfo(), nil)
+		}
+		if err := c.Close(); err != nil {
+			return nil, &PathError{Op: "mount", Path: archivePath, Err: err}
+		}
+
+	default:
+		return nil, &PathError{
+			Op:   "mount",
+			Path: archivePath,
+			Err:  ErrUnsupported,
+		}
+	}
+
+	for _, n := range afs.nodes {
+		slices.Sort(n.children)
+	}
+	return afs, nil
+}
+
+// archiveFS implements a read-only FS over an indexed archive.
+type archiveFS struct {
+	fsys   FS
+	name   string
+	format archiveFormat
+	closer io.Closer
+	nodes  map[string]*archiveNode
+}
+
+// archiveNode is an indexed archive member.
+type archiveNode struct {
+	info     FileInfo
+	zf       *zip.File // nil for tar members and synthesized directories
+	children []string
+}
+
+// add indexes an archive member, synthesizing any missing parents.
+func (f *archiveFS) add(name string, info FileInfo, zf *zip.File) {
+	name = archiveMemberName(name)
+	if name == "." {
+		return
+	}
+	if n, ok := f.nodes[name]; ok {
+		// A synthesized directory may be replaced by its real entry.
+		n.info, n.zf = info, zf
+		return
+	}
+	f.nodes[name] = &archiveNode{info: info, zf: zf}
+
+	for {
+		parent := archiveMemberName(path.Dir(name))
+		p, ok := f.nodes[parent]
+		if !ok {
+			p = &archiveNode{
+				info: &virtualDirInfo{name: path.Base(parent)},
+			}
+			f.nodes[parent] = p
+		}
+		p.children = append(p.children, path.Base(name))
+		if ok {
+			return
+		}
+		name = parent
+	}
+}
+
+// lookup resolves name, relative to any working directory in ctx.
+func (f *archiveFS) lookup(
+	ctx context.Context, op, name string,
+) (string, *archiveNode, error) {
+	if w := WorkDir(ctx); w != "" && !path.IsAbs(name) {
+		name = path.Join(w, name)
+	}
+	name = archiveMemberName(name)
+	n, ok := f.nodes[name]
+	if !ok {
+		return name, nil, &PathError{Op: op, Path: name, Err: ErrNotExist}
+	}
+	return name, n, nil
+}
+
+func (f *archiveFS) Open(
+	ctx context.Context, name string,
+) (io.ReadCloser, error) {
+	name, n, err := f.lookup(ctx, "open", name)
+	if err != nil {
+		return nil, err
+	}
+	if n.info.IsDir() {
+		return nil, &PathError{Op: "open", Path: name, Err: ErrIsDir}
+	}
+	if n.zf != nil {
+		r, err := n.zf.Open()
+		return r, newPathError("open", name, err)
+	}
+
+	tr, c, err := openTar(ctx, f.fsys, f.name, f.format)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			_ = c.Close()
+			if err == io.EOF {
+				err = ErrNotExist
+			}
+			return nil, &PathError{Op: "open", Path: name, Err: err}
+		}
+		if archiveMemberName(hdr.Name) == name {
+			return readCloser{tr, c}, nil
+		}
+	}
+}
+
+func (f *archiveFS) Stat(ctx context.Context, name string) (FileInfo, error) {
+	_, n, err := f.lookup(ctx, "stat", name)
+	if err != nil {
+		return nil, err
+	}
+	return n.info, nil
+}
+
+func (f *archiveFS) ReadDir(
+	ctx context.Context, name string,
+) iter.Seq2[DirEntry, error] {
+	return func(yield func(DirEntry, error) bool) {
+		name, n, err := f.lookup(ctx, "readdir", name)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		if !n.info.IsDir() {
+			yield(nil, &PathError{Op: "readdir", Path: name, Err: ErrNotDir})
+			return
+		}
+		for _, child := range n.children {
+			c := f.nodes[archiveMemberName(path.Join(name, child))]
+			if !yield(&readDirEntry{
+				name:  child,
+				isDir: c.info.IsDir(),
+				typ:   c.info.Mode().Type(),
+				info:  c.info,
+			}, nil) {
+				return
+			}
+		}
+	}
+}
+
+func (f *archiveFS) Close() error {
+	if f.closer != nil {
+		return f.closer.Close()
+	}
+	return nil
+}
+
+var (
+	_ StatFS    = (*archiveFS)(nil)
+	_ ReadDirFS = (*archiveFS)(nil)
+	_ io.Closer = (*archiveFS)(nil)
+)
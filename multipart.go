@@ -0,0 +1,147 @@
+package fs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"sync"
+)
+
+// A Part identifies one uploaded part of a multipart upload.
+type Part struct {
+	Number int    // Part number, starting at 1.
+	ETag   string // Opaque tag returned by the backend for the part.
+}
+
+// A MultipartFS is a file system with the CreateMultipart, UploadPart,
+// CompleteMultipart, and AbortMultipart methods.
+//
+// Backends that can assemble a file from independently uploaded parts, such
+// as object stores with multipart uploads, implement MultipartFS. The file
+// does not exist under its name until CompleteMultipart succeeds.
+type MultipartFS interface {
+	FS
+
+	// CreateMultipart starts a multipart upload of the named file and
+	// returns an identifier for it.
+	CreateMultipart(ctx context.Context, name string) (string, error)
+
+	// UploadPart uploads size bytes from r as the given part number of the
+	// upload. Parts may be uploaded concurrently and in any order.
+	UploadPart(
+		ctx context.Context, name, uploadID string, number int,
+		r io.Reader, size int64,
+	) (Part, error)
+
+	// CompleteMultipart assembles parts, in order of part number, into the
+	// named file.
+	CompleteMultipart(
+		ctx context.Context, name, uploadID string, parts []Part,
+	) error
+
+	// AbortMultipart cancels the upload and discards any uploaded parts.
+	AbortMultipart(ctx context.Context, name, uploadID string) error
+}
+
+// UploadParallel writes size bytes from r to the named file, uploading up
+// to parts ranges concurrently.
+// Analogous to: S3 Transfer Manager uploads, gsutil -m cp, rclone
+// --multi-thread-streams.
+//
+// When fsys implements [MultipartFS], r is split into parts ranges of
+// roughly equal size, each read through its own [io.SectionReader], so r
+// must accept concurrent ReadAt calls, as [os.File] does. If any part
+// fails, the remaining parts are canceled, the upload is aborted, and the
+// first error is returned. Backends may impose a minimum part size; S3,
+// for example, rejects parts smaller than 5 MiB other than the last.
+//
+// Otherwise, or if parts is less than 2, the file is written sequentially
+// with [Create].
+//
+// Requires: [MultipartFS] || [CreateFS]
+func UploadParallel(
+	ctx context.Context, fsys FS, name string,
+	r io.ReaderAt, size int64, parts int,
+) error {
+	var err error
+	if name, err = localizePath(ctx, fsys, name); err != nil {
+		return err
+	}
+	if size < 0 {
+		return &PathError{Op: "upload", Path: name, Err: ErrInvalid}
+	}
+	mfs, ok := fsys.(MultipartFS)
+	if !ok || parts < 2 || size < int64(parts) {
+		return uploadSequential(ctx, fsys, name, r, size)
+	}
+	id, err := mfs.CreateMultipart(ctx, name)
+	if errors.Is(err, ErrUnsupported) {
+		return uploadSequential(ctx, fsys, name, r, size)
+	} else if err != nil {
+		return newPathError("upload", name, err)
+	}
+
+	rctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+		done     = make([]Part, parts)
+	)
+	fail := func(err error) {
+		once.Do(func() {
+			firstErr = err
+			cancel()
+		})
+	}
+	chunk := size / int64(parts)
+	for i := range parts {
+		off := int64(i) * chunk
+		n := chunk
+		if i == parts-1 {
+			n = size - off
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p, err := mfs.UploadPart(
+				rctx, name, id, i+1, io.NewSectionReader(r, off, n), n,
+			)
+			if err != nil {
+				fail(newPathError("upload", name, err))
+				return
+			}
+			done[i] = p
+		}()
+	}
+	wg.Wait()
+	if firstErr == nil {
+		slices.SortFunc(done, func(a, b Part) int {
+			return a.Number - b.Number
+		})
+		err := mfs.CompleteMultipart(ctx, name, id, done)
+		if err == nil {
+			return nil
+		}
+		firstErr = newPathError("upload", name, err)
+	}
+	_ = mfs.AbortMultipart(context.WithoutCancel(ctx), name, id)
+	return firstErr
+}
+
+func uploadSequential(
+	ctx context.Context, fsys FS, name string, r io.ReaderAt, size int64,
+) error {
+	w, err := Create(ctx, fsys, name)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(w, io.NewSectionReader(r, 0, size)); err != nil {
+		_ = w.Close()
+		return &PathError{Op: "write", Path: name, Err: err}
+	}
+	return w.Close()
+}
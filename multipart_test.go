@@ -0,0 +1,136 @@
+package fs_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+
+	"lesiw.io/fs"
+	"lesiw.io/fs/memfs"
+)
+
+// multipartFS assembles multipart uploads in memory and writes the result
+// to the underlying file system on completion.
+type multipartFS struct {
+	fs.FS
+	mu      sync.Mutex
+	parts   map[int][]byte
+	aborted bool
+	failAt  int
+}
+
+func (f *multipartFS) CreateMultipart(
+	ctx context.Context, name string,
+) (string, error) {
+	f.parts = make(map[int][]byte)
+	return "upload-1", nil
+}
+
+func (f *multipartFS) UploadPart(
+	ctx context.Context, name, uploadID string, number int,
+	r io.Reader, size int64,
+) (fs.Part, error) {
+	if number == f.failAt {
+		return fs.Part{}, errors.New("part failed")
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return fs.Part{}, err
+	}
+	if int64(len(data)) != size {
+		return fs.Part{}, fmt.Errorf("part %d: got %d bytes, want %d",
+			number, len(data), size)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.parts[number] = data
+	return fs.Part{Number: number, ETag: fmt.Sprint(number)}, nil
+}
+
+func (f *multipartFS) CompleteMultipart(
+	ctx context.Context, name, uploadID string, parts []fs.Part,
+) error {
+	var buf bytes.Buffer
+	for i, p := range parts {
+		if p.Number != i+1 {
+			return fmt.Errorf("parts[%d].Number = %d", i, p.Number)
+		}
+		buf.Write(f.parts[p.Number])
+	}
+	return fs.WriteFile(ctx, f.FS, name, buf.Bytes())
+}
+
+func (f *multipartFS) AbortMultipart(
+	ctx context.Context, name, uploadID string,
+) error {
+	f.aborted = true
+	return nil
+}
+
+func (f *multipartFS) Create(
+	ctx context.Context, name string,
+) (io.WriteCloser, error) {
+	return fs.Create(ctx, f.FS, name)
+}
+
+func (f *multipartFS) Stat(
+	ctx context.Context, name string,
+) (fs.FileInfo, error) {
+	return fs.Stat(ctx, f.FS, name)
+}
+
+func TestUploadParallel(t *testing.T) {
+	ctx := context.Background()
+	data := bytes.Repeat([]byte("0123456789abcdef"), 1000)
+	data = append(data, "tail"...)
+
+	for _, parts := range []int{4, 1} {
+		t.Run(fmt.Sprint(parts), func(t *testing.T) {
+			fsys := &multipartFS{FS: memfs.New()}
+			err := fs.UploadParallel(ctx, fsys, "big.bin",
+				bytes.NewReader(data), int64(len(data)), parts)
+			if err != nil {
+				t.Fatalf("UploadParallel() error = %v", err)
+			}
+			wantParts := parts
+			if parts < 2 {
+				wantParts = 0
+			}
+			if got := len(fsys.parts); got != wantParts {
+				t.Errorf("uploaded parts = %d, want %d", got, wantParts)
+			}
+			got, err := fs.ReadFile(ctx, fsys, "big.bin")
+			if err != nil {
+				t.Fatalf("ReadFile() error = %v", err)
+			}
+			if !bytes.Equal(got, data) {
+				t.Errorf("ReadFile() = %d bytes, want %d matching bytes",
+					len(got), len(data))
+			}
+		})
+	}
+}
+
+func TestUploadParallelAbort(t *testing.T) {
+	ctx := context.Background()
+	fsys := &multipartFS{FS: memfs.New(), failAt: 3}
+	data := bytes.Repeat([]byte("x"), 4096)
+
+	err := fs.UploadParallel(ctx, fsys, "big.bin",
+		bytes.NewReader(data), int64(len(data)), 4)
+	if err == nil {
+		t.Fatal("UploadParallel() error = nil, want error")
+	}
+	if !fsys.aborted {
+		t.Error("upload not aborted after failed part")
+	}
+	if _, err := fs.Stat(ctx, fsys, "big.bin"); !errors.Is(
+		err, fs.ErrNotExist,
+	) {
+		t.Errorf("Stat() error = %v, want ErrNotExist", err)
+	}
+}
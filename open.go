@@ -2,10 +2,14 @@ package fs
 
 import (
 	"archive/tar"
+	"cmp"
+	"compress/gzip"
 	"context"
 	"errors"
 	"io"
+	"slices"
 	"strings"
+	"time"
 
 	"lesiw.io/fs/path"
 )
@@ -32,6 +36,8 @@ type DirFS interface {
 
 	// OpenDir opens a tar stream for reading from the specified directory.
 	// The directory is archived as a tar stream that can be read until EOF.
+	// If [TarCompression] of ctx is not [CompressionNone], the stream is
+	// compressed in that format.
 	//
 	// The returned reader must be closed when done.
 	OpenDir(ctx context.Context, dir string) (io.ReadCloser, error)
@@ -46,19 +52,31 @@ type DirFS interface {
 // internally.
 //
 // The returned [ReadPathCloser] must be closed when done. The Path() method
-// returns the native filesystem path, or the input path if localization is
-// not supported.
+// returns the native filesystem path, or the input path if localization is not
+// supported. If the backend's stream reports its own path via [Pather], such as
+// a normalized object key, that path is returned instead.
 //
 // # Files
 //
-// Returns a [ReadPathCloser] for reading the file contents.
+// Returns a [ReadPathCloser] for reading the file contents. If the backend's
+// reader implements [io.Seeker], the returned reader does too; see [CanSeek].
+// Under [WithReadahead], the contents are prefetched in the background.
+// Under [WithChecksumSidecar], they are checked against the digest beside
+// the file.
 //
 // Requires: [FS]
 //
 // # Directories
 //
 // A trailing slash returns a tar archive stream of the directory contents.
-// A path identified as a directory via [StatFS] also returns a tar archive.
+// A path identified as a directory via [StatFS] also returns a tar archive,
+// as does a path whose plain open fails with [ErrIsDir], so directories can
+// be opened without a trailing slash on backends without [StatFS].
+// When the archive is built from [ReadDirFS], members are listed depth-first
+// in lexicographic order within each directory, or in lexicographic order of
+// their full paths under [WithSortedTar].
+//
+// Under [WithCompression], the archive is compressed.
 //
 // Requires: [DirFS] || ([FS] && ([ReadDirFS] || [WalkFS]))
 func Open(ctx context.Context, fsys FS, name string) (ReadPathCloser, error) {
@@ -68,31 +86,46 @@ func Open(ctx context.Context, fsys FS, name string) (ReadPathCloser, error) {
 	}
 
 	if path.IsDir(name) {
-		r, err := openDirAsTar(ctx, fsys, name)
-		if err != nil {
-			return nil, err
-		}
-		return readPathCloser(r, name), nil
+		return openDir(ctx, fsys, name)
+	}
+	sidecar := checksumSidecar(ctx)
+	if sidecar {
+		ctx = withoutSidecar(ctx)
 	}
 
 	if sfs, ok := fsys.(StatFS); ok {
 		info, err := sfs.Stat(ctx, name)
 		if err == nil && info.IsDir() {
-			r, err := openDirAsTar(ctx, fsys, name)
-			if err != nil {
-				return nil, err
-			}
-			return readPathCloser(r, name), nil
+			return openDir(ctx, fsys, path.Join(name, ""))
 		}
 	}
 
 	r, err := fsys.Open(ctx, name)
-	if err != nil {
+	if errors.Is(err, ErrIsDir) {
+		return openDir(ctx, fsys, path.Join(name, ""))
+	} else if err != nil {
 		return nil, err
 	}
+	if sidecar {
+		if r, err = verifySidecar(ctx, fsys, name, r); err != nil {
+			return nil, err
+		}
+	}
+	if n := readahead(ctx); n > 0 {
+		r = newReadahead(ctx, r, n)
+	}
 	return readPathCloser(r, name), nil
 }
 
+// openDir opens dir, which must end in a separator, as a tar stream.
+func openDir(ctx context.Context, fsys FS, dir string) (ReadPathCloser, error) {
+	r, err := openDirAsTar(ctx, fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	return readPathCloser(r, dir), nil
+}
+
 func openDirAsTar(
 	ctx context.Context, fsys FS, dir string,
 ) (io.ReadCloser, error) {
@@ -115,7 +148,16 @@ func walkDirAsTar(
 	pr, pw := io.Pipe()
 
 	go func() {
-		err := createTarFromFS(ctx, fsys, dir, pw)
+		var err error
+		if TarCompression(ctx) == CompressionGzip {
+			gz := gzip.NewWriter(pw)
+			err = createTarFromFS(ctx, fsys, dir, gz)
+			if cerr := gz.Close(); err == nil {
+				err = cerr
+			}
+		} else {
+			err = createTarFromFS(ctx, fsys, dir, pw)
+		}
 		pw.CloseWithError(err)
 	}()
 
@@ -126,18 +168,80 @@ func walkDirAsTar(
 func createTarFromFS(
 	ctx context.Context, fsys FS, dir string, w io.Writer,
 ) error {
-	dir = path.Clean(dir)
 	tw := tar.NewWriter(w)
 	defer tw.Close()
 
-	// Walk all entries and add to tar
-	var walkPath func(string, int) error
-	walkPath = func(currentPath string, currentDepth int) error {
+	return walkArchive(ctx, fsys, dir, func(m archiveMember) error {
+		// Create tar header
+		hdr, err := tar.FileInfoHeader(m.info, "")
+		if err != nil {
+			return err
+		}
+		hdr.Name = m.rel
+		if tarNumericOwner(ctx) {
+			hdr.Uname, hdr.Gname = "", ""
+		}
+		if reproducibleTar(ctx) {
+			normalizeTarHeader(hdr)
+		}
+
+		// Write header
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+
+		// Only regular files have contents. Directories, named pipes,
+		// and devices are archived as headers alone.
+		if !m.info.Mode().IsRegular() {
+			return nil
+		}
+		return copyMember(ctx, fsys, m, tw)
+	})
+}
+
+// An archiveMember is a file found under the root of an archive by
+// [walkArchive].
+type archiveMember struct {
+	path, rel string
+	info      FileInfo
+}
+
+// walkArchive calls fn for each file under dir, as the members of an
+// archive of dir.
+//
+// Members are visited depth-first, with each directory's entries in
+// lexicographic order, so the same tree always yields the same member order.
+// If [WithSortedTar] is set, the whole listing is gathered first and members
+// are visited in lexicographic order of their full paths.
+func walkArchive(
+	ctx context.Context, fsys FS, dir string, fn func(archiveMember) error,
+) error {
+	dir = path.Clean(dir)
+
+	var members []archiveMember
+	emit := fn
+	if sortedTar(ctx) {
+		emit = func(m archiveMember) error {
+			members = append(members, m)
+			return nil
+		}
+	}
+
+	// Walk all entries
+	var walkPath func(string) error
+	walkPath = func(currentPath string) error {
+		var entries []DirEntry
 		for entry, err := range ReadDir(ctx, fsys, currentPath) {
 			if err != nil {
 				return err
 			}
+			entries = append(entries, entry)
+		}
+		slices.SortFunc(entries, func(a, b DirEntry) int {
+			return cmp.Compare(a.Name(), b.Name())
+		})
 
+		for _, entry := range entries {
 			// Build full path
 			entryPath := path.Join(currentPath, entry.Name())
 
@@ -146,47 +250,68 @@ func createTarFromFS(
 			relPath = strings.TrimPrefix(relPath, "/")
 
 			// Get file info
-			info, infoErr := entry.Info()
-			if infoErr != nil {
-				return infoErr
-			}
-
-			// Create tar header
-			hdr, hdrErr := tar.FileInfoHeader(info, "")
-			if hdrErr != nil {
-				return hdrErr
+			info, err := entry.Info()
+			if err != nil {
+				return err
 			}
-			hdr.Name = relPath
 
-			// Write header
-			if writeErr := tw.WriteHeader(hdr); writeErr != nil {
-				return writeErr
+			m := archiveMember{entryPath, relPath, info}
+			if err := emit(m); err != nil {
+				return err
 			}
 
-			// Write file contents if not a directory
-			if !entry.IsDir() {
-				f, openErr := Open(ctx, fsys, entryPath)
-				if openErr != nil {
-					return openErr
-				}
-				_, copyErr := io.Copy(tw, f)
-				closeErr := f.Close()
-				if copyErr != nil {
-					return copyErr
-				}
-				if closeErr != nil {
-					return closeErr
-				}
-			} else {
-				// Recurse into subdirectory
-				recurseErr := walkPath(entryPath, currentDepth+1)
-				if recurseErr != nil {
-					return recurseErr
+			// Recurse into subdirectory
+			if entry.IsDir() {
+				if err := walkPath(entryPath); err != nil {
+					return err
 				}
 			}
 		}
 		return nil
 	}
 
-	return walkPath(dir, 0)
+	if err := walkPath(dir); err != nil {
+		return err
+	}
+	slices.SortFunc(members, func(a, b archiveMember) int {
+		return cmp.Compare(a.rel, b.rel)
+	})
+	for _, m := range members {
+		if err := fn(m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyMember copies the contents of the file m to w.
+func copyMember(
+	ctx context.Context, fsys FS, m archiveMember, w io.Writer,
+) error {
+	f, err := Open(ctx, fsys, m.path)
+	if err != nil {
+		return err
+	}
+	_, copyErr := io.Copy(w, f)
+	closeErr := f.Close()
+	if copyErr != nil {
+		return copyErr
+	}
+	return closeErr
+}
+
+// normalizeTarHeader strips the metadata of hdr that varies between hosts
+// and over time, for [WithReproducibleTar].
+func normalizeTarHeader(hdr *tar.Header) {
+	hdr.ModTime = time.Unix(0, 0)
+	hdr.AccessTime, hdr.ChangeTime = time.Time{}, time.Time{}
+	hdr.Uid, hdr.Gid = 0, 0
+	hdr.Uname, hdr.Gname = "", ""
+	hdr.PAXRecords = nil
+	switch {
+	case hdr.Typeflag == tar.TypeDir || hdr.Mode&0111 != 0:
+		hdr.Mode = 0755
+	default:
+		hdr.Mode = 0644
+	}
 }
@@ -0,0 +1,69 @@
+package fs
+
+import (
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"context"
+	"io"
+)
+
+// Compression magic numbers recognized by OpenAuto.
+var (
+	magicGzip  = []byte{0x1f, 0x8b}
+	magicBzip2 = []byte("BZh")
+	magicZstd  = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// OpenAuto opens the named file for reading, transparently decompressing it
+// if its contents are compressed. Analogous to: zcat -f.
+//
+// The compression format is detected from the magic number at the start of
+// the file, not from its name, so compressed files without a conventional
+// extension are handled. Gzip and bzip2 are decompressed. Zstandard is
+// detected but not supported, and returns an error satisfying
+// errors.Is(err, [ErrUnsupported]). Any other file is returned unchanged.
+//
+// OpenAuto buffers the first few bytes of the file to detect the format;
+// they are replayed ahead of the rest of the stream.
+//
+// The returned [ReadPathCloser] must be closed when done.
+//
+// Requires: [FS]
+func OpenAuto(
+	ctx context.Context, fsys FS, name string,
+) (ReadPathCloser, error) {
+	f, err := Open(ctx, fsys, name)
+	if err != nil {
+		return nil, err
+	}
+
+	hdr := make([]byte, len(magicZstd))
+	n, err := io.ReadFull(f, hdr)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		_ = f.Close()
+		return nil, &PathError{Op: "open", Path: f.Path(), Err: err}
+	}
+	hdr = hdr[:n]
+	r := io.MultiReader(bytes.NewReader(hdr), f)
+
+	switch {
+	case bytes.HasPrefix(hdr, magicGzip):
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			_ = f.Close()
+			return nil, &PathError{Op: "open", Path: f.Path(), Err: err}
+		}
+		return readPathCloser(readCloser{gz, multiCloser{gz, f}},
+			f.Path()), nil
+	case bytes.HasPrefix(hdr, magicBzip2) && len(hdr) > len(magicBzip2) &&
+		'1' <= hdr[3] && hdr[3] <= '9':
+		// The magic is followed by the block size, in hundreds of KiB.
+		return readPathCloser(readCloser{bzip2.NewReader(r), f},
+			f.Path()), nil
+	case bytes.HasPrefix(hdr, magicZstd):
+		_ = f.Close()
+		return nil, &PathError{Op: "open", Path: f.Path(), Err: ErrUnsupported}
+	}
+	return readPathCloser(readCloser{r, f}, f.Path()), nil
+}
@@ -0,0 +1,58 @@
+package fs_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"testing"
+
+	"lesiw.io/fs"
+	"lesiw.io/fs/memfs"
+)
+
+func TestOpenAuto(t *testing.T) {
+	ctx, fsys := context.Background(), memfs.New()
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := io.WriteString(gz, "compressed payload"); err != nil {
+		t.Fatalf("gzip Write() error = %v", err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatalf("gzip Close() error = %v", err)
+	}
+
+	files := map[string][]byte{
+		"payload.dat": buf.Bytes(),
+		"plain.dat":   []byte("plain payload"),
+		"bzh.dat":     []byte("BZhello"),
+		"short.dat":   []byte("x"),
+	}
+	want := map[string]string{
+		"payload.dat": "compressed payload",
+		"plain.dat":   "plain payload",
+		"bzh.dat":     "BZhello",
+		"short.dat":   "x",
+	}
+	for name, data := range files {
+		if err := fs.WriteFile(ctx, fsys, name, data); err != nil {
+			t.Fatalf("WriteFile(%q) error = %v", name, err)
+		}
+	}
+
+	for name, want := range want {
+		r, err := fs.OpenAuto(ctx, fsys, name)
+		if err != nil {
+			t.Fatalf("OpenAuto(%q) error = %v", name, err)
+		}
+		got, err := io.ReadAll(r)
+		_ = r.Close()
+		if err != nil {
+			t.Fatalf("ReadAll(%q) error = %v", name, err)
+		}
+		if string(got) != want {
+			t.Errorf("OpenAuto(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
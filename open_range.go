@@ -0,0 +1,73 @@
+package fs
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// An OpenRangeFS is a file system with the OpenRange method.
+//
+// Backends that can fetch part of a file without reading what comes before
+// it, such as object stores with ranged GET requests, implement OpenRangeFS.
+type OpenRangeFS interface {
+	FS
+
+	// OpenRange opens the named file for reading length bytes starting at
+	// offset. A negative length reads to the end of the file.
+	OpenRange(
+		ctx context.Context, name string, offset, length int64,
+	) (io.ReadCloser, error)
+}
+
+// OpenRange opens the named file for reading length bytes starting at
+// offset. A negative length reads to the end of the file.
+// Analogous to: [io.NewSectionReader], HTTP Range requests, S3 GetObject
+// with Range, 9P Tread.
+//
+// If fsys does not implement [OpenRangeFS], OpenRange opens the whole file
+// and seeks to offset if the reader implements [io.Seeker], or reads and
+// discards the bytes before offset otherwise.
+//
+// Requires: [OpenRangeFS] || [FS]
+func OpenRange(
+	ctx context.Context, fsys FS, name string, offset, length int64,
+) (ReadPathCloser, error) {
+	var err error
+	if name, err = localizePath(ctx, fsys, name); err != nil {
+		return nil, err
+	}
+	if offset < 0 {
+		return nil, &PathError{Op: "open", Path: name, Err: ErrInvalid}
+	}
+	if rfs, ok := fsys.(OpenRangeFS); ok {
+		r, err := rfs.OpenRange(ctx, name, offset, length)
+		if err == nil {
+			return readPathCloser(r, name), nil
+		}
+		if !errors.Is(err, ErrUnsupported) {
+			return nil, newPathError("open", name, err)
+		}
+	}
+
+	f, err := fsys.Open(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if s, ok := f.(io.Seeker); ok {
+		_, err = s.Seek(offset, io.SeekStart)
+	} else {
+		_, err = io.CopyN(io.Discard, f, offset)
+		if err == io.EOF {
+			err = nil
+		}
+	}
+	if err != nil {
+		_ = f.Close()
+		return nil, &PathError{Op: "open", Path: name, Err: err}
+	}
+	if length < 0 {
+		return readPathCloser(f, name), nil
+	}
+	return readPathCloser(readCloser{io.LimitReader(f, length), f}, name), nil
+}
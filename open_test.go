@@ -1,12 +1,17 @@
 package fs_test
 
 import (
+	"archive/tar"
 	"context"
 	"fmt"
 	"io"
+	"iter"
 	"log"
+	"slices"
+	"testing"
 
 	"lesiw.io/fs"
+	"lesiw.io/fs/memfs"
 	"lesiw.io/fs/osfs"
 )
 
@@ -31,3 +36,76 @@ func ExampleOpen() {
 	// Output:
 	// example content
 }
+
+// noStatFS exposes only Open and ReadDir, so Open cannot tell a directory
+// from a file before opening it.
+type noStatFS struct{ fsys fs.FS }
+
+func (f *noStatFS) Open(
+	ctx context.Context, name string,
+) (io.ReadCloser, error) {
+	return f.fsys.Open(ctx, name)
+}
+
+func (f *noStatFS) ReadDir(
+	ctx context.Context, name string,
+) iter.Seq2[fs.DirEntry, error] {
+	return fs.ReadDir(ctx, f.fsys, name)
+}
+
+func TestOpenDirWithoutStat(t *testing.T) {
+	ctx, mem := context.Background(), memfs.New()
+	for _, name := range []string{"dir/a.txt", "dir/sub/b.txt", "c.txt"} {
+		if err := fs.WriteFile(ctx, mem, name, []byte(name)); err != nil {
+			t.Fatalf("WriteFile(%q) error = %v", name, err)
+		}
+	}
+	fsys := &noStatFS{mem}
+
+	for _, name := range []string{"dir", "dir/"} {
+		t.Run(name, func(t *testing.T) {
+			r, err := fs.Open(ctx, fsys, name)
+			if err != nil {
+				t.Fatalf("Open(%q) error = %v", name, err)
+			}
+			defer r.Close()
+			var got []string
+			tr := tar.NewReader(r)
+			for {
+				hdr, err := tr.Next()
+				if err == io.EOF {
+					break
+				} else if err != nil {
+					t.Fatalf("tar Next() error = %v", err)
+				}
+				got = append(got, hdr.Name)
+			}
+			want := []string{"a.txt", "sub", "sub/b.txt"}
+			if !slices.Equal(got, want) {
+				t.Errorf("Open(%q) members = %v, want %v", name, got, want)
+			}
+		})
+	}
+}
+
+func TestOpenDirStat(t *testing.T) {
+	ctx, fsys := context.Background(), memfs.New()
+	for _, name := range []string{"dir/a.txt", "c.txt"} {
+		if err := fs.WriteFile(ctx, fsys, name, []byte(name)); err != nil {
+			t.Fatalf("WriteFile(%q) error = %v", name, err)
+		}
+	}
+
+	r, err := fs.Open(ctx, fsys, "dir")
+	if err != nil {
+		t.Fatalf("Open(dir) error = %v", err)
+	}
+	defer r.Close()
+	hdr, err := tar.NewReader(r).Next()
+	if err != nil {
+		t.Fatalf("tar Next() error = %v", err)
+	}
+	if want := "a.txt"; hdr.Name != want {
+		t.Errorf("first member = %q, want %q", hdr.Name, want)
+	}
+}
@@ -0,0 +1,88 @@
+package fs
+
+import "context"
+
+// OpenOptions gathers the context options that apply to [Open] into a
+// single value for [OpenWith]. The zero value of each field leaves the
+// context unchanged.
+type OpenOptions struct {
+	WorkDir   string // See [WithWorkDir].
+	RequestID string // See [WithRequestID].
+	UserAgent string // See [WithUserAgent].
+
+	// Offset and Length select a byte range of the file, as with
+	// [OpenRange]. A Length of 0 reads to the end of the file.
+	Offset, Length int64
+}
+
+// CreateOptions gathers the context options that apply to [Create] into a
+// single value for [CreateWith]. The zero value of each field leaves the
+// context unchanged.
+type CreateOptions struct {
+	FileMode       Mode   // See [WithFileMode].
+	DirMode        Mode   // See [WithDirMode].
+	ParentDirMode  Mode   // See [WithParentDirMode].
+	WorkDir        string // See [WithWorkDir].
+	IdempotencyKey string // See [WithIdempotencyKey].
+	RequestID      string // See [WithRequestID].
+	UserAgent      string // See [WithUserAgent].
+}
+
+// OpenWith opens the named file for reading with the given options.
+// It is equivalent to calling [Open], or [OpenRange] if a range is set,
+// with a context derived from ctx by the corresponding WithX functions.
+//
+// Requires: [FS]
+func OpenWith(
+	ctx context.Context, fsys FS, name string, opts OpenOptions,
+) (ReadPathCloser, error) {
+	ctx = withCommon(ctx, opts.WorkDir, opts.RequestID, opts.UserAgent)
+	if opts.Offset != 0 || opts.Length != 0 {
+		length := opts.Length
+		if length == 0 {
+			length = -1
+		}
+		return OpenRange(ctx, fsys, name, opts.Offset, length)
+	}
+	return Open(ctx, fsys, name)
+}
+
+// CreateWith creates or truncates the named file with the given options.
+// It is equivalent to calling [Create] with a context derived from ctx by
+// the corresponding WithX functions.
+//
+// Requires: [CreateFS]
+func CreateWith(
+	ctx context.Context, fsys FS, name string, opts CreateOptions,
+) (WritePathCloser, error) {
+	ctx = withCommon(ctx, opts.WorkDir, opts.RequestID, opts.UserAgent)
+	if opts.FileMode != 0 {
+		ctx = WithFileMode(ctx, opts.FileMode)
+	}
+	if opts.DirMode != 0 {
+		ctx = WithDirMode(ctx, opts.DirMode)
+	}
+	if opts.ParentDirMode != 0 {
+		ctx = WithParentDirMode(ctx, opts.ParentDirMode)
+	}
+	if opts.IdempotencyKey != "" {
+		ctx = WithIdempotencyKey(ctx, opts.IdempotencyKey)
+	}
+	return Create(ctx, fsys, name)
+}
+
+// withCommon applies the options shared by OpenOptions and CreateOptions.
+func withCommon(
+	ctx context.Context, workDir, requestID, userAgent string,
+) context.Context {
+	if workDir != "" {
+		ctx = WithWorkDir(ctx, workDir)
+	}
+	if requestID != "" {
+		ctx = WithRequestID(ctx, requestID)
+	}
+	if userAgent != "" {
+		ctx = WithUserAgent(ctx, userAgent)
+	}
+	return ctx
+}
@@ -0,0 +1,86 @@
+package fs_test
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"lesiw.io/fs"
+	"lesiw.io/fs/memfs"
+)
+
+func TestCreateWith(t *testing.T) {
+	ctx := context.Background()
+	write := func(w fs.WritePathCloser, err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("create error = %v", err)
+		}
+		if _, err := io.WriteString(w, "data"); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatalf("Close() error = %v", err)
+		}
+	}
+
+	chained := memfs.New()
+	cctx := fs.WithFileMode(ctx, 0600)
+	cctx = fs.WithDirMode(cctx, 0700)
+	cctx = fs.WithWorkDir(cctx, "base")
+	w, err := fs.Create(cctx, chained, "sub/file.txt")
+	write(w, err)
+
+	opts := memfs.New()
+	w, err = fs.CreateWith(ctx, opts, "sub/file.txt", fs.CreateOptions{
+		FileMode: 0600,
+		DirMode:  0700,
+		WorkDir:  "base",
+	})
+	write(w, err)
+
+	for _, name := range []string{"base/sub", "base/sub/file.txt"} {
+		want, err := fs.Stat(ctx, chained, name)
+		if err != nil {
+			t.Fatalf("Stat(%q) with chained options error = %v", name, err)
+		}
+		got, err := fs.Stat(ctx, opts, name)
+		if err != nil {
+			t.Fatalf("Stat(%q) with CreateOptions error = %v", name, err)
+		}
+		if got.Mode() != want.Mode() || got.Size() != want.Size() {
+			t.Errorf("Stat(%q) = %v %d, want %v %d", name,
+				got.Mode(), got.Size(), want.Mode(), want.Size())
+		}
+	}
+}
+
+func TestOpenWith(t *testing.T) {
+	ctx, fsys := context.Background(), memfs.New()
+	err := fs.WriteFile(ctx, fsys, "base/file.txt", []byte("0123456789"))
+	if err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	for _, tt := range []struct {
+		opts fs.OpenOptions
+		want string
+	}{
+		{fs.OpenOptions{WorkDir: "base"}, "0123456789"},
+		{fs.OpenOptions{WorkDir: "base", Offset: 4}, "456789"},
+		{fs.OpenOptions{WorkDir: "base", Offset: 2, Length: 3}, "234"},
+	} {
+		r, err := fs.OpenWith(ctx, fsys, "file.txt", tt.opts)
+		if err != nil {
+			t.Fatalf("OpenWith(%+v) error = %v", tt.opts, err)
+		}
+		data, err := io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatalf("ReadAll() error = %v", err)
+		}
+		if got := string(data); got != tt.want {
+			t.Errorf("OpenWith(%+v) = %q, want %q", tt.opts, got, tt.want)
+		}
+	}
+}
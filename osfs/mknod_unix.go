@@ -0,0 +1,43 @@
+//go:build unix && !aix
+
+package osfs
+
+import (
+	"context"
+	"syscall"
+
+	"lesiw.io/fs"
+)
+
+var _ fs.MknodFS = (*osFS)(nil)
+
+func (f *osFS) Mknod(
+	ctx context.Context, name string, mode fs.Mode, dev uint64,
+) error {
+	path, err := f.resolvePath(ctx, name)
+	if err != nil {
+		return err
+	}
+	m := uint32(mode.Perm())
+	switch mode.Type() {
+	case fs.ModeNamedPipe:
+		m |= syscall.S_IFIFO
+	case fs.ModeSocket:
+		m |= syscall.S_IFSOCK
+	case fs.ModeDevice:
+		m |= syscall.S_IFBLK
+	case fs.ModeDevice | fs.ModeCharDevice:
+		m |= syscall.S_IFCHR
+	default:
+		return fs.ErrInvalid
+	}
+	return mknod(syscall.Mknod, path, m, dev)
+}
+
+// mknod calls fn, converting dev to the device number type of the
+// platform's syscall.Mknod.
+func mknod[D int | uint64](
+	fn func(string, uint32, D) error, path string, mode uint32, dev uint64,
+) error {
+	return fn(path, mode, D(dev))
+}
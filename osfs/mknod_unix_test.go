@@ -0,0 +1,81 @@
+//go:build unix && !aix
+
+package osfs
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"lesiw.io/fs"
+)
+
+func TestMkfifo(t *testing.T) {
+	fsys, ctx := NewTemp(), t.Context()
+	defer fs.Close(fsys)
+
+	if err := fs.Mkfifo(ctx, fsys, "dir/pipe", 0o600); err == nil {
+		t.Errorf("Mkfifo(dir/pipe) error = nil, want missing parent error")
+	}
+	if err := fs.Mkfifo(ctx, fsys, "pipe", 0o600); err != nil {
+		t.Fatalf("Mkfifo() error = %v", err)
+	}
+	info, err := fs.Stat(ctx, fsys, "pipe")
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	if got := info.Mode().Type(); got != fs.ModeNamedPipe {
+		t.Errorf("Stat().Mode().Type() = %v, want %v", got, fs.ModeNamedPipe)
+	}
+	err = fs.Mknod(ctx, fsys, "bad", 0o600|fs.ModeDir, 0)
+	if !errors.Is(err, fs.ErrInvalid) {
+		t.Errorf("Mknod(ModeDir) error = %v, want ErrInvalid", err)
+	}
+}
+
+func TestTarFifo(t *testing.T) {
+	fsys, ctx := NewTemp(), t.Context()
+	defer fs.Close(fsys)
+
+	err := fs.WriteFile(ctx, fsys, "src/file.txt", []byte("hi"))
+	if err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if err := fs.Mkdir(ctx, fsys, "src/sub"); err != nil {
+		t.Fatalf("Mkdir() error = %v", err)
+	}
+	if err := fs.Mkfifo(ctx, fsys, "src/sub/pipe", 0o640); err != nil {
+		t.Fatalf("Mkfifo() error = %v", err)
+	}
+
+	r, err := fs.Open(ctx, fsys, "src/")
+	if err != nil {
+		t.Fatalf("Open(src/) error = %v", err)
+	}
+	defer r.Close()
+	w, err := fs.Append(ctx, fsys, "dst/")
+	if err != nil {
+		t.Fatalf("Append(dst/) error = %v", err)
+	}
+	if _, err := io.Copy(w, r); err != nil {
+		t.Fatalf("Copy() error = %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	info, err := fs.Stat(ctx, fsys, "dst/sub/pipe")
+	if err != nil {
+		t.Fatalf("Stat(dst/sub/pipe) error = %v", err)
+	}
+	if got := info.Mode().Type(); got != fs.ModeNamedPipe {
+		t.Errorf("Stat().Mode().Type() = %v, want %v", got, fs.ModeNamedPipe)
+	}
+	data, err := fs.ReadFile(ctx, fsys, "dst/file.txt")
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if string(data) != "hi" {
+		t.Errorf("ReadFile() = %q, want %q", data, "hi")
+	}
+}
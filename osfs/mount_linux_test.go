@@ -0,0 +1,70 @@
+//go:build linux
+
+package osfs
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"syscall"
+	"testing"
+
+	"lesiw.io/fs"
+)
+
+func TestWalkStayOnFilesystem(t *testing.T) {
+	fsys, ctx := NewTemp(), t.Context()
+	defer fs.Close(fsys)
+
+	// Mount a tmpfs outside the tree, then bind it into the tree, so that
+	// the bind mount has a different device than its parent.
+	src := t.TempDir()
+	if err := syscall.Mount("tmpfs", src, "tmpfs", 0, ""); err != nil {
+		t.Skipf("mount tmpfs: %v", err)
+	}
+	defer syscall.Unmount(src, syscall.MNT_DETACH)
+	err := os.WriteFile(filepath.Join(src, "inner.txt"), nil, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"mnt/.keep", "dir/outer.txt"} {
+		if err := fs.WriteFile(ctx, fsys, name, nil); err != nil {
+			t.Fatalf("WriteFile(%q) error = %v", name, err)
+		}
+	}
+	mnt, err := fs.Abs(ctx, fsys, "mnt")
+	if err != nil {
+		t.Fatalf("Abs() error = %v", err)
+	}
+	if err := syscall.Mount(src, mnt, "", syscall.MS_BIND, ""); err != nil {
+		t.Skipf("bind mount: %v", err)
+	}
+	defer syscall.Unmount(mnt, syscall.MNT_DETACH)
+
+	if ok, err := fs.IsMountPoint(ctx, fsys, "mnt"); err != nil || !ok {
+		t.Errorf("IsMountPoint(mnt) = %v, %v, want true, <nil>", ok, err)
+	}
+	if ok, err := fs.IsMountPoint(ctx, fsys, "dir"); err != nil || ok {
+		t.Errorf("IsMountPoint(dir) = %v, %v, want false, <nil>", ok, err)
+	}
+
+	walk := func(ctx context.Context) (names []string) {
+		for e, err := range fs.Walk(ctx, fsys, ".", 0) {
+			if err != nil {
+				t.Fatalf("Walk() error = %v", err)
+			}
+			names = append(names, e.Path())
+		}
+		slices.Sort(names)
+		return names
+	}
+	want := []string{"./dir", "./dir/outer.txt", "./mnt", "./mnt/inner.txt"}
+	if got := walk(ctx); !slices.Equal(got, want) {
+		t.Errorf("Walk() = %v, want %v", got, want)
+	}
+	want = []string{"./dir", "./dir/outer.txt", "./mnt"}
+	if got := walk(fs.WithStayOnFilesystem(ctx)); !slices.Equal(got, want) {
+		t.Errorf("Walk(WithStayOnFilesystem) = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,54 @@
+//go:build unix
+
+package osfs
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"lesiw.io/fs"
+)
+
+func TestOSDefaults(t *testing.T) {
+	// A umask that leaves group write makes the os defaults differ from
+	// the package defaults of 0644 and 0755.
+	defer syscall.Umask(syscall.Umask(0o002))
+
+	dir := t.TempDir()
+	fsys, ctx := New(), fs.WithWorkDir(t.Context(), dir)
+	ctx = fs.WithOSDefaults(ctx)
+
+	f, err := os.Create(filepath.Join(dir, "os.txt"))
+	if err != nil {
+		t.Fatalf("os.Create() error = %v", err)
+	}
+	f.Close()
+	if err := fs.WriteFile(ctx, fsys, "fs.txt", nil); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "os"), 0777); err != nil {
+		t.Fatalf("os.MkdirAll() error = %v", err)
+	}
+	if err := fs.MkdirAll(ctx, fsys, "fs"); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+
+	for _, names := range [][2]string{{"os.txt", "fs.txt"}, {"os", "fs"}} {
+		want, err := os.Stat(filepath.Join(dir, names[0]))
+		if err != nil {
+			t.Fatalf("os.Stat(%q) error = %v", names[0], err)
+		}
+		got, err := fs.Stat(ctx, fsys, names[1])
+		if err != nil {
+			t.Fatalf("Stat(%q) error = %v", names[1], err)
+		}
+		if got.Mode() != want.Mode() {
+			t.Errorf(
+				"Stat(%q).Mode() = %v, want %v as from package os",
+				names[1], got.Mode(), want.Mode(),
+			)
+		}
+	}
+}
@@ -27,6 +27,7 @@ import (
 	"path"
 	"path/filepath"
 	"strings"
+	"syscall"
 	"time"
 
 	"lesiw.io/fs"
@@ -134,6 +135,19 @@ func (f *osFS) Open(ctx context.Context, name string) (io.ReadCloser, error) {
 	return os.Open(path)
 }
 
+var _ fs.SeekFS = (*osFS)(nil)
+
+// CanSeek reports whether name is a regular file. Pipes, sockets, and
+// devices open as *os.File but may not support seeking.
+func (f *osFS) CanSeek(ctx context.Context, name string) bool {
+	path, err := f.resolvePath(ctx, name)
+	if err != nil {
+		return false
+	}
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular()
+}
+
 var _ fs.CreateFS = (*osFS)(nil)
 
 func (f *osFS) Create(
@@ -216,6 +230,34 @@ func (f *osFS) ReadDir(
 	}
 }
 
+var _ fs.CountFS = (*osFS)(nil)
+
+// Count reads only the names in the directory, skipping the per-entry
+// lstat that ReadDir performs.
+func (f *osFS) Count(ctx context.Context, dir string) (int, error) {
+	path, err := f.resolvePath(ctx, dir)
+	if err != nil {
+		return 0, err
+	}
+	d, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer d.Close()
+	var n int
+	for {
+		names, err := d.Readdirnames(1024)
+		n += len(names)
+		if err == io.EOF {
+			return n, nil
+		} else if errors.Is(err, errNotDir) {
+			return 0, &fs.PathError{Op: "count", Path: dir, Err: fs.ErrNotDir}
+		} else if err != nil {
+			return 0, err
+		}
+	}
+}
+
 // dirEntry implements fs.DirEntry without path/depth (for ReadDir).
 type dirEntry struct {
 	name  string
@@ -299,6 +341,42 @@ func (f *osFS) Symlink(ctx context.Context, oldname, newname string) error {
 	return os.Symlink(oldname, newpath)
 }
 
+var _ fs.LinkFS = (*osFS)(nil)
+
+func (f *osFS) Link(ctx context.Context, oldname, newname string) error {
+	oldpath, err := f.resolvePath(ctx, oldname)
+	if err != nil {
+		return err
+	}
+	newpath, err := f.resolvePath(ctx, newname)
+	if err != nil {
+		return err
+	}
+	return os.Link(oldpath, newpath)
+}
+
+var _ fs.SameFileFS = (*osFS)(nil)
+
+func (f *osFS) SameFile(ctx context.Context, a, b string) (bool, error) {
+	apath, err := f.resolvePath(ctx, a)
+	if err != nil {
+		return false, err
+	}
+	bpath, err := f.resolvePath(ctx, b)
+	if err != nil {
+		return false, err
+	}
+	ainfo, err := os.Stat(apath)
+	if err != nil {
+		return false, err
+	}
+	binfo, err := os.Stat(bpath)
+	if err != nil {
+		return false, err
+	}
+	return os.SameFile(ainfo, binfo), nil
+}
+
 var _ fs.ReadLinkFS = (*osFS)(nil)
 
 func (f *osFS) ReadLink(ctx context.Context, name string) (string, error) {
@@ -317,6 +395,33 @@ func (f *osFS) Lstat(ctx context.Context, name string) (fs.FileInfo, error) {
 	return os.Lstat(path)
 }
 
+var _ fs.SysFS = (*osFS)(nil)
+
+func (f *osFS) SysConn(
+	ctx context.Context, name string,
+) (fs.RawConn, error) {
+	path, err := f.resolvePath(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	rc, err := file.SyscallConn()
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	return rawConn{rc, file}, nil
+}
+
+// rawConn ties a syscall.RawConn to the file it came from.
+type rawConn struct {
+	syscall.RawConn
+	io.Closer
+}
+
 var _ fs.LocalizeFS = (*osFS)(nil)
 
 func (f *osFS) Localize(ctx context.Context, path string) (string, error) {
@@ -5,6 +5,8 @@ package osfs
 import (
 	"context"
 	"os"
+	"path/filepath"
+	"syscall"
 
 	"lesiw.io/fs"
 )
@@ -28,3 +30,31 @@ func (f *osFS) Chown(ctx context.Context, name string, uid, gid int) error {
 	}
 	return os.Chown(path, uid, gid)
 }
+
+var _ fs.MountFS = (*osFS)(nil)
+
+// IsMountPoint compares the device of name with that of its parent. A
+// directory that is its own parent, such as /, is also a mount point.
+func (f *osFS) IsMountPoint(ctx context.Context, name string) (bool, error) {
+	path, err := f.resolvePath(ctx, name)
+	if err != nil {
+		return false, err
+	}
+	info, err := os.Lstat(path)
+	if err != nil {
+		return false, err
+	}
+	if !info.IsDir() {
+		return false, nil
+	}
+	parent, err := os.Stat(filepath.Join(path, ".."))
+	if err != nil {
+		return false, err
+	}
+	st, ok := info.Sys().(*syscall.Stat_t)
+	pst, pok := parent.Sys().(*syscall.Stat_t)
+	if !ok || !pok {
+		return false, fs.ErrUnsupported
+	}
+	return st.Dev != pst.Dev || st.Ino == pst.Ino, nil
+}
@@ -0,0 +1,57 @@
+//go:build unix
+
+package osfs
+
+import (
+	stdpath "path"
+	"slices"
+	"testing"
+	"time"
+
+	"lesiw.io/fs"
+)
+
+func TestWalkSymlinkLoop(t *testing.T) {
+	fsys, ctx := NewTemp(), t.Context()
+	defer fs.Close(fsys)
+
+	if err := fs.WriteFile(ctx, fsys, "dir/file.txt", nil); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	// dir/link points back at dir.
+	if err := fs.Symlink(ctx, fsys, ".", "dir/link"); err != nil {
+		t.Fatalf("Symlink() error = %v", err)
+	}
+
+	done := make(chan struct{})
+	var paths, links []string
+	go func() {
+		defer close(done)
+		for e, err := range fs.Walk(ctx, fsys, "dir", -1) {
+			if err != nil {
+				t.Errorf("Walk() error = %v", err)
+				continue
+			}
+			p := stdpath.Clean(e.Path())
+			paths = append(paths, p)
+			if e.Type()&fs.ModeSymlink != 0 {
+				links = append(links, p)
+			}
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("Walk() did not terminate")
+	}
+
+	// The link is yielded once, and nothing is yielded from beneath it.
+	slices.Sort(paths)
+	want := []string{"dir/file.txt", "dir/link"}
+	if !slices.Equal(paths, want) {
+		t.Errorf("Walk() = %q, want %q", paths, want)
+	}
+	if want := []string{"dir/link"}; !slices.Equal(links, want) {
+		t.Errorf("Walk() symlinks = %q, want %q", links, want)
+	}
+}
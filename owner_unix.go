@@ -0,0 +1,12 @@
+//go:build unix
+
+package fs
+
+import "syscall"
+
+func sysOwner(sys any) (uid, gid int, ok bool) {
+	if st, ok := sys.(*syscall.Stat_t); ok {
+		return int(st.Uid), int(st.Gid), true
+	}
+	return 0, 0, false
+}
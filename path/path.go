@@ -160,6 +160,28 @@ func Dir(path string) string {
 	return dir
 }
 
+// Ext returns the file name extension used by path: the suffix beginning
+// at the final dot in its last element. Returns "" if the last element has
+// no dot or path has a trailing separator (directory).
+//
+// Unlike the standard library's Ext, leading dots do not start an
+// extension, so dotfiles like .gitignore have none, while .bashrc.bak has
+// the extension .bak.
+//
+// Examples:
+//
+//	Ext("a/b.tar.gz")                    // ".gz"
+//	Ext(`C:\foo\bar.txt`)                // ".txt"
+//	Ext("https://example.com/a.tar.gz")  // ".gz"
+//	Ext(".gitignore")                    // ""
+func Ext(path string) string {
+	name := strings.TrimLeft(Base(path), ".")
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		return name[i:]
+	}
+	return ""
+}
+
 // IsDir reports whether the path is lexically a directory.
 // A path is a directory if it has a trailing separator.
 func IsDir(path string) bool {
@@ -368,10 +390,17 @@ func Clean(path string) string {
 // An error is returned if targpath can't be made relative to basepath or if
 // knowing the current working directory would be necessary to compute it.
 //
-// The result uses basepath's detected separator style. When basepath has no
-// strong style signal (no backslashes, drive letters, or URL protocols),
-// Unix style is used.
+// The result uses the style the two paths share, so Rel(`C:\a\b`,
+// `C:\a\c`) is `..\c`. A path without separators, drive letters, or URL
+// protocols takes the style of the other; if neither has one, Unix style is
+// used. An error is returned if the paths have different styles.
 func Rel(basepath, targpath string) (string, error) {
+	style, ok := sharedStyle(basepath, targpath)
+	if !ok {
+		return "", fmt.Errorf(
+			"Rel: %s and %s have different path styles", targpath, basepath,
+		)
+	}
 	baseSeg, targSeg := segments(basepath), segments(targpath)
 	baseAbs, targAbs := IsAbs(basepath), IsAbs(targpath)
 
@@ -418,10 +447,29 @@ func Rel(basepath, targpath string) (string, error) {
 		return ".", nil
 	}
 
-	style := detectStyle([]string{basepath})
 	return strings.Join(result, string(style.sep)), nil
 }
 
+// sharedStyle returns the style of a and b, reporting false if both show a
+// style and the styles differ.
+func sharedStyle(a, b string) (pathStyle, bool) {
+	as, bs := detectStyle([]string{a}), detectStyle([]string{b})
+	switch {
+	case !hasStyle(a):
+		return bs, true
+	case !hasStyle(b):
+		return as, true
+	}
+	return as, as == bs
+}
+
+// hasStyle reports whether p carries a style signal that detectStyle
+// recognizes: a separator, a drive letter, or a URL protocol.
+func hasStyle(p string) bool {
+	return strings.ContainsAny(p, `/\`) ||
+		len(p) >= 2 && p[1] == ':' && isDriveLetter(p[0])
+}
+
 // segments returns the individual elements of path after cleaning.
 // Unlike splitAll, which preserves roots and prefixes for reassembly,
 // segments strips volume names, root separators, local prefixes (./ or .\),
@@ -652,6 +700,55 @@ func Match(pattern, name string) (matched bool, err error) {
 	return stdpath.Match(pattern, name)
 }
 
+// MatchRecursive reports whether name matches the shell pattern, where a
+// path segment consisting of ** matches zero or more whole segments.
+// Every other segment is matched as by [Match], so logs/**/*.txt matches
+// logs/a.txt and logs/x/y/a.txt, and dir/** matches everything beneath
+// dir. Segments are separated by slashes.
+//
+// The only possible returned error is [ErrBadPattern], when pattern is
+// malformed.
+func MatchRecursive(pattern, name string) (matched bool, err error) {
+	if _, err := stdpath.Match(pattern, ""); err != nil {
+		return false, err
+	}
+	pat, segs := strings.Split(pattern, "/"), strings.Split(name, "/")
+
+	// Match segments as in wildcard matching, with ** as the wildcard.
+	// On a mismatch, the most recent ** absorbs one more segment and
+	// matching resumes after it. Earlier ** never need to absorb more,
+	// which keeps the match linear in the number of backtracks.
+	var p, n int
+	star, starN := -1, 0
+	for n < len(segs) {
+		if p < len(pat) && pat[p] == "**" {
+			star, starN = p, n
+			p++
+			continue
+		}
+		if p < len(pat) {
+			ok, err := stdpath.Match(pat[p], segs[n])
+			if err != nil {
+				return false, err
+			}
+			if ok {
+				p++
+				n++
+				continue
+			}
+		}
+		if star < 0 {
+			return false, nil
+		}
+		starN++
+		p, n = star+1, starN
+	}
+	for p < len(pat) && pat[p] == "**" {
+		p++
+	}
+	return p == len(pat), nil
+}
+
 // ErrBadPattern indicates a pattern was malformed.
 // This is an alias to avoid importing both packages.
 var ErrBadPattern = stdpath.ErrBadPattern
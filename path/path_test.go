@@ -226,6 +226,49 @@ func TestIsRoot(t *testing.T) {
 	}
 }
 
+func TestExt(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		// Unix-style paths
+		{"UnixSimple", "file.txt", ".txt"},
+		{"UnixNested", "a/b/file.go", ".go"},
+		{"UnixMultiple", "a/b.tar.gz", ".gz"},
+		{"UnixNone", "a/Makefile", ""},
+		{"UnixDotDir", "a.d/file", ""},
+		{"UnixTrailingSep", "a/b.d/", ""},
+		{"UnixDotfile", ".gitignore", ""},
+		{"UnixNestedDotfile", "a/.gitignore", ""},
+		{"UnixDotfileExt", "a/.bashrc.bak", ".bak"},
+		{"UnixTrailingDot", "a/file.", "."},
+		{"UnixDotDot", "a/..", ""},
+		{"UnixRoot", "/", ""},
+		{"UnixEmpty", "", ""},
+
+		// Windows-style paths
+		{"WindowsSimple", `C:\foo\bar.txt`, ".txt"},
+		{"WindowsRelative", `foo\bar.tar.gz`, ".gz"},
+		{"WindowsTrailingSep", `C:\foo.d\`, ""},
+		{"WindowsRoot", `C:\`, ""},
+		{"WindowsDotfile", `C:\foo\.gitignore`, ""},
+
+		// URL-style paths
+		{"URLSimple", "https://example.com/a.tar.gz", ".gz"},
+		{"URLHost", "https://example.com", ""},
+		{"URLTrailingSep", "https://example.com/a.d/", ""},
+		{"URLNoExt", "https://example.com/a.d/file", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Ext(tt.path); got != tt.want {
+				t.Errorf("Ext(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
 func TestIsAbs(t *testing.T) {
 	tests := []struct {
 		path string
@@ -464,12 +507,17 @@ func TestRel(t *testing.T) {
 		{"MixedAbsURLWin", "https://example.com/foo",
 			`C:\win\path`, "", true},
 
-		// Mixed separators in relative paths: uses basepath's style
-		{"MixedSepBaseUnix", "./foo", `bar\baz`, "../bar/baz", false},
-		{"MixedSepBaseWin", `.\foo`, "bar/baz", `..\bar\baz`, false},
-		{"MixedSepRelUnix", "foo/bar", `baz\qux`, "../../baz/qux", false},
-		{"MixedSepRelWin", `foo\bar`, "baz/qux", `..\..\baz\qux`, false},
+		// Mixed separators in relative paths: errors
+		{"MixedSepBaseUnix", "./foo", `bar\baz`, "", true},
+		{"MixedSepBaseWin", `.\foo`, "bar/baz", "", true},
+		{"MixedSepRelUnix", "foo/bar", `baz\qux`, "", true},
+		{"MixedSepRelWin", `foo\bar`, "baz/qux", "", true},
+
+		// A path without a style takes the other's style
 		{"MixedSepChild", "foo", `foo\bar`, "bar", false},
+		{"StylelessBaseWin", "foo", `bar\baz`, `..\bar\baz`, false},
+		{"StylelessBaseUnix", "foo", "bar/baz", "../bar/baz", false},
+		{"StylelessTargWin", `foo\bar`, "baz", `..\..\baz`, false},
 	}
 
 	for _, tt := range tests {
@@ -505,6 +553,53 @@ func TestRel(t *testing.T) {
 	}
 }
 
+func TestMatchRecursive(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+		wantErr bool
+	}{
+		{"*.txt", "a.txt", true, false},
+		{"*.txt", "x/a.txt", false, false},
+		{"**", "a", true, false},
+		{"**", "a/b/c", true, false},
+		{"logs/**/*.txt", "logs/a.txt", true, false},
+		{"logs/**/*.txt", "logs/x/y/a.txt", true, false},
+		{"logs/**/*.txt", "logs/x/y/a.go", false, false},
+		{"logs/**/*.txt", "other/a.txt", false, false},
+		{"a/**/b", "a/b", true, false},
+		{"a/**/b", "a/x/b", true, false},
+		{"a/**/b", "a/x/y/b", true, false},
+		{"a/**/b", "a/b/x", false, false},
+		{"a/**/b/**/c", "a/b/x/b/y/c", true, false},
+		{"a/**/b/**/c", "a/x/c", false, false},
+		{"dir/**", "dir/x", true, false},
+		{"dir/**", "dir/x/y", true, false},
+		{"dir/**", "other/x", false, false},
+		{"a**/b", "ax/b", true, false},
+		{"a**/b", "ax/y/b", false, false},
+		{"[", "a", false, true},
+		{"**/[", "a", false, true},
+	}
+	for _, tt := range tests {
+		got, err := MatchRecursive(tt.pattern, tt.name)
+		if (err != nil) != tt.wantErr {
+			t.Errorf(
+				"MatchRecursive(%q, %q) error = %v, wantErr %v",
+				tt.pattern, tt.name, err, tt.wantErr,
+			)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf(
+				"MatchRecursive(%q, %q) = %v, want %v",
+				tt.pattern, tt.name, got, tt.want,
+			)
+		}
+	}
+}
+
 func TestVolume(t *testing.T) {
 	tests := []struct {
 		name string
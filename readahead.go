@@ -0,0 +1,109 @@
+package fs
+
+import (
+	"context"
+	"io"
+	"os"
+	"sync"
+)
+
+// readaheadReader prefetches from src into a ring buffer from a background
+// goroutine, so that the next bytes are usually ready when Read is called.
+type readaheadReader struct {
+	src  io.ReadCloser
+	stop func() bool
+
+	mu     sync.Mutex
+	cond   sync.Cond
+	buf    []byte
+	off, n int   // buf[off:off+n], wrapping, holds unread bytes
+	err    error // error from src or ctx, returned once buf is drained
+	closed bool
+}
+
+// newReadahead returns r wrapped in a reader that prefetches up to n bytes,
+// or r itself if it is an *os.File.
+func newReadahead(ctx context.Context, r io.ReadCloser, n int) io.ReadCloser {
+	if _, ok := r.(*os.File); ok {
+		return r
+	}
+	ra := &readaheadReader{src: r, buf: make([]byte, n)}
+	ra.cond.L = &ra.mu
+	ra.stop = context.AfterFunc(ctx, func() { ra.fail(ctx.Err()) })
+	go ra.fill()
+	return ra
+}
+
+func (ra *readaheadReader) fill() {
+	for {
+		ra.mu.Lock()
+		for ra.n == len(ra.buf) && ra.err == nil && !ra.closed {
+			ra.cond.Wait()
+		}
+		if ra.err != nil || ra.closed {
+			ra.mu.Unlock()
+			return
+		}
+		// Only the free region is filled, which Read never touches, so
+		// the lock can be released for the duration of the read.
+		start := (ra.off + ra.n) % len(ra.buf)
+		end := len(ra.buf)
+		if start < ra.off {
+			end = ra.off
+		}
+		ra.mu.Unlock()
+
+		m, err := ra.src.Read(ra.buf[start:end])
+
+		ra.mu.Lock()
+		ra.n += m
+		ra.cond.Broadcast()
+		ra.mu.Unlock()
+		if err != nil {
+			ra.fail(err)
+			return
+		}
+	}
+}
+
+// fail records err, if no error was recorded yet, and wakes any waiters.
+func (ra *readaheadReader) fail(err error) {
+	ra.mu.Lock()
+	defer ra.mu.Unlock()
+	if ra.err == nil {
+		ra.err = err
+	}
+	ra.cond.Broadcast()
+}
+
+func (ra *readaheadReader) Read(p []byte) (int, error) {
+	ra.mu.Lock()
+	defer ra.mu.Unlock()
+	for ra.n == 0 && ra.err == nil && !ra.closed {
+		ra.cond.Wait()
+	}
+	if ra.closed {
+		return 0, ErrClosed
+	}
+	if ra.n == 0 {
+		return 0, ra.err
+	}
+	m := copy(p, ra.buf[ra.off:min(ra.off+ra.n, len(ra.buf))])
+	ra.off = (ra.off + m) % len(ra.buf)
+	ra.n -= m
+	ra.cond.Broadcast()
+	return m, nil
+}
+
+func (ra *readaheadReader) Close() error {
+	ra.mu.Lock()
+	if ra.closed {
+		ra.mu.Unlock()
+		return nil
+	}
+	ra.closed = true
+	ra.cond.Broadcast()
+	ra.mu.Unlock()
+	ra.stop()
+	return ra.src.Close()
+}
@@ -0,0 +1,124 @@
+package fs_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"lesiw.io/fs"
+)
+
+// latencyFS serves data in chunk-sized reads, each taking lag, and then fails
+// with err, or io.EOF if err is nil.
+type latencyFS struct {
+	data  []byte
+	chunk int
+	lag   time.Duration
+	err   error
+}
+
+func (f *latencyFS) Open(
+	ctx context.Context, name string,
+) (io.ReadCloser, error) {
+	return io.NopCloser(&latencyReader{f, bytes.NewReader(f.data)}), nil
+}
+
+type latencyReader struct {
+	fsys *latencyFS
+	r    *bytes.Reader
+}
+
+func (r *latencyReader) Read(p []byte) (int, error) {
+	time.Sleep(r.fsys.lag)
+	if len(p) > r.fsys.chunk {
+		p = p[:r.fsys.chunk]
+	}
+	n, err := r.r.Read(p)
+	if err == io.EOF && r.fsys.err != nil {
+		err = r.fsys.err
+	}
+	return n, err
+}
+
+func TestReadahead(t *testing.T) {
+	const chunk, chunks = 1024, 20
+	lag := 5 * time.Millisecond
+	data := bytes.Repeat([]byte("x"), chunk*chunks)
+	fsys := &latencyFS{data: data, chunk: chunk, lag: lag}
+
+	// process reads the file one chunk at a time, spending as long on each
+	// chunk as the backend takes to produce it.
+	process := func(ctx context.Context) time.Duration {
+		start := time.Now()
+		r, err := fs.Open(ctx, fsys, "file")
+		if err != nil {
+			t.Fatalf("Open() error = %v", err)
+		}
+		defer r.Close()
+		var got []byte
+		buf := make([]byte, chunk)
+		for {
+			n, err := io.ReadFull(r, buf)
+			got = append(got, buf[:n]...)
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatalf("Read() error = %v", err)
+			}
+			time.Sleep(lag)
+		}
+		if !bytes.Equal(got, data) {
+			t.Fatalf("read %d bytes, want %d matching bytes",
+				len(got), len(data))
+		}
+		return time.Since(start)
+	}
+
+	ctx := context.Background()
+	plain := process(ctx)
+	ahead := process(fs.WithReadahead(ctx, 4*chunk))
+	if ahead >= plain*3/4 {
+		t.Errorf("with readahead took %v, without took %v", ahead, plain)
+	}
+}
+
+func TestReadaheadError(t *testing.T) {
+	errBroken := errors.New("connection reset")
+	data := []byte("partial data")
+	fsys := &latencyFS{data: data, chunk: 4, err: errBroken}
+	ctx := fs.WithReadahead(context.Background(), 8)
+
+	r, err := fs.Open(ctx, fsys, "file")
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer r.Close()
+	got, err := io.ReadAll(r)
+	if !errors.Is(err, errBroken) {
+		t.Errorf("ReadAll() error = %v, want %v", err, errBroken)
+	}
+	if !bytes.Equal(got, data) {
+		t.Errorf("ReadAll() = %q, want %q", got, data)
+	}
+}
+
+func TestReadaheadCancel(t *testing.T) {
+	fsys := &latencyFS{
+		data:  bytes.Repeat([]byte("x"), 1<<20),
+		chunk: 1024,
+		lag:   time.Millisecond,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	r, err := fs.Open(fs.WithReadahead(ctx, 4096), fsys, "file")
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer r.Close()
+	cancel()
+	if _, err := io.ReadAll(r); !errors.Is(err, context.Canceled) {
+		t.Errorf("ReadAll() error = %v, want context.Canceled", err)
+	}
+}
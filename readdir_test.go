@@ -3,9 +3,14 @@ package fs_test
 import (
 	"context"
 	"fmt"
+	"iter"
 	"log"
+	"path"
+	"slices"
+	"testing"
 
 	"lesiw.io/fs"
+	"lesiw.io/fs/memfs"
 	"lesiw.io/fs/osfs"
 )
 
@@ -41,3 +46,137 @@ func ExampleWalk_singleLevel() {
 	// - file1.txt (dir: false)
 	// - file2.txt (dir: false)
 }
+
+// markerFS emits object-store style directory markers in its listings.
+type markerFS struct{ fs.FS }
+
+func (markerFS) ReadDir(
+	ctx context.Context, name string,
+) iter.Seq2[fs.DirEntry, error] {
+	listings := map[string][]fs.DirEntry{
+		".":   {dirEntry{"dir", true}, dirEntry{"dir", true}},
+		"dir": {dirEntry{"", true}, dirEntry{"file", false}},
+	}
+	return func(yield func(fs.DirEntry, error) bool) {
+		for _, e := range listings[path.Clean(name)] {
+			if !yield(e, nil) {
+				return
+			}
+		}
+	}
+}
+
+type dirEntry struct {
+	name  string
+	isDir bool
+}
+
+func (e dirEntry) Name() string { return e.name }
+func (e dirEntry) IsDir() bool  { return e.isDir }
+func (e dirEntry) Path() string { return "" }
+func (e dirEntry) Type() fs.Mode {
+	if e.isDir {
+		return fs.ModeDir
+	}
+	return 0
+}
+func (e dirEntry) Info() (fs.FileInfo, error) { return nil, fs.ErrUnsupported }
+
+func TestReadDirDedupe(t *testing.T) {
+	ctx, fsys := context.Background(), markerFS{memfs.New()}
+
+	for name, want := range map[string][]string{
+		".":   {"dir"},
+		"dir": {"file"},
+	} {
+		var got []string
+		for e, err := range fs.ReadDir(ctx, fsys, name) {
+			if err != nil {
+				t.Fatalf("ReadDir(%q) error = %v", name, err)
+			}
+			got = append(got, e.Name())
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("ReadDir(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestWithSkipHidden(t *testing.T) {
+	ctx := context.Background()
+	osFS := osfs.NewTemp()
+	defer fs.Close(osFS)
+
+	for name, fsys := range map[string]fs.FS{
+		"memfs": memfs.New(),
+		"osfs":  osFS,
+	} {
+		t.Run(name, func(t *testing.T) {
+			for _, file := range []string{
+				".hidden", "visible", ".git/config", "dir/.env", "dir/a",
+			} {
+				err := fs.WriteFile(ctx, fsys, file, []byte(file))
+				if err != nil {
+					t.Fatalf("WriteFile(%q) error = %v", file, err)
+				}
+			}
+
+			readDir := func(ctx context.Context) (names []string) {
+				for e, err := range fs.ReadDir(ctx, fsys, ".") {
+					if err != nil {
+						t.Fatalf("ReadDir() error = %v", err)
+					}
+					names = append(names, e.Name())
+				}
+				slices.Sort(names)
+				return
+			}
+			walk := func(ctx context.Context) (names []string) {
+				for e, err := range fs.Walk(ctx, fsys, ".", 0) {
+					if err != nil {
+						t.Fatalf("Walk() error = %v", err)
+					}
+					names = append(names, e.Name())
+				}
+				slices.Sort(names)
+				return
+			}
+
+			hidden := fs.WithSkipHidden(ctx)
+			if got, want := readDir(hidden), []string{
+				"dir", "visible",
+			}; !slices.Equal(got, want) {
+				t.Errorf("ReadDir(skip hidden) = %v, want %v", got, want)
+			}
+			if got, want := walk(hidden), []string{
+				"a", "dir", "visible",
+			}; !slices.Equal(got, want) {
+				t.Errorf("Walk(skip hidden) = %v, want %v", got, want)
+			}
+			matches, err := fs.Glob(hidden, fsys, "*")
+			if err != nil {
+				t.Fatalf("Glob() error = %v", err)
+			}
+			for i, m := range matches {
+				matches[i] = path.Base(m)
+			}
+			slices.Sort(matches)
+			if want := []string{"dir", "visible"}; !slices.Equal(
+				matches, want,
+			) {
+				t.Errorf("Glob(skip hidden) = %v, want %v", matches, want)
+			}
+
+			if got, want := readDir(ctx), []string{
+				".git", ".hidden", "dir", "visible",
+			}; !slices.Equal(got, want) {
+				t.Errorf("ReadDir() = %v, want %v", got, want)
+			}
+			if got, want := walk(ctx), []string{
+				".env", ".git", ".hidden", "a", "config", "dir", "visible",
+			}; !slices.Equal(got, want) {
+				t.Errorf("Walk() = %v, want %v", got, want)
+			}
+		})
+	}
+}
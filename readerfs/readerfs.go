@@ -0,0 +1,54 @@
+// Package readerfs implements lesiw.io/fs.FS over a single io.Reader.
+//
+// It lets command-line tools that accept "-" for standard input treat the
+// stream like any other file:
+//
+//	fsys := readerfs.New("-", os.Stdin)
+//	data, err := fs.ReadFile(ctx, fsys, "-")
+package readerfs
+
+import (
+	"context"
+	"io"
+	stdpath "path"
+	"sync"
+
+	"lesiw.io/fs"
+)
+
+// New returns a filesystem holding one file, name, whose contents are read
+// from r.
+//
+// Because r is a stream, the file can be opened only once. Opening it
+// again reports [fs.ErrClosed], and opening any other name reports
+// [fs.ErrNotExist]. Closing the opened file does not close r.
+func New(name string, r io.Reader) fs.FS {
+	return &readerFS{name: clean(name), r: r}
+}
+
+type readerFS struct {
+	name string
+
+	mu sync.Mutex
+	r  io.Reader // nil once opened
+}
+
+func clean(name string) string {
+	return stdpath.Clean("/" + name)[1:]
+}
+
+func (f *readerFS) Open(
+	ctx context.Context, name string,
+) (io.ReadCloser, error) {
+	if clean(name) != f.name {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.r == nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrClosed}
+	}
+	r := f.r
+	f.r = nil
+	return io.NopCloser(r), nil
+}
@@ -0,0 +1,37 @@
+package readerfs
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"lesiw.io/fs"
+)
+
+func TestOpen(t *testing.T) {
+	fsys, ctx := New("-", strings.NewReader("hello")), t.Context()
+
+	if _, err := fs.Open(ctx, fsys, "other"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Open(%q) error = %v, want ErrNotExist", "other", err)
+	}
+
+	r, err := fs.Open(ctx, fsys, "-")
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	if got, want := string(data), "hello"; got != want {
+		t.Errorf("ReadAll() = %q, want %q", got, want)
+	}
+	if err := r.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+
+	if _, err := fs.Open(ctx, fsys, "-"); !errors.Is(err, fs.ErrClosed) {
+		t.Errorf("Open() again error = %v, want ErrClosed", err)
+	}
+}
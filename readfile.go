@@ -1,13 +1,20 @@
 package fs
 
 import (
+	"bytes"
 	"context"
+	"encoding/binary"
 	"io"
+	"unicode/utf16"
+	"unicode/utf8"
 )
 
 // ReadFile reads the named file and returns its contents.
 // Analogous to: [io/fs.ReadFile], [os.ReadFile], cat.
 //
+// Under [WithStripBOM], a leading byte order mark is removed, and UTF-16
+// content is decoded to UTF-8.
+//
 // Requires: [FS]
 func ReadFile(ctx context.Context, fsys FS, name string) ([]byte, error) {
 	f, err := Open(ctx, fsys, name)
@@ -15,5 +22,39 @@ func ReadFile(ctx context.Context, fsys FS, name string) ([]byte, error) {
 		return nil, err
 	}
 	defer f.Close()
-	return io.ReadAll(f)
+	data, err := io.ReadAll(f)
+	if err != nil || !stripBOM(ctx) {
+		return data, err
+	}
+	return decodeBOM(data), nil
+}
+
+// decodeBOM removes a leading byte order mark from data, decoding UTF-16
+// to UTF-8. Data without a BOM is returned as is.
+func decodeBOM(data []byte) []byte {
+	var order binary.ByteOrder
+	switch {
+	case bytes.HasPrefix(data, []byte{0xEF, 0xBB, 0xBF}):
+		return data[3:]
+	case bytes.HasPrefix(data, []byte{0xFF, 0xFE}):
+		order = binary.LittleEndian
+	case bytes.HasPrefix(data, []byte{0xFE, 0xFF}):
+		order = binary.BigEndian
+	default:
+		return data
+	}
+	data = data[2:]
+	units := make([]uint16, len(data)/2)
+	for i := range units {
+		units[i] = order.Uint16(data[2*i:])
+	}
+	out := make([]byte, 0, len(data))
+	for _, r := range utf16.Decode(units) {
+		out = utf8.AppendRune(out, r)
+	}
+	if len(data)%2 != 0 {
+		// A trailing odd byte is half a code unit.
+		out = utf8.AppendRune(out, utf8.RuneError)
+	}
+	return out
 }
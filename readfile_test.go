@@ -4,8 +4,10 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"testing"
 
 	"lesiw.io/fs"
+	"lesiw.io/fs/memfs"
 	"lesiw.io/fs/osfs"
 )
 
@@ -26,3 +28,43 @@ func ExampleReadFile() {
 	// Output:
 	// Hello, World!
 }
+
+func TestReadFileStripBOM(t *testing.T) {
+	ctx, fsys := context.Background(), memfs.New()
+
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"NoBOM", "héllo", "héllo"},
+		{"UTF8", "\xEF\xBB\xBFhéllo", "héllo"},
+		{"UTF16LE", "\xFF\xFEh\x00\xE9\x00=\xD8\x00\xDE", "hé😀"},
+		{"UTF16BE", "\xFE\xFF\x00h\x00\xE9\xD8=\xDE\x00", "hé😀"},
+		{"UTF16Odd", "\xFF\xFEh\x00!", "h\uFFFD"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := fs.WriteFile(ctx, fsys, tt.name, []byte(tt.data))
+			if err != nil {
+				t.Fatalf("WriteFile() error = %v", err)
+			}
+
+			got, err := fs.ReadFile(fs.WithStripBOM(ctx), fsys, tt.name)
+			if err != nil {
+				t.Fatalf("ReadFile() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("ReadFile() = %q, want %q", got, tt.want)
+			}
+
+			raw, err := fs.ReadFile(ctx, fsys, tt.name)
+			if err != nil {
+				t.Fatalf("ReadFile() error = %v", err)
+			}
+			if string(raw) != tt.data {
+				t.Errorf("ReadFile() unstripped = %q, want %q", raw, tt.data)
+			}
+		})
+	}
+}
@@ -0,0 +1,521 @@
+package fs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"iter"
+	"slices"
+	"sync"
+	"time"
+
+	"lesiw.io/fs/path"
+)
+
+// An Op is one mutating operation captured by [Record].
+type Op struct {
+	// Op names the operation, as in [PathError]: "create", "append",
+	// "appenddir", "mkdir", "mkdirall", "remove", "removeall", "rename",
+	// "copy", "link", "symlink", "mknod", "truncate", "truncatedir",
+	// "chmod", "chown", "chtimes", or "trash".
+	Op string
+
+	// Path is the file the operation acted on. For rename, copy, link,
+	// and symlink it is the old name, and NewPath is the new name.
+	Path    string
+	NewPath string
+
+	// Data holds the bytes written by create, append, and appenddir, and
+	// Size is their length. For truncate, Size is the new size.
+	Data []byte
+	Size int64
+
+	// Mode is the new mode for chmod and mknod. For every other operation,
+	// Mode and DirMode are the [FileMode] and [DirMode] carried by the
+	// context, so that files and directories are replayed with the same
+	// permissions.
+	Mode    Mode
+	DirMode Mode
+
+	UID, GID     int       // Owner for chown.
+	Atime, Mtime time.Time // Times for chtimes.
+	Dev          uint64    // Device number for mknod.
+}
+
+// An OpLog is an ordered log of the operations captured by [Record]. It is
+// safe for concurrent use.
+type OpLog struct {
+	mu  sync.Mutex
+	ops []Op
+}
+
+func (l *OpLog) add(op Op) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.ops = append(l.ops, op)
+}
+
+// Ops returns a copy of the operations recorded so far, in the order they
+// completed.
+func (l *OpLog) Ops() []Op {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return slices.Clone(l.ops)
+}
+
+// Replay applies the recorded operations to target, in order, through the
+// package-level helpers. It stops at the first error.
+func (l *OpLog) Replay(ctx context.Context, target FS) error {
+	for _, op := range l.Ops() {
+		if err := op.apply(ctx, target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (op Op) apply(ctx context.Context, fsys FS) error {
+	if op.Op != "chmod" && op.Op != "mknod" {
+		ctx = WithFileMode(WithDirMode(ctx, op.DirMode), op.Mode)
+	}
+	switch op.Op {
+	case "create", "append", "appenddir":
+		var w io.WriteCloser
+		var err error
+		switch op.Op {
+		case "create":
+			w, err = Create(ctx, fsys, op.Path)
+		case "append":
+			w, err = Append(ctx, fsys, op.Path)
+		default:
+			w, err = Append(ctx, fsys, path.Join(op.Path, ""))
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(op.Data); err != nil {
+			_ = w.Close()
+			return &PathError{Op: "write", Path: op.Path, Err: err}
+		}
+		return w.Close()
+	case "mkdir":
+		return Mkdir(ctx, fsys, op.Path)
+	case "mkdirall":
+		return MkdirAll(ctx, fsys, op.Path)
+	case "remove":
+		return Remove(ctx, fsys, op.Path)
+	case "removeall":
+		return RemoveAll(ctx, fsys, op.Path)
+	case "rename":
+		return Rename(ctx, fsys, op.Path, op.NewPath)
+	case "copy":
+		return Copy(ctx, fsys, op.Path, op.NewPath)
+	case "link":
+		return Link(ctx, fsys, op.Path, op.NewPath)
+	case "symlink":
+		return Symlink(ctx, fsys, op.Path, op.NewPath)
+	case "mknod":
+		return Mknod(ctx, fsys, op.Path, op.Mode, op.Dev)
+	case "truncate":
+		return Truncate(ctx, fsys, op.Path, op.Size)
+	case "truncatedir":
+		return Truncate(ctx, fsys, path.Join(op.Path, ""), 0)
+	case "chmod":
+		return Chmod(ctx, fsys, op.Path, op.Mode)
+	case "chown":
+		return Chown(ctx, fsys, op.Path, op.UID, op.GID)
+	case "chtimes":
+		return Chtimes(ctx, fsys, op.Path, op.Atime, op.Mtime)
+	case "trash":
+		return Trash(ctx, fsys, op.Path)
+	}
+	return &PathError{Op: op.Op, Path: op.Path, Err: ErrInvalid}
+}
+
+// Record returns a filesystem that forwards every operation to fsys and
+// logs each mutating operation that succeeds to the returned [OpLog].
+// Replaying the log onto an empty filesystem reproduces the changes made
+// through the wrapper, which makes it useful for audits and for turning a
+// bug report into a test case.
+//
+// Writes are logged when their stream is closed successfully, with the
+// full data written. Paths are logged as passed to the wrapper, so replay
+// with the same working directory, if any. Temporary files from [Temp] and
+// anything written through [SysConn] are not logged, and multipart uploads
+// fall back to Create so that their data is captured.
+//
+// Like [WithDeadlineWrapper], the wrapper implements every optional
+// interface, and operations that fsys does not implement natively run
+// through the package-level helpers.
+func Record(fsys FS) (FS, *OpLog) {
+	log := new(OpLog)
+	return &recordFS{fsys: fsys, log: log}, log
+}
+
+type recordFS struct {
+	fsys FS
+	log  *OpLog
+}
+
+// record logs op with the modes carried by ctx if err is nil, and returns
+// err.
+func (r *recordFS) record(ctx context.Context, op Op, err error) error {
+	if err == nil {
+		if op.Op != "chmod" && op.Op != "mknod" {
+			op.Mode, op.DirMode = FileMode(ctx), DirMode(ctx)
+		}
+		r.log.add(op)
+	}
+	return err
+}
+
+// recordWriter copies the data written to w and logs it on Close.
+type recordWriter struct {
+	w      io.WriteCloser
+	buf    bytes.Buffer
+	commit func(data []byte) error
+}
+
+func (rw *recordWriter) Write(p []byte) (int, error) {
+	n, err := rw.w.Write(p)
+	rw.buf.Write(p[:n])
+	return n, err
+}
+
+func (rw *recordWriter) Close() error {
+	if err := rw.w.Close(); err != nil {
+		return err
+	}
+	return rw.commit(rw.buf.Bytes())
+}
+
+func (r *recordFS) writer(
+	ctx context.Context, op, name string, w io.WriteCloser, err error,
+) (io.WriteCloser, error) {
+	if err != nil {
+		return nil, err
+	}
+	return &recordWriter{w: w, commit: func(data []byte) error {
+		return r.record(ctx, Op{
+			Op:   op,
+			Path: name,
+			Data: data,
+			Size: int64(len(data)),
+		}, nil)
+	}}, nil
+}
+
+func (r *recordFS) Open(
+	ctx context.Context, name string,
+) (io.ReadCloser, error) {
+	return Open(ctx, r.fsys, name)
+}
+
+func (r *recordFS) OpenDir(
+	ctx context.Context, dir string,
+) (io.ReadCloser, error) {
+	dfs, ok := r.fsys.(DirFS)
+	if !ok {
+		return nil, &PathError{Op: "opendir", Path: dir, Err: ErrUnsupported}
+	}
+	return dfs.OpenDir(ctx, dir)
+}
+
+func (r *recordFS) OpenRange(
+	ctx context.Context, name string, offset, length int64,
+) (io.ReadCloser, error) {
+	return OpenRange(ctx, r.fsys, name, offset, length)
+}
+
+func (r *recordFS) CanSeek(ctx context.Context, name string) bool {
+	return CanSeek(ctx, r.fsys, name)
+}
+
+func (r *recordFS) Create(
+	ctx context.Context, name string,
+) (io.WriteCloser, error) {
+	w, err := Create(ctx, r.fsys, name)
+	return r.writer(ctx, "create", name, w, err)
+}
+
+func (r *recordFS) Append(
+	ctx context.Context, name string,
+) (io.WriteCloser, error) {
+	w, err := Append(ctx, r.fsys, name)
+	return r.writer(ctx, "append", name, w, err)
+}
+
+func (r *recordFS) AppendDir(
+	ctx context.Context, dir string,
+) (io.WriteCloser, error) {
+	afs, ok := r.fsys.(AppendDirFS)
+	if !ok {
+		return nil, &PathError{
+			Op:   "appenddir",
+			Path: dir,
+			Err:  ErrUnsupported,
+		}
+	}
+	w, err := afs.AppendDir(ctx, dir)
+	return r.writer(ctx, "appenddir", dir, w, err)
+}
+
+func (r *recordFS) Stat(ctx context.Context, name string) (FileInfo, error) {
+	return Stat(ctx, r.fsys, name)
+}
+
+func (r *recordFS) StatMany(
+	ctx context.Context, names []string,
+) ([]FileInfo, error) {
+	sfs, ok := r.fsys.(StatManyFS)
+	if !ok {
+		return nil, &PathError{Op: "stat", Err: ErrUnsupported}
+	}
+	return sfs.StatMany(ctx, names)
+}
+
+func (r *recordFS) Lstat(ctx context.Context, name string) (FileInfo, error) {
+	return Lstat(ctx, r.fsys, name)
+}
+
+func (r *recordFS) ReadLink(
+	ctx context.Context, name string,
+) (string, error) {
+	return ReadLink(ctx, r.fsys, name)
+}
+
+func (r *recordFS) Link(
+	ctx context.Context, oldname, newname string,
+) error {
+	err := Link(ctx, r.fsys, oldname, newname)
+	return r.record(ctx, Op{Op: "link", Path: oldname, NewPath: newname}, err)
+}
+
+func (r *recordFS) SameFile(
+	ctx context.Context, a, b string,
+) (bool, error) {
+	return SameFile(ctx, r.fsys, a, b)
+}
+
+func (r *recordFS) Symlink(
+	ctx context.Context, oldname, newname string,
+) error {
+	err := Symlink(ctx, r.fsys, oldname, newname)
+	return r.record(ctx, Op{
+		Op:      "symlink",
+		Path:    oldname,
+		NewPath: newname,
+	}, err)
+}
+
+func (r *recordFS) ReadDir(
+	ctx context.Context, name string,
+) iter.Seq2[DirEntry, error] {
+	return ReadDir(ctx, r.fsys, name)
+}
+
+func (r *recordFS) Walk(
+	ctx context.Context, root string, depth int,
+) iter.Seq2[DirEntry, error] {
+	return Walk(ctx, r.fsys, root, depth)
+}
+
+func (r *recordFS) Glob(
+	ctx context.Context, pattern string,
+) ([]string, error) {
+	return Glob(ctx, r.fsys, pattern)
+}
+
+func (r *recordFS) Count(ctx context.Context, dir string) (int, error) {
+	return Count(ctx, r.fsys, dir)
+}
+
+func (r *recordFS) Exists(ctx context.Context, name string) (bool, error) {
+	return Exists(ctx, r.fsys, name)
+}
+
+func (r *recordFS) IsMountPoint(
+	ctx context.Context, name string,
+) (bool, error) {
+	return IsMountPoint(ctx, r.fsys, name)
+}
+
+func (r *recordFS) Mkdir(ctx context.Context, name string) error {
+	err := Mkdir(ctx, r.fsys, name)
+	return r.record(ctx, Op{Op: "mkdir", Path: name}, err)
+}
+
+func (r *recordFS) MkdirAll(ctx context.Context, name string) error {
+	err := MkdirAll(ctx, r.fsys, name)
+	return r.record(ctx, Op{Op: "mkdirall", Path: name}, err)
+}
+
+func (r *recordFS) Remove(ctx context.Context, name string) error {
+	err := Remove(ctx, r.fsys, name)
+	return r.record(ctx, Op{Op: "remove", Path: name}, err)
+}
+
+func (r *recordFS) RemoveAll(ctx context.Context, name string) error {
+	err := RemoveAll(ctx, r.fsys, name)
+	return r.record(ctx, Op{Op: "removeall", Path: name}, err)
+}
+
+func (r *recordFS) Rename(
+	ctx context.Context, oldname, newname string,
+) error {
+	err := Rename(ctx, r.fsys, oldname, newname)
+	return r.record(ctx, Op{
+		Op:      "rename",
+		Path:    oldname,
+		NewPath: newname,
+	}, err)
+}
+
+func (r *recordFS) Copy(ctx context.Context, src, dst string) error {
+	err := Copy(ctx, r.fsys, src, dst)
+	return r.record(ctx, Op{Op: "copy", Path: src, NewPath: dst}, err)
+}
+
+// Trash forwards to a native trash only. Otherwise it reports
+// ErrUnsupported, so that the fallback in [Trash] runs through the
+// wrapper's Rename and MkdirAll and is logged step by step.
+func (r *recordFS) Trash(ctx context.Context, name string) error {
+	tfs, ok := r.fsys.(TrashFS)
+	if !ok {
+		return &PathError{Op: "trash", Path: name, Err: ErrUnsupported}
+	}
+	err := tfs.Trash(ctx, name)
+	if errors.Is(err, ErrUnsupported) {
+		return err
+	}
+	return r.record(ctx, Op{Op: "trash", Path: name}, err)
+}
+
+func (r *recordFS) Chmod(ctx context.Context, name string, mode Mode) error {
+	err := Chmod(ctx, r.fsys, name, mode)
+	return r.record(ctx, Op{Op: "chmod", Path: name, Mode: mode}, err)
+}
+
+func (r *recordFS) Chown(
+	ctx context.Context, name string, uid, gid int,
+) error {
+	err := Chown(ctx, r.fsys, name, uid, gid)
+	return r.record(ctx, Op{Op: "chown", Path: name, UID: uid, GID: gid}, err)
+}
+
+func (r *recordFS) Chtimes(
+	ctx context.Context, name string, atime, mtime time.Time,
+) error {
+	err := Chtimes(ctx, r.fsys, name, atime, mtime)
+	return r.record(ctx, Op{
+		Op:    "chtimes",
+		Path:  name,
+		Atime: atime,
+		Mtime: mtime,
+	}, err)
+}
+
+func (r *recordFS) Mknod(
+	ctx context.Context, name string, mode Mode, dev uint64,
+) error {
+	err := Mknod(ctx, r.fsys, name, mode, dev)
+	return r.record(ctx, Op{
+		Op:   "mknod",
+		Path: name,
+		Mode: mode,
+		Dev:  dev,
+	}, err)
+}
+
+func (r *recordFS) Truncate(
+	ctx context.Context, name string, size int64,
+) error {
+	err := Truncate(ctx, r.fsys, name, size)
+	return r.record(ctx, Op{Op: "truncate", Path: name, Size: size}, err)
+}
+
+func (r *recordFS) TruncateDir(ctx context.Context, dir string) error {
+	tfs, ok := r.fsys.(TruncateDirFS)
+	if !ok {
+		return &PathError{Op: "truncate", Path: dir, Err: ErrUnsupported}
+	}
+	err := tfs.TruncateDir(ctx, dir)
+	return r.record(ctx, Op{Op: "truncatedir", Path: dir}, err)
+}
+
+func (r *recordFS) Temp(ctx context.Context, name string) (string, error) {
+	tfs, ok := r.fsys.(TempFS)
+	if !ok {
+		return "", &PathError{Op: "temp", Path: name, Err: ErrUnsupported}
+	}
+	return tfs.Temp(ctx, name)
+}
+
+func (r *recordFS) TempDir(
+	ctx context.Context, name string,
+) (string, error) {
+	tfs, ok := r.fsys.(TempDirFS)
+	if !ok {
+		return "", &PathError{Op: "tempdir", Path: name, Err: ErrUnsupported}
+	}
+	return tfs.TempDir(ctx, name)
+}
+
+func (r *recordFS) Localize(
+	ctx context.Context, path string,
+) (string, error) {
+	return Localize(ctx, r.fsys, path)
+}
+
+func (r *recordFS) Abs(ctx context.Context, name string) (string, error) {
+	return Abs(ctx, r.fsys, name)
+}
+
+func (r *recordFS) SysConn(
+	ctx context.Context, name string,
+) (RawConn, error) {
+	return SysConn(ctx, r.fsys, name)
+}
+
+func (r *recordFS) Close() error { return Close(r.fsys) }
+
+var (
+	_ AbsFS         = (*recordFS)(nil)
+	_ AppendFS      = (*recordFS)(nil)
+	_ AppendDirFS   = (*recordFS)(nil)
+	_ ChmodFS       = (*recordFS)(nil)
+	_ ChownFS       = (*recordFS)(nil)
+	_ ChtimesFS     = (*recordFS)(nil)
+	_ CountFS       = (*recordFS)(nil)
+	_ CreateFS      = (*recordFS)(nil)
+	_ DirFS         = (*recordFS)(nil)
+	_ CopyFS        = (*recordFS)(nil)
+	_ ExistsFS      = (*recordFS)(nil)
+	_ GlobFS        = (*recordFS)(nil)
+	_ LinkFS        = (*recordFS)(nil)
+	_ LocalizeFS    = (*recordFS)(nil)
+	_ MkdirFS       = (*recordFS)(nil)
+	_ MkdirAllFS    = (*recordFS)(nil)
+	_ MknodFS       = (*recordFS)(nil)
+	_ MountFS       = (*recordFS)(nil)
+	_ OpenRangeFS   = (*recordFS)(nil)
+	_ ReadDirFS     = (*recordFS)(nil)
+	_ ReadLinkFS    = (*recordFS)(nil)
+	_ RemoveFS      = (*recordFS)(nil)
+	_ RemoveAllFS   = (*recordFS)(nil)
+	_ RenameFS      = (*recordFS)(nil)
+	_ SameFileFS    = (*recordFS)(nil)
+	_ SeekFS        = (*recordFS)(nil)
+	_ StatFS        = (*recordFS)(nil)
+	_ StatManyFS    = (*recordFS)(nil)
+	_ SymlinkFS     = (*recordFS)(nil)
+	_ SysFS         = (*recordFS)(nil)
+	_ TempFS        = (*recordFS)(nil)
+	_ TempDirFS     = (*recordFS)(nil)
+	_ TrashFS       = (*recordFS)(nil)
+	_ TruncateFS    = (*recordFS)(nil)
+	_ TruncateDirFS = (*recordFS)(nil)
+	_ WalkFS        = (*recordFS)(nil)
+	_ io.Closer     = (*recordFS)(nil)
+)
@@ -0,0 +1,164 @@
+package fs_test
+
+import (
+	"context"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+
+	"lesiw.io/fs"
+	"lesiw.io/fs/memfs"
+	"lesiw.io/fs/path"
+)
+
+func TestRecordReplay(t *testing.T) {
+	ctx, src := context.Background(), memfs.New()
+	fsys, log := fs.Record(src)
+
+	steps := []func() error{
+		func() error {
+			return fs.WriteFile(ctx, fsys, "a/b/file.txt", []byte("hello"))
+		},
+		func() error {
+			ctx := fs.WithDirMode(ctx, 0700)
+			return fs.Mkdir(ctx, fsys, "private")
+		},
+		func() error {
+			ctx := fs.WithFileMode(ctx, 0600)
+			return fs.WriteFile(ctx, fsys, "private/key", []byte("secret"))
+		},
+		func() error {
+			w, err := fs.Append(ctx, fsys, "a/b/file.txt")
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(w, strings.NewReader(", world")); err != nil {
+				return err
+			}
+			return w.Close()
+		},
+		func() error {
+			return fs.WriteFile(ctx, fsys, "tmp.txt", []byte("temporary"))
+		},
+		func() error { return fs.Rename(ctx, fsys, "tmp.txt", "kept.txt") },
+		func() error { return fs.Truncate(ctx, fsys, "kept.txt", 4) },
+		func() error { return fs.Symlink(ctx, fsys, "kept.txt", "link") },
+		func() error { return fs.WriteFile(ctx, fsys, "gone.txt", nil) },
+		func() error { return fs.Remove(ctx, fsys, "gone.txt") },
+	}
+	for i, step := range steps {
+		if err := step(); err != nil {
+			t.Fatalf("step %d error = %v", i, err)
+		}
+	}
+
+	var ops []string
+	for _, op := range log.Ops() {
+		ops = append(ops, op.Op)
+	}
+	want := []string{
+		"create", "mkdir", "create", "append", "create",
+		"rename", "truncate", "symlink", "create", "remove",
+	}
+	if !slices.Equal(ops, want) {
+		t.Errorf("log.Ops() = %v, want %v", ops, want)
+	}
+
+	dst := memfs.New()
+	if err := log.Replay(ctx, dst); err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+	got, wantTree := snapshot(t, dst), snapshot(t, src)
+	if !maps.Equal(got, wantTree) {
+		t.Errorf("replayed tree = %v, want %v", got, wantTree)
+	}
+}
+
+func TestRecordFailedOp(t *testing.T) {
+	ctx := context.Background()
+	fsys, log := fs.Record(memfs.New())
+
+	if err := fs.Remove(ctx, fsys, "missing.txt"); err == nil {
+		t.Fatal("Remove(missing) error = nil, want error")
+	}
+	if ops := log.Ops(); len(ops) != 0 {
+		t.Errorf("log.Ops() = %v, want none", ops)
+	}
+}
+
+// tarDirFS extracts tar archives natively through AppendDir.
+type tarDirFS struct{ fs.FS }
+
+func (f tarDirFS) AppendDir(
+	ctx context.Context, dir string,
+) (io.WriteCloser, error) {
+	return fs.Append(ctx, f.FS, path.Join(dir, ""))
+}
+
+func TestRecordReplayAppendDir(t *testing.T) {
+	ctx, src := context.Background(), memfs.New()
+	fsys, log := fs.Record(tarDirFS{src})
+
+	files := map[string]string{"a/b.txt": "b", "c.txt": "c"}
+	if err := appendTar(ctx, fsys, "out/", files); err != nil {
+		t.Fatalf("Append(out/) error = %v", err)
+	}
+	ops := log.Ops()
+	if len(ops) != 1 || ops[0].Op != "appenddir" {
+		t.Fatalf("log.Ops() = %v, want one appenddir", ops)
+	}
+
+	dst := memfs.New()
+	if err := log.Replay(ctx, dst); err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+	got, want := snapshot(t, dst), snapshot(t, src)
+	if !maps.Equal(got, want) {
+		t.Errorf("replayed tree = %v, want %v", got, want)
+	}
+}
+
+func TestRecordNoMkdir(t *testing.T) {
+	ctx := context.Background()
+	fsys, log := fs.Record(noMkdirFS{memfs.New()})
+
+	err := appendTar(ctx, fsys, "out/", map[string]string{"a/b.txt": "b"})
+	if err != nil {
+		t.Fatalf("Append(out/) error = %v", err)
+	}
+	var ops []string
+	for _, op := range log.Ops() {
+		ops = append(ops, op.Op+" "+op.Path)
+	}
+	if want := []string{"create ./out/a/b.txt"}; !slices.Equal(ops, want) {
+		t.Errorf("log.Ops() = %v, want %v", ops, want)
+	}
+}
+
+// snapshot describes every entry in fsys by its mode and contents.
+func snapshot(t *testing.T, fsys fs.FS) map[string]string {
+	t.Helper()
+	ctx := context.Background()
+	tree := make(map[string]string)
+	for e, err := range fs.Walk(ctx, fsys, ".", 0) {
+		if err != nil {
+			t.Fatalf("Walk() error = %v", err)
+		}
+		info, err := e.Info()
+		if err != nil {
+			t.Fatalf("Info(%q) error = %v", e.Path(), err)
+		}
+		desc := info.Mode().String()
+		if !e.IsDir() {
+			data, err := fs.ReadFile(ctx, fsys, e.Path())
+			if err != nil {
+				t.Fatalf("ReadFile(%q) error = %v", e.Path(), err)
+			}
+			desc += " " + string(data)
+		}
+		tree[e.Path()] = desc
+	}
+	return tree
+}
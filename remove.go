@@ -3,6 +3,9 @@ package fs
 import (
 	"context"
 	"errors"
+	"strings"
+
+	"lesiw.io/fs/path"
 )
 
 // A RemoveFS is a file system with the Remove method.
@@ -33,3 +36,74 @@ func Remove(ctx context.Context, fsys FS, name string) error {
 	}
 	return &PathError{Op: "remove", Path: name, Err: ErrUnsupported}
 }
+
+// RemoveEmptyParents removes the named file or empty directory, then removes
+// each parent directory left empty by the removal, like rmdir -p.
+// Analogous to: rm followed by rmdir -p.
+//
+// Parents are removed from the innermost outward. The walk stops at the
+// first directory that is not empty, and never removes stopAt or any of its
+// ancestors. If stopAt is empty or is not an ancestor of name, the walk
+// stops at the first non-empty directory or at the root.
+//
+// Hidden files keep a directory from being removed, even under
+// [WithSkipHidden]. A parent that no longer exists, such as a virtual
+// directory on an object store, counts as already removed.
+//
+// Requires: [RemoveFS] && [ReadDirFS]
+func RemoveEmptyParents(
+	ctx context.Context, fsys FS, name, stopAt string,
+) error {
+	if err := Remove(ctx, fsys, name); err != nil {
+		return err
+	}
+	stop := parentKey(stopAt)
+	for dir := parentKey(path.Dir(name)); ; {
+		if dir == "." || dir == "/" || dir == stop {
+			return nil
+		}
+		if strings.HasPrefix(stop, dir+"/") {
+			return nil
+		}
+		empty, err := emptyDir(ctx, fsys, dir)
+		if err != nil {
+			return err
+		} else if !empty {
+			return nil
+		}
+		err = Remove(ctx, fsys, dir)
+		if err != nil && !errors.Is(err, ErrNotExist) {
+			return err
+		}
+		dir = parentKey(path.Dir(dir))
+	}
+}
+
+// emptyDir reports whether dir has no entries, counting hidden files even
+// under [WithSkipHidden]. A directory that no longer exists, as a virtual
+// directory on an object store vanishes with its last object, is empty.
+func emptyDir(ctx context.Context, fsys FS, dir string) (bool, error) {
+	ctx = context.WithValue(ctx, skipHiddenKey, false)
+	for _, err := range ReadDir(ctx, fsys, dir) {
+		if errors.Is(err, ErrNotExist) {
+			return true, nil
+		} else if err != nil {
+			return false, err
+		}
+		return false, nil
+	}
+	return true, nil
+}
+
+// parentKey normalizes a directory name for comparison in
+// RemoveEmptyParents.
+func parentKey(name string) string {
+	name = strings.TrimPrefix(path.Clean(name), "./")
+	if len(name) > 1 {
+		name = strings.TrimSuffix(name, "/")
+	}
+	if name == "" {
+		return "."
+	}
+	return name
+}
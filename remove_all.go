@@ -3,13 +3,14 @@ package fs
 import (
 	"context"
 	"errors"
+	"slices"
 
 	"lesiw.io/fs/path"
 )
 
 // A RemoveAllFS is a file system with the RemoveAll method.
 //
-// If not implemented, RemoveAll falls back to recursive removal using
+// If not implemented, RemoveAll falls back to removing each entry using
 // RemoveFS, StatFS, and ReadDirFS.
 type RemoveAllFS interface {
 	FS
@@ -21,6 +22,9 @@ type RemoveAllFS interface {
 // RemoveAll removes name and any children it contains.
 // Analogous to: [os.RemoveAll], rm -rf.
 //
+// Without a native RemoveAll, a failure to remove one entry does not stop
+// the removal of the others. All such errors are joined and returned.
+//
 // Requires: [RemoveAllFS] ||
 // ([RemoveFS] && [StatFS] && ([ReadDirFS] || [WalkFS]))
 func RemoveAll(ctx context.Context, fsys FS, name string) error {
@@ -69,18 +73,30 @@ func RemoveAll(ctx context.Context, fsys FS, name string) error {
 		return err
 	}
 
-	// It's a directory - read contents to remove children
-	// Remove all children
-	for entry, readErr := range ReadDir(ctx, fsys, name) {
-		if readErr != nil {
-			return readErr
+	// It's a directory - collect the tree with an explicit stack, each
+	// directory ahead of its children, then remove it leaves-first.
+	var errs []error
+	paths, stack := []string{name}, []string{name}
+	for len(stack) > 0 {
+		dir := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for entry, readErr := range ReadDir(ctx, fsys, dir) {
+			if readErr != nil {
+				errs = append(errs, readErr)
+				break
+			}
+			childPath := path.Join(dir, entry.Name())
+			paths = append(paths, childPath)
+			if entry.IsDir() {
+				stack = append(stack, childPath)
+			}
 		}
-		childPath := path.Join(name, entry.Name())
-		if removeErr := RemoveAll(ctx, fsys, childPath); removeErr != nil {
-			return removeErr
+	}
+	for _, p := range slices.Backward(paths) {
+		err := rfs.Remove(ctx, p)
+		if err != nil && !errors.Is(err, ErrNotExist) {
+			errs = append(errs, err)
 		}
 	}
-
-	// Now remove the empty directory
-	return rfs.Remove(ctx, name)
+	return errors.Join(errs...)
 }
@@ -4,9 +4,16 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"iter"
 	"log"
+	stdpath "path"
+	"runtime/debug"
+	"strings"
+	"testing"
 
 	"lesiw.io/fs"
+	"lesiw.io/fs/memfs"
 	"lesiw.io/fs/osfs"
 )
 
@@ -53,3 +60,184 @@ func ExampleRemoveAll() {
 	// Output:
 	// Directory tree successfully removed
 }
+
+func TestRemoveEmptyParents(t *testing.T) {
+	ctx, fsys := context.Background(), memfs.New()
+
+	if err := fs.WriteFile(ctx, fsys, "a/b/c/file", nil); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	if err := fs.RemoveEmptyParents(ctx, fsys, "a/b/c/file", "a"); err != nil {
+		t.Fatalf("RemoveEmptyParents() error = %v", err)
+	}
+
+	for _, name := range []string{"a/b/c/file", "a/b/c", "a/b"} {
+		if _, err := fs.Stat(ctx, fsys, name); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("Stat(%q) error = %v, want ErrNotExist", name, err)
+		}
+	}
+	if _, err := fs.Stat(ctx, fsys, "a"); err != nil {
+		t.Errorf("Stat(%q) error = %v", "a", err)
+	}
+}
+
+func TestRemoveEmptyParentsStopsAtNonEmpty(t *testing.T) {
+	ctx, fsys := context.Background(), memfs.New()
+
+	for _, name := range []string{"a/b/c/file", "a/b/keep"} {
+		if err := fs.WriteFile(ctx, fsys, name, nil); err != nil {
+			t.Fatalf("WriteFile(%q) error = %v", name, err)
+		}
+	}
+
+	if err := fs.RemoveEmptyParents(ctx, fsys, "a/b/c/file", ""); err != nil {
+		t.Fatalf("RemoveEmptyParents() error = %v", err)
+	}
+
+	if _, err := fs.Stat(ctx, fsys, "a/b/c"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Stat(%q) error = %v, want ErrNotExist", "a/b/c", err)
+	}
+	if _, err := fs.Stat(ctx, fsys, "a/b/keep"); err != nil {
+		t.Errorf("Stat(%q) error = %v", "a/b/keep", err)
+	}
+}
+
+func TestRemoveEmptyParentsKeepsHidden(t *testing.T) {
+	ctx, fsys := context.Background(), memfs.New()
+	for _, name := range []string{"a/b/file", "a/b/.keep"} {
+		if err := fs.WriteFile(ctx, fsys, name, nil); err != nil {
+			t.Fatalf("WriteFile(%q) error = %v", name, err)
+		}
+	}
+
+	ctx = fs.WithSkipHidden(ctx)
+	if err := fs.RemoveEmptyParents(ctx, fsys, "a/b/file", ""); err != nil {
+		t.Fatalf("RemoveEmptyParents() error = %v", err)
+	}
+	if _, err := fs.Stat(ctx, fsys, "a/b/.keep"); err != nil {
+		t.Errorf("Stat(%q) error = %v", "a/b/.keep", err)
+	}
+}
+
+// objectFS removes each directory left empty by a Remove, as virtual
+// directories on an object store vanish with their last object.
+type objectFS struct{ fs.FS }
+
+func (f objectFS) Remove(ctx context.Context, name string) error {
+	if err := fs.Remove(ctx, f.FS, name); err != nil {
+		return err
+	}
+	for dir := stdpath.Dir(name); dir != "."; dir = stdpath.Dir(dir) {
+		if fs.Remove(ctx, f.FS, dir) != nil {
+			break
+		}
+	}
+	return nil
+}
+
+func (f objectFS) ReadDir(
+	ctx context.Context, name string,
+) iter.Seq2[fs.DirEntry, error] {
+	return fs.ReadDir(ctx, f.FS, name)
+}
+
+func TestRemoveEmptyParentsVanished(t *testing.T) {
+	ctx, mem := context.Background(), memfs.New()
+	if err := fs.WriteFile(ctx, mem, "a/b/c/file", nil); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	err := fs.RemoveEmptyParents(ctx, objectFS{mem}, "a/b/c/file", "")
+	if err != nil {
+		t.Fatalf("RemoveEmptyParents() error = %v", err)
+	}
+	if _, err := fs.Stat(ctx, mem, "a"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Stat(%q) error = %v, want ErrNotExist", "a", err)
+	}
+}
+
+// removeFS exposes only the operations RemoveAll's fallback needs, and
+// refuses to remove the names in deny.
+type removeFS struct {
+	fsys fs.FS
+	deny map[string]bool
+}
+
+func (f removeFS) Open(ctx context.Context, name string) (
+	io.ReadCloser, error,
+) {
+	return fs.Open(ctx, f.fsys, name)
+}
+
+func (f removeFS) Remove(ctx context.Context, name string) error {
+	if f.deny[stdpath.Clean(name)] {
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrPermission}
+	}
+	return fs.Remove(ctx, f.fsys, name)
+}
+
+func (f removeFS) Stat(ctx context.Context, name string) (
+	fs.FileInfo, error,
+) {
+	return fs.Stat(ctx, f.fsys, name)
+}
+
+func (f removeFS) ReadDir(
+	ctx context.Context, name string,
+) iter.Seq2[fs.DirEntry, error] {
+	return fs.ReadDir(ctx, f.fsys, name)
+}
+
+func TestRemoveAllDeep(t *testing.T) {
+	ctx, mem := context.Background(), memfs.New()
+	fsys := removeFS{fsys: mem}
+
+	name := strings.Repeat("d/", 500) + "leaf.txt"
+	if err := fs.WriteFile(ctx, mem, name, []byte("leaf")); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	// A recursive fallback would need far more than this for 500 levels.
+	defer debug.SetMaxStack(debug.SetMaxStack(128 << 10))
+
+	if err := fs.RemoveAll(ctx, fsys, "d"); err != nil {
+		t.Fatalf("RemoveAll() error = %v", err)
+	}
+	if _, err := fs.Stat(ctx, mem, "d"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Stat() after RemoveAll() error = %v, want ErrNotExist", err)
+	}
+}
+
+func TestRemoveAllContinuesPastError(t *testing.T) {
+	ctx, mem := context.Background(), memfs.New()
+	fsys := removeFS{fsys: mem, deny: map[string]bool{"root/b/2.txt": true}}
+
+	var files []string
+	for _, dir := range []string{"a", "b", "c"} {
+		for _, base := range []string{"1.txt", "2.txt", "3.txt"} {
+			name := "root/" + dir + "/" + base
+			if err := fs.WriteFile(ctx, mem, name, nil); err != nil {
+				t.Fatalf("WriteFile(%q) error = %v", name, err)
+			}
+			files = append(files, name)
+		}
+	}
+
+	err := fs.RemoveAll(ctx, fsys, "root")
+	if !errors.Is(err, fs.ErrPermission) {
+		t.Fatalf("RemoveAll() error = %v, want ErrPermission", err)
+	}
+	for _, name := range files {
+		_, err := fs.Stat(ctx, mem, name)
+		if name == "root/b/2.txt" {
+			if err != nil {
+				t.Errorf("Stat(%q) error = %v, want file kept", name, err)
+			}
+			continue
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("Stat(%q) error = %v, want ErrNotExist", name, err)
+		}
+	}
+}
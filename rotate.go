@@ -0,0 +1,131 @@
+package fs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strconv"
+)
+
+// RotatingAppend opens a file for appending and rotates it when it grows
+// past maxBytes. Analogous to: logrotate, log/syslog file rotation.
+//
+// The writer tracks the size of the current file, starting from its size
+// reported by [Stat]. Before a write that would take the file past
+// maxBytes, the current file is closed and renamed to name.1, existing
+// backups are shifted up by one (name.1 to name.2, and so on), and a fresh
+// file is opened in its place. Each write lands in a single file, so a
+// write larger than maxBytes is written whole to a fresh file.
+//
+// Backups are never deleted.
+//
+// The returned writer must be closed when done.
+//
+// Requires: [StatFS], plus see [Append] and [Rename] requirements
+func RotatingAppend(
+	ctx context.Context, fsys FS, name string, maxBytes int64,
+) (io.WriteCloser, error) {
+	if maxBytes <= 0 {
+		return nil, &PathError{
+			Op:   "append",
+			Path: name,
+			Err:  errors.New("maxBytes must be positive"),
+		}
+	}
+	var size int64
+	info, err := Stat(ctx, fsys, name)
+	switch {
+	case err == nil:
+		size = info.Size()
+	case !errors.Is(err, ErrNotExist):
+		return nil, err
+	}
+	w, err := Append(ctx, fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	return &rotatingWriter{
+		ctx:  ctx,
+		fsys: fsys,
+		name: name,
+		max:  maxBytes,
+		size: size,
+		w:    w,
+	}, nil
+}
+
+type rotatingWriter struct {
+	ctx  context.Context
+	fsys FS
+	name string
+	max  int64
+	size int64
+	w    io.WriteCloser
+}
+
+func (rw *rotatingWriter) Write(p []byte) (int, error) {
+	if rw.w == nil {
+		return 0, &PathError{Op: "write", Path: rw.name, Err: ErrClosed}
+	}
+	if rw.size > 0 && rw.size+int64(len(p)) > rw.max {
+		if err := rw.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := rw.w.Write(p)
+	rw.size += int64(n)
+	return n, err
+}
+
+func (rw *rotatingWriter) Close() error {
+	if rw.w == nil {
+		return nil
+	}
+	err := rw.w.Close()
+	rw.w = nil
+	return err
+}
+
+// rotate closes the current file, shifts it into the backups, and opens a
+// fresh file.
+func (rw *rotatingWriter) rotate() error {
+	err := rw.w.Close()
+	rw.w = nil
+	if err != nil {
+		return err
+	}
+
+	// Find the highest existing backup, then shift downward so no backup
+	// is overwritten.
+	n := 0
+	for {
+		_, err := Stat(rw.ctx, rw.fsys, rw.backup(n+1))
+		if errors.Is(err, ErrNotExist) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		n++
+	}
+	for i := n; i > 0; i-- {
+		err := Rename(rw.ctx, rw.fsys, rw.backup(i), rw.backup(i+1))
+		if err != nil {
+			return err
+		}
+	}
+	if err := Rename(rw.ctx, rw.fsys, rw.name, rw.backup(1)); err != nil {
+		return err
+	}
+
+	w, err := Append(rw.ctx, rw.fsys, rw.name)
+	if err != nil {
+		return err
+	}
+	rw.w, rw.size = w, 0
+	return nil
+}
+
+func (rw *rotatingWriter) backup(i int) string {
+	return rw.name + "." + strconv.Itoa(i)
+}
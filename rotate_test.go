@@ -0,0 +1,52 @@
+package fs_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"lesiw.io/fs"
+	"lesiw.io/fs/memfs"
+)
+
+func TestRotatingAppend(t *testing.T) {
+	ctx, fsys := context.Background(), memfs.New()
+
+	if err := fs.WriteFile(ctx, fsys, "app.log", []byte("old\n")); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	w, err := fs.RotatingAppend(ctx, fsys, "app.log", 10)
+	if err != nil {
+		t.Fatalf("RotatingAppend() error = %v", err)
+	}
+	for _, line := range []string{"line1\n", "line2\n", "line3\n"} {
+		if _, err := io.WriteString(w, line); err != nil {
+			t.Fatalf("Write(%q) error = %v", line, err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	want := map[string]string{
+		"app.log":   "line3\n",
+		"app.log.1": "line2\n",
+		"app.log.2": "old\nline1\n",
+	}
+	for name, want := range want {
+		got, err := fs.ReadFile(ctx, fsys, name)
+		if err != nil {
+			t.Fatalf("ReadFile(%q) error = %v", name, err)
+		}
+		if string(got) != want {
+			t.Errorf("ReadFile(%q) = %q, want %q", name, got, want)
+		}
+	}
+
+	_, err = fs.Stat(ctx, fsys, "app.log.3")
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Stat(app.log.3) error = %v, want ErrNotExist", err)
+	}
+}
@@ -0,0 +1,76 @@
+package fs
+
+import (
+	"context"
+	"errors"
+	"reflect"
+)
+
+// A SameFileFS is a file system with the SameFile method.
+//
+// Backends that can tell authoritatively whether two paths name the same
+// file, such as local filesystems with inodes, implement SameFileFS.
+// Object stores, which have no notion of file identity beyond the key,
+// should return [ErrUnsupported].
+type SameFileFS interface {
+	FS
+
+	// SameFile reports whether a and b refer to the same underlying file.
+	SameFile(ctx context.Context, a, b string) (bool, error)
+}
+
+// SameFile reports whether a and b refer to the same underlying file, as
+// when one is a hard link to the other.
+// Analogous to: [os.SameFile], test -ef.
+//
+// Without [SameFileFS], or if its SameFile returns [ErrUnsupported], both
+// paths are passed to [Stat]. If the two FileInfos return the same non-nil
+// pointer from Sys, the files are the same; otherwise their device and
+// inode numbers are compared where the platform provides them. If neither
+// identifies the files, SameFile returns [ErrUnsupported].
+//
+// Requires: [SameFileFS] || [StatFS]
+func SameFile(ctx context.Context, fsys FS, a, b string) (bool, error) {
+	var err error
+	if a, err = localizePath(ctx, fsys, a); err != nil {
+		return false, err
+	}
+	if b, err = localizePath(ctx, fsys, b); err != nil {
+		return false, err
+	}
+	if sfs, ok := fsys.(SameFileFS); ok {
+		same, err := sfs.SameFile(ctx, a, b)
+		if !errors.Is(err, ErrUnsupported) {
+			return same, newPathError("samefile", a, err)
+		}
+	}
+	ainfo, err := Stat(ctx, fsys, a)
+	if err != nil {
+		return false, err
+	}
+	binfo, err := Stat(ctx, fsys, b)
+	if err != nil {
+		return false, err
+	}
+	if same, ok := sameSys(ainfo.Sys(), binfo.Sys()); ok {
+		return same, nil
+	}
+	return false, &PathError{Op: "samefile", Path: a, Err: ErrUnsupported}
+}
+
+// sameSys compares the identities held in the Sys values of two FileInfos.
+// It reports false for ok if they cannot be compared.
+func sameSys(a, b any) (same, ok bool) {
+	if a == nil || b == nil {
+		return false, false
+	}
+	adev, aino, aok := sysFileID(a)
+	bdev, bino, bok := sysFileID(b)
+	if aok && bok {
+		return adev == bdev && aino == bino, true
+	}
+	if reflect.ValueOf(a).Kind() == reflect.Pointer && a == b {
+		return true, true
+	}
+	return false, false
+}
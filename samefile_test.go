@@ -0,0 +1,88 @@
+package fs_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"runtime"
+	"testing"
+
+	"lesiw.io/fs"
+	"lesiw.io/fs/memfs"
+	"lesiw.io/fs/osfs"
+)
+
+// statOnlyFS hides every optional interface of its FS except StatFS.
+type statOnlyFS struct{ fsys fs.FS }
+
+func (f statOnlyFS) Open(ctx context.Context, name string) (
+	io.ReadCloser, error,
+) {
+	return fs.Open(ctx, f.fsys, name)
+}
+
+func (f statOnlyFS) Stat(ctx context.Context, name string) (
+	fs.FileInfo, error,
+) {
+	return fs.Stat(ctx, f.fsys, name)
+}
+
+func TestSameFile(t *testing.T) {
+	ctx, fsys := context.Background(), osfs.NewTemp()
+	defer fs.Close(fsys)
+	for _, name := range []string{"a.txt", "c.txt"} {
+		if err := fs.WriteFile(ctx, fsys, name, []byte(name)); err != nil {
+			t.Fatalf("WriteFile(%q) error = %v", name, err)
+		}
+	}
+	if err := fs.Link(ctx, fsys, "a.txt", "b.txt"); err != nil {
+		t.Fatalf("Link() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		fsys fs.FS
+	}{
+		{"Native", fsys},
+		{"Fallback", statOnlyFS{fsys}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			same, err := fs.SameFile(ctx, tt.fsys, "a.txt", "b.txt")
+			if errors.Is(err, fs.ErrUnsupported) &&
+				runtime.GOOS == "windows" {
+				t.Skip("file identity is not in FileInfo.Sys on windows")
+			}
+			if err != nil {
+				t.Fatalf("SameFile(link) error = %v", err)
+			}
+			if !same {
+				t.Errorf("SameFile(link) = false, want true")
+			}
+
+			same, err = fs.SameFile(ctx, tt.fsys, "a.txt", "c.txt")
+			if err != nil {
+				t.Fatalf("SameFile(other) error = %v", err)
+			}
+			if same {
+				t.Errorf("SameFile(other) = true, want false")
+			}
+
+			_, err = fs.SameFile(ctx, tt.fsys, "a.txt", "missing.txt")
+			if !errors.Is(err, fs.ErrNotExist) {
+				t.Errorf("SameFile(missing) error = %v, want ErrNotExist", err)
+			}
+		})
+	}
+}
+
+func TestSameFileUnsupported(t *testing.T) {
+	ctx, fsys := context.Background(), memfs.New()
+	if err := fs.WriteFile(ctx, fsys, "a.txt", nil); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	_, err := fs.SameFile(ctx, fsys, "a.txt", "a.txt")
+	if !errors.Is(err, fs.ErrUnsupported) {
+		t.Errorf("SameFile() error = %v, want ErrUnsupported", err)
+	}
+}
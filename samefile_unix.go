@@ -0,0 +1,12 @@
+//go:build unix
+
+package fs
+
+import "syscall"
+
+func sysFileID(sys any) (dev, ino uint64, ok bool) {
+	if st, ok := sys.(*syscall.Stat_t); ok {
+		return uint64(st.Dev), uint64(st.Ino), true
+	}
+	return 0, 0, false
+}
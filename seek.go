@@ -0,0 +1,46 @@
+package fs
+
+import (
+	"context"
+	"io"
+)
+
+// A SeekFS is a file system with the CanSeek method.
+//
+// Backends implement SeekFS when they can answer cheaply, without opening
+// the file. If not implemented, [CanSeek] opens the file and checks the
+// reader.
+type SeekFS interface {
+	FS
+
+	// CanSeek reports whether the reader returned by Open for name
+	// implements io.Seeker and can reposition within the file.
+	CanSeek(ctx context.Context, name string) bool
+}
+
+// CanSeek reports whether opening name in fsys would yield a reader that
+// implements [io.Seeker]. Callers can use it to choose an algorithm up front,
+// such as skipping ahead instead of reading and discarding.
+//
+// When CanSeek reports true, the [ReadPathCloser] returned by [Open] for
+// name can be asserted to [io.ReadSeekCloser].
+//
+// CanSeek reports false if the file cannot be opened.
+//
+// Requires: [FS]
+func CanSeek(ctx context.Context, fsys FS, name string) bool {
+	var err error
+	if name, err = localizePath(ctx, fsys, name); err != nil {
+		return false
+	}
+	if sfs, ok := fsys.(SeekFS); ok {
+		return sfs.CanSeek(ctx, name)
+	}
+	r, err := fsys.Open(ctx, name)
+	if err != nil {
+		return false
+	}
+	_, ok := r.(io.Seeker)
+	_ = r.Close()
+	return ok
+}
@@ -0,0 +1,67 @@
+package fs_test
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"lesiw.io/fs"
+	"lesiw.io/fs/memfs"
+	"lesiw.io/fs/osfs"
+)
+
+// streamFS is a filesystem whose readers cannot seek.
+type streamFS struct{ fs.FS }
+
+func (streamFS) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	return io.NopCloser(strings.NewReader("data")), nil
+}
+
+func TestCanSeek(t *testing.T) {
+	ctx := context.Background()
+	tmp := osfs.NewTemp()
+	defer fs.Close(tmp)
+
+	tests := []struct {
+		name string
+		fsys fs.FS
+		want bool
+	}{
+		{"osfs", tmp, true},
+		{"memfs", memfs.New(), true},
+		{"stream", streamFS{memfs.New()}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, ok := tt.fsys.(fs.CreateFS); ok {
+				err := fs.WriteFile(ctx, tt.fsys, "file.txt", []byte("data"))
+				if err != nil {
+					t.Fatalf("WriteFile() error = %v", err)
+				}
+			}
+
+			if got := fs.CanSeek(ctx, tt.fsys, "file.txt"); got != tt.want {
+				t.Errorf("CanSeek() = %v, want %v", got, tt.want)
+			}
+
+			r, err := fs.Open(ctx, tt.fsys, "file.txt")
+			if err != nil {
+				t.Fatalf("Open() error = %v", err)
+			}
+			closeOnCleanup(t, r)
+			if _, ok := r.(io.ReadSeekCloser); ok != tt.want {
+				t.Errorf("Open() is io.ReadSeekCloser = %v, want %v",
+					ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanSeekNotExist(t *testing.T) {
+	ctx, fsys := context.Background(), memfs.New()
+
+	if fs.CanSeek(ctx, fsys, "missing.txt") {
+		t.Error("CanSeek(missing) = true, want false")
+	}
+}
@@ -0,0 +1,104 @@
+package fs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// SplitWriter returns a writer that splits its input across files of at
+// most maxBytes each. Analogous to: split -b.
+//
+// Files are named by formatting namePattern with an index starting at 0,
+// as with [fmt.Sprintf]; for example, "part-%04d" names the files
+// part-0000, part-0001, and so on. The first file is created immediately,
+// and each subsequent file is created when the previous one is full and
+// more data is written. Writes are split at exact byte boundaries, so
+// reading the files back in order reproduces the input.
+//
+// Each file is created with [Create], so it honors [WithFileMode] and
+// creates missing parent directories.
+//
+// Close finalizes the current file. The returned writer must be closed when
+// done.
+//
+// Requires: See [Create] requirements
+func SplitWriter(
+	ctx context.Context, fsys FS, namePattern string, maxBytes int64,
+) (io.WriteCloser, error) {
+	if maxBytes <= 0 {
+		return nil, &PathError{
+			Op:   "create",
+			Path: namePattern,
+			Err:  errors.New("maxBytes must be positive"),
+		}
+	}
+	sw := &splitWriter{
+		ctx:     ctx,
+		fsys:    fsys,
+		pattern: namePattern,
+		max:     maxBytes,
+	}
+	if err := sw.next(); err != nil {
+		return nil, err
+	}
+	return sw, nil
+}
+
+type splitWriter struct {
+	ctx     context.Context
+	fsys    FS
+	pattern string
+	max     int64
+	index   int
+	size    int64
+	w       io.WriteCloser
+}
+
+func (sw *splitWriter) Write(p []byte) (int, error) {
+	var n int
+	for len(p) > 0 {
+		if sw.w == nil {
+			return n, &PathError{Op: "write", Path: sw.pattern, Err: ErrClosed}
+		}
+		if sw.size >= sw.max {
+			if err := sw.w.Close(); err != nil {
+				sw.w = nil
+				return n, err
+			}
+			if err := sw.next(); err != nil {
+				return n, err
+			}
+		}
+		chunk := p[:min(int64(len(p)), sw.max-sw.size)]
+		m, err := sw.w.Write(chunk)
+		n, sw.size, p = n+m, sw.size+int64(m), p[m:]
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+func (sw *splitWriter) Close() error {
+	if sw.w == nil {
+		return nil
+	}
+	err := sw.w.Close()
+	sw.w = nil
+	return err
+}
+
+// next creates the next file in the sequence.
+func (sw *splitWriter) next() error {
+	name := fmt.Sprintf(sw.pattern, sw.index)
+	w, err := Create(sw.ctx, sw.fsys, name)
+	if err != nil {
+		sw.w = nil
+		return err
+	}
+	sw.w, sw.size = w, 0
+	sw.index++
+	return nil
+}
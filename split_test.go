@@ -0,0 +1,57 @@
+package fs_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"lesiw.io/fs"
+	"lesiw.io/fs/memfs"
+)
+
+func TestSplitWriter(t *testing.T) {
+	ctx, fsys := context.Background(), memfs.New()
+
+	input := bytes.Repeat([]byte("0123456789"), 25)
+	w, err := fs.SplitWriter(ctx, fsys, "out/part-%04d", 100)
+	if err != nil {
+		t.Fatalf("SplitWriter() error = %v", err)
+	}
+	if _, err := w.Write(input); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	names := []string{"out/part-0000", "out/part-0001", "out/part-0002"}
+	sizes := []int{100, 100, 50}
+	for i, name := range names {
+		data, err := fs.ReadFile(ctx, fsys, name)
+		if err != nil {
+			t.Fatalf("ReadFile(%q) error = %v", name, err)
+		}
+		if got, want := len(data), sizes[i]; got != want {
+			t.Errorf("len(ReadFile(%q)) = %d, want %d", name, got, want)
+		}
+	}
+	_, err = fs.Stat(ctx, fsys, "out/part-0003")
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Stat(part-0003) error = %v, want ErrNotExist", err)
+	}
+
+	r, err := fs.OpenConcat(ctx, fsys, names...)
+	if err != nil {
+		t.Fatalf("OpenConcat() error = %v", err)
+	}
+	closeOnCleanup(t, r)
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	if !bytes.Equal(got, input) {
+		t.Errorf("concatenated shards = %q, want %q", got, input)
+	}
+}
@@ -3,6 +3,7 @@ package fs
 import (
 	"context"
 	"errors"
+	"time"
 )
 
 // A StatFS is a file system with the Stat method.
@@ -17,6 +18,10 @@ type StatFS interface {
 // Analogous to: [io/fs.Stat], [os.Stat], stat, ls -l, 9P Tstat,
 // S3 HeadObject.
 //
+// The returned ModTime is always in UTC, regardless of the time zone the
+// backend reports, so that times from different backends compare equal.
+// Use [WithLocalTimes] to receive the backend's FileInfo unchanged.
+//
 // Requires: [StatFS]
 func Stat(ctx context.Context, fsys FS, name string) (FileInfo, error) {
 	var err error
@@ -25,8 +30,53 @@ func Stat(ctx context.Context, fsys FS, name string) (FileInfo, error) {
 	}
 	if sfs, ok := fsys.(StatFS); ok {
 		if info, err := sfs.Stat(ctx, name); !errors.Is(err, ErrUnsupported) {
-			return info, newPathError("stat", name, err)
+			if err != nil {
+				return nil, newPathError("stat", name, err)
+			}
+			return normalizeInfo(ctx, info), nil
 		}
 	}
 	return nil, &PathError{Op: "stat", Path: name, Err: ErrUnsupported}
 }
+
+// normalizeInfo wraps info so its ModTime is in UTC, unless ctx opts out
+// via WithLocalTimes.
+func normalizeInfo(ctx context.Context, info FileInfo) FileInfo {
+	if info == nil || localTimes(ctx) {
+		return info
+	}
+	if info.ModTime().Location() == time.UTC {
+		return info
+	}
+	return utcInfo{info}
+}
+
+// utcInfo reports the ModTime of a FileInfo in UTC.
+type utcInfo struct{ FileInfo }
+
+func (fi utcInfo) ModTime() time.Time { return fi.FileInfo.ModTime().UTC() }
+func (fi utcInfo) BlockSize() int64   { return BlockSize(fi.FileInfo) }
+
+// ContentType forwards the backend's stored MIME type, if any.
+func (fi utcInfo) ContentType() string {
+	if ct, ok := fi.FileInfo.(interface{ ContentType() string }); ok {
+		return ct.ContentType()
+	}
+	return ""
+}
+
+// ETag forwards the backend's entity tag, if any.
+func (fi utcInfo) ETag() string {
+	if et, ok := fi.FileInfo.(interface{ ETag() string }); ok {
+		return et.ETag()
+	}
+	return ""
+}
+
+// VersionID forwards the backend's object version, if any.
+func (fi utcInfo) VersionID() string {
+	if v, ok := fi.FileInfo.(interface{ VersionID() string }); ok {
+		return v.VersionID()
+	}
+	return ""
+}
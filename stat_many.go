@@ -0,0 +1,56 @@
+package fs
+
+import (
+	"context"
+	"errors"
+)
+
+// A StatManyFS is a file system with the StatMany method.
+//
+// Backends whose per-entry metadata is expensive, such as those where
+// [DirEntry.Info] makes a network request, implement StatManyFS so that
+// [Walk] can fetch a directory's metadata in one batch.
+type StatManyFS interface {
+	FS
+
+	// StatMany returns file metadata for each of the named files.
+	// The returned slice has the same length and order as names.
+	// A nil FileInfo means the file does not exist.
+	StatMany(ctx context.Context, names []string) ([]FileInfo, error)
+}
+
+// StatMany returns file metadata for each of the named files, in the same
+// order as names. A nil FileInfo means the file does not exist.
+// Analogous to: stat with multiple operands, S3 ListObjectsV2.
+//
+// Requires: [StatManyFS] || [StatFS]
+func StatMany(
+	ctx context.Context, fsys FS, names []string,
+) ([]FileInfo, error) {
+	local := make([]string, len(names))
+	for i, name := range names {
+		var err error
+		if local[i], err = localizePath(ctx, fsys, name); err != nil {
+			return nil, err
+		}
+	}
+	if sfs, ok := fsys.(StatManyFS); ok {
+		infos, err := sfs.StatMany(ctx, local)
+		if !errors.Is(err, ErrUnsupported) {
+			return infos, err
+		}
+	}
+
+	infos := make([]FileInfo, len(local))
+	for i, name := range local {
+		info, err := Stat(ctx, fsys, name)
+		if errors.Is(err, ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		infos[i] = info
+	}
+	return infos, nil
+}
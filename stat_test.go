@@ -4,8 +4,11 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"testing"
+	"time"
 
 	"lesiw.io/fs"
+	"lesiw.io/fs/memfs"
 	"lesiw.io/fs/osfs"
 )
 
@@ -29,3 +32,78 @@ func ExampleStat() {
 	// Size: 5 bytes
 	// IsDir: false
 }
+
+func TestStatModTimeUTC(t *testing.T) {
+	ctx := context.Background()
+	osFS := osfs.NewTemp()
+	defer fs.Close(osFS)
+
+	for name, fsys := range map[string]fs.FS{
+		"memfs": memfs.New(),
+		"osfs":  osFS,
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := fs.WriteFile(ctx, fsys, "file.txt", []byte("data"))
+			if err != nil {
+				t.Fatalf("WriteFile() error = %v", err)
+			}
+			info, err := fs.Stat(ctx, fsys, "file.txt")
+			if err != nil {
+				t.Fatalf("Stat() error = %v", err)
+			}
+			if got := info.ModTime().Location(); got != time.UTC {
+				t.Errorf("ModTime().Location() = %v, want UTC", got)
+			}
+			info, err = fs.Lstat(ctx, fsys, "file.txt")
+			if err != nil {
+				t.Fatalf("Lstat() error = %v", err)
+			}
+			if got := info.ModTime().Location(); got != time.UTC {
+				t.Errorf("Lstat ModTime().Location() = %v, want UTC", got)
+			}
+		})
+	}
+}
+
+func TestStatWithLocalTimes(t *testing.T) {
+	ctx, fsys := context.Background(), memfs.New()
+
+	if err := fs.WriteFile(ctx, fsys, "file.txt", []byte("data")); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	info, err := fs.Stat(fs.WithLocalTimes(ctx), fsys, "file.txt")
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	if got := info.ModTime().Location(); got != time.Local {
+		t.Errorf("ModTime().Location() = %v, want Local", got)
+	}
+}
+
+// versionFS stats every file as a versioned object with a local ModTime.
+type versionFS struct{ fs.FS }
+
+func (versionFS) Stat(ctx context.Context, name string) (fs.FileInfo, error) {
+	est := time.FixedZone("EST", -5*60*60)
+	mtime := time.Date(2024, 1, 2, 3, 4, 5, 0, est)
+	info := testInfo{size: 3, mtime: mtime, etag: `"abc"`}
+	return versionInfo{info, "v2"}, nil
+}
+
+func TestStatModTimeUTCKeepsMethods(t *testing.T) {
+	ctx := context.Background()
+	info, err := fs.Stat(ctx, versionFS{memfs.New()}, "file.txt")
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	if got := info.ModTime().Location(); got != time.UTC {
+		t.Errorf("ModTime().Location() = %v, want UTC", got)
+	}
+	if got, want := fs.ChangeToken(info), "v2"; got != want {
+		t.Errorf("ChangeToken() = %q, want %q", got, want)
+	}
+	other := testInfo{size: 3, etag: "abc"}
+	if diff := fs.InfoDiff(info, other, fs.InfoETag, 0); diff != 0 {
+		t.Errorf("InfoDiff(InfoETag) = %v, want 0", diff)
+	}
+}
@@ -1,6 +1,10 @@
 package fs
 
-import "context"
+import (
+	"context"
+
+	"lesiw.io/fs/path"
+)
 
 // A SymlinkFS is a file system with the Symlink method.
 type SymlinkFS interface {
@@ -70,11 +74,41 @@ func ReadLink(ctx context.Context, fsys FS, name string) (string, error) {
 	}
 }
 
+// ReadLinkResolved returns the destination of the named symbolic link as a
+// path in fsys. A relative target is joined to the directory containing
+// the link, so the result can be passed back to fsys regardless of where
+// the link lives. Both relative and absolute targets are cleaned.
+// Analogous to: readlink -m (without resolving further links).
+//
+// Only the named link is read; if its target is itself a link, that link is
+// not followed.
+//
+// Requires: [ReadLinkFS]
+func ReadLinkResolved(
+	ctx context.Context, fsys FS, name string,
+) (string, error) {
+	var err error
+	if name, err = localizePath(ctx, fsys, name); err != nil {
+		return "", err
+	}
+	target, err := ReadLink(ctx, fsys, name)
+	if err != nil {
+		return "", err
+	}
+	if path.IsAbs(target) {
+		return path.Clean(target), nil
+	}
+	return path.Clean(path.Join(path.Dir(name), target)), nil
+}
+
 // Lstat returns FileInfo describing the named file.
 // Analogous to: [os.Lstat], stat (without -L).
 // If the file is a symbolic link, the returned FileInfo describes the
 // symbolic link. Lstat makes no attempt to follow the link.
 //
+// As with [Stat], the returned ModTime is in UTC unless [WithLocalTimes] is
+// set.
+//
 // Requires: [ReadLinkFS] || [StatFS]
 func Lstat(ctx context.Context, fsys FS, name string) (FileInfo, error) {
 	var err error
@@ -82,7 +116,47 @@ func Lstat(ctx context.Context, fsys FS, name string) (FileInfo, error) {
 		return nil, err
 	}
 	if rfs, ok := fsys.(ReadLinkFS); ok {
-		return rfs.Lstat(ctx, name)
+		info, err := rfs.Lstat(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		return normalizeInfo(ctx, info), nil
 	}
 	return Stat(ctx, fsys, name)
 }
+
+// LinkInfo returns the destination of the named symbolic link along with
+// FileInfo describing the link itself, as [ReadLink] and [Lstat] would.
+// Analogous to: stat (without -L) with %N, ls -l.
+//
+// If name is not a symbolic link, LinkInfo returns an empty target and the
+// file's own FileInfo. The target is read only for symbolic links, so a
+// plain file costs a single round trip.
+//
+// Requires: [ReadLinkFS]
+func LinkInfo(
+	ctx context.Context, fsys FS, name string,
+) (target string, info FileInfo, err error) {
+	if name, err = localizePath(ctx, fsys, name); err != nil {
+		return "", nil, err
+	}
+	rfs, ok := fsys.(ReadLinkFS)
+	if !ok {
+		return "", nil, &PathError{
+			Op:   "linkinfo",
+			Path: name,
+			Err:  ErrUnsupported,
+		}
+	}
+	if info, err = rfs.Lstat(ctx, name); err != nil {
+		return "", nil, err
+	}
+	info = normalizeInfo(ctx, info)
+	if info.Mode()&ModeSymlink == 0 {
+		return "", info, nil
+	}
+	if target, err = rfs.ReadLink(ctx, name); err != nil {
+		return "", nil, err
+	}
+	return target, info, nil
+}
@@ -0,0 +1,51 @@
+package fs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"syscall"
+)
+
+// A RawConn is a [syscall.RawConn] for an open file. It must be closed to
+// release the file.
+type RawConn interface {
+	syscall.RawConn
+	io.Closer
+}
+
+// A SysFS is a file system with the SysConn method.
+type SysFS interface {
+	FS
+
+	// SysConn opens the named file and returns raw access to its
+	// underlying file descriptor or handle.
+	//
+	// The returned RawConn must be closed when done.
+	SysConn(ctx context.Context, name string) (RawConn, error)
+}
+
+// SysConn opens the named file and returns raw access to its native file
+// descriptor, for local optimizations such as sendfile, mmap, or fd passing.
+// Analogous to: [os.File.SyscallConn].
+//
+// Backends without a local file descriptor return an error satisfying
+// errors.Is(err, [ErrUnsupported]), so portable code can fall back to
+// [Open].
+//
+// The returned [RawConn] must be closed when done.
+//
+// Requires: [SysFS]
+func SysConn(ctx context.Context, fsys FS, name string) (RawConn, error) {
+	var err error
+	if name, err = localizePath(ctx, fsys, name); err != nil {
+		return nil, err
+	}
+	if sfs, ok := fsys.(SysFS); ok {
+		rc, err := sfs.SysConn(ctx, name)
+		if !errors.Is(err, ErrUnsupported) {
+			return rc, newPathError("sysconn", name, err)
+		}
+	}
+	return nil, &PathError{Op: "sysconn", Path: name, Err: ErrUnsupported}
+}
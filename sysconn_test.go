@@ -0,0 +1,55 @@
+//go:build unix
+
+package fs_test
+
+import (
+	"context"
+	"errors"
+	"syscall"
+	"testing"
+
+	"lesiw.io/fs"
+	"lesiw.io/fs/memfs"
+	"lesiw.io/fs/osfs"
+)
+
+func TestSysConn(t *testing.T) {
+	fsys, ctx := osfs.NewTemp(), context.Background()
+	defer fs.Close(fsys)
+
+	if err := fs.WriteFile(ctx, fsys, "file.txt", []byte("data")); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	rc, err := fs.SysConn(ctx, fsys, "file.txt")
+	if err != nil {
+		t.Fatalf("SysConn() error = %v", err)
+	}
+	closeOnCleanup(t, rc)
+
+	var st syscall.Stat_t
+	var statErr error
+	err = rc.Control(func(fd uintptr) {
+		statErr = syscall.Fstat(int(fd), &st)
+	})
+	if err != nil {
+		t.Fatalf("Control() error = %v", err)
+	}
+	if statErr != nil {
+		t.Fatalf("Fstat() error = %v", statErr)
+	}
+	if got, want := st.Size, int64(4); got != want {
+		t.Errorf("Fstat() size = %d, want %d", got, want)
+	}
+}
+
+func TestSysConnUnsupported(t *testing.T) {
+	ctx, fsys := context.Background(), memfs.New()
+
+	if err := fs.WriteFile(ctx, fsys, "file.txt", []byte("data")); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	_, err := fs.SysConn(ctx, fsys, "file.txt")
+	if !errors.Is(err, fs.ErrUnsupported) {
+		t.Errorf("SysConn() error = %v, want ErrUnsupported", err)
+	}
+}
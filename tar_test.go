@@ -1,13 +1,21 @@
 package fs_test
 
 import (
+	"archive/tar"
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+	"time"
 
 	"lesiw.io/fs"
+	"lesiw.io/fs/memfs"
 	"lesiw.io/fs/osfs"
 )
 
@@ -80,3 +88,356 @@ func ExampleCreate_directory() {
 	// Output:
 	// content
 }
+
+// tarNames opens dir as a tar stream and returns its member names.
+func tarNames(
+	ctx context.Context, t *testing.T, fsys fs.FS, dir string,
+) []string {
+	t.Helper()
+	r, err := fs.Open(ctx, fsys, dir)
+	if err != nil {
+		t.Fatalf("Open(%q) error = %v", dir, err)
+	}
+	defer r.Close()
+	var names []string
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("tar Next() error = %v", err)
+		}
+		names = append(names, hdr.Name)
+	}
+	return names
+}
+
+func TestOpenDirTarOrder(t *testing.T) {
+	ctx, fsys := context.Background(), memfs.New()
+	for _, name := range []string{"src/b", "src/a/x", "src/a.txt"} {
+		if err := fs.WriteFile(ctx, fsys, name, []byte(name)); err != nil {
+			t.Fatalf("WriteFile(%q) error = %v", name, err)
+		}
+	}
+
+	first := tarNames(ctx, t, fsys, "src/")
+	second := tarNames(ctx, t, fsys, "src/")
+	if !slices.Equal(first, second) {
+		t.Errorf("tar members = %q, then %q, want identical", first, second)
+	}
+	if want := []string{"a", "a/x", "a.txt", "b"}; !slices.Equal(first, want) {
+		t.Errorf("tar members = %q, want %q", first, want)
+	}
+
+	sorted := tarNames(fs.WithSortedTar(ctx), t, fsys, "src/")
+	if want := []string{"a", "a.txt", "a/x", "b"}; !slices.Equal(sorted, want) {
+		t.Errorf("WithSortedTar: tar members = %q, want %q", sorted, want)
+	}
+}
+
+func TestOpenDirReproducibleTar(t *testing.T) {
+	ctx := fs.WithReproducibleTar(context.Background())
+	fsys := osfs.NewTemp()
+	defer fs.Close(fsys)
+
+	for _, name := range []string{"src/a.txt", "src/sub/b.txt"} {
+		if err := fs.WriteFile(ctx, fsys, name, []byte(name)); err != nil {
+			t.Fatalf("WriteFile(%q) error = %v", name, err)
+		}
+	}
+
+	first, err := fs.ReadFile(ctx, fsys, "src/")
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+
+	mtime := time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC)
+	err = fs.Chtimes(ctx, fsys, "src/sub/b.txt", mtime, mtime)
+	if err != nil {
+		t.Fatalf("Chtimes() error = %v", err)
+	}
+
+	second, err := fs.ReadFile(ctx, fsys, "src/")
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if !bytes.Equal(first, second) {
+		t.Error("tar bytes differ after Chtimes, want identical")
+	}
+
+	tr := tar.NewReader(bytes.NewReader(second))
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("tar Next() error = %v", err)
+		}
+		if !hdr.ModTime.Equal(time.Unix(0, 0)) {
+			t.Errorf("%s: ModTime = %v, want epoch", hdr.Name, hdr.ModTime)
+		}
+		if hdr.Uid != 0 || hdr.Gid != 0 || hdr.Uname != "" {
+			t.Errorf("%s: owner = %d:%d %q, want 0:0 \"\"",
+				hdr.Name, hdr.Uid, hdr.Gid, hdr.Uname)
+		}
+	}
+}
+
+func TestCreateDirStripComponents(t *testing.T) {
+	ctx, fsys := context.Background(), memfs.New()
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, hdr := range []*tar.Header{
+		{Name: "project-1.0/", Typeflag: tar.TypeDir, Mode: 0o755},
+		{Name: "project-1.0/README", Typeflag: tar.TypeReg, Mode: 0o644},
+		{Name: "project-1.0/src/", Typeflag: tar.TypeDir, Mode: 0o755},
+		{Name: "project-1.0/src/main.go", Typeflag: tar.TypeReg, Mode: 0o644},
+		{Name: "./pax_global", Typeflag: tar.TypeReg, Mode: 0o644},
+	} {
+		data := []byte(hdr.Name)
+		if hdr.Typeflag == tar.TypeReg {
+			hdr.Size = int64(len(data))
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatalf("WriteHeader(%q) error = %v", hdr.Name, err)
+		}
+		if hdr.Typeflag == tar.TypeReg {
+			if _, err := tw.Write(data); err != nil {
+				t.Fatalf("Write(%q) error = %v", hdr.Name, err)
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("tar Close() error = %v", err)
+	}
+
+	w, err := fs.Create(fs.WithStripComponents(ctx, 1), fsys, "dest/")
+	if err != nil {
+		t.Fatalf("Create(dest/) error = %v", err)
+	}
+	if _, err := io.Copy(w, &buf); err != nil {
+		t.Fatalf("Copy() error = %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	for name, want := range map[string]string{
+		"dest/README":      "project-1.0/README",
+		"dest/src/main.go": "project-1.0/src/main.go",
+	} {
+		got, err := fs.ReadFile(ctx, fsys, name)
+		if err != nil {
+			t.Errorf("ReadFile(%q) error = %v", name, err)
+		} else if string(got) != want {
+			t.Errorf("ReadFile(%q) = %q, want %q", name, got, want)
+		}
+	}
+	for _, name := range []string{"dest/project-1.0", "dest/pax_global"} {
+		if _, err := fs.Stat(ctx, fsys, name); err == nil {
+			t.Errorf("Stat(%q) error = nil, want not exist", name)
+		}
+	}
+}
+
+func TestAppendDirSkipsSpecialFiles(t *testing.T) {
+	ctx, fsys := context.Background(), memfs.New()
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, hdr := range []*tar.Header{
+		{Name: "pipe", Typeflag: tar.TypeFifo, Mode: 0o644},
+		{
+			Name: "null", Typeflag: tar.TypeChar, Mode: 0o666,
+			Devmajor: 1, Devminor: 3,
+		},
+		{Name: "file.txt", Typeflag: tar.TypeReg, Mode: 0o644, Size: 2},
+	} {
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatalf("WriteHeader(%q) error = %v", hdr.Name, err)
+		}
+	}
+	if _, err := tw.Write([]byte("hi")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("tar Close() error = %v", err)
+	}
+
+	w, err := fs.Append(ctx, fsys, "dest/")
+	if err != nil {
+		t.Fatalf("Append(dest/) error = %v", err)
+	}
+	if _, err := io.Copy(w, &buf); err != nil {
+		t.Fatalf("Copy() error = %v", err)
+	}
+	err = w.Close()
+	if !errors.Is(err, fs.ErrUnsupported) {
+		t.Errorf("Close() error = %v, want ErrUnsupported", err)
+	}
+	for _, name := range []string{"dest/pipe", "dest/null"} {
+		if err == nil || !strings.Contains(err.Error(), name) {
+			t.Errorf("Close() error = %v, want mention of %q", err, name)
+		}
+	}
+	got, err := fs.ReadFile(ctx, fsys, "dest/file.txt")
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if string(got) != "hi" {
+		t.Errorf("ReadFile() = %q, want %q", got, "hi")
+	}
+}
+
+func TestOpenDirTarNumericOwner(t *testing.T) {
+	ctx := fs.WithTarNumericOwner(context.Background())
+	fsys := osfs.NewTemp()
+	defer fs.Close(fsys)
+	for _, name := range []string{"src/a.txt", "src/sub/b.txt"} {
+		if err := fs.WriteFile(ctx, fsys, name, []byte(name)); err != nil {
+			t.Fatalf("WriteFile(%q) error = %v", name, err)
+		}
+	}
+
+	r, err := fs.Open(ctx, fsys, "src/")
+	if err != nil {
+		t.Fatalf("Open(src/) error = %v", err)
+	}
+	defer r.Close()
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("tar Next() error = %v", err)
+		}
+		if hdr.Uname != "" || hdr.Gname != "" {
+			t.Errorf(
+				"%q: Uname, Gname = %q, %q, want empty",
+				hdr.Name, hdr.Uname, hdr.Gname,
+			)
+		}
+	}
+}
+
+// ownerFS records the owners set with Chown.
+type ownerFS struct {
+	fs.FS
+	owners map[string][2]int
+}
+
+func (f *ownerFS) Create(
+	ctx context.Context, name string,
+) (io.WriteCloser, error) {
+	return fs.Create(ctx, f.FS, name)
+}
+
+func (f *ownerFS) Chown(
+	ctx context.Context, name string, uid, gid int,
+) error {
+	f.owners[name] = [2]int{uid, gid}
+	return nil
+}
+
+func TestAppendDirTarNumericOwner(t *testing.T) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	hdr := &tar.Header{
+		Name: "file.txt", Typeflag: tar.TypeReg, Mode: 0o644,
+		Uid: 1234, Gid: 5678, Uname: "alice", Gname: "staff",
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		t.Fatalf("WriteHeader() error = %v", err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("tar Close() error = %v", err)
+	}
+	data := buf.Bytes()
+
+	for _, numeric := range []bool{false, true} {
+		ctx := context.Background()
+		if numeric {
+			ctx = fs.WithTarNumericOwner(ctx)
+		}
+		fsys := &ownerFS{FS: memfs.New(), owners: map[string][2]int{}}
+
+		w, err := fs.Append(ctx, fsys, "dest/")
+		if err != nil {
+			t.Fatalf("Append(dest/) error = %v", err)
+		}
+		if _, err := w.Write(data); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatalf("Close() error = %v", err)
+		}
+
+		want := map[string][2]int{}
+		if numeric {
+			want["dest/file.txt"] = [2]int{1234, 5678}
+		}
+		got := map[string][2]int{}
+		for name, owner := range fsys.owners {
+			got[strings.TrimPrefix(name, "./")] = owner
+		}
+		if !maps.Equal(got, want) {
+			t.Errorf(
+				"numeric=%v: Chown calls = %v, want %v", numeric, got, want,
+			)
+		}
+	}
+}
+
+func TestCompressionRoundTrip(t *testing.T) {
+	fsys := memfs.New()
+	ctx := fs.WithCompression(context.Background(), fs.CompressionGzip)
+	files := map[string]string{
+		"src/a.txt":   strings.Repeat("a", 1000),
+		"src/b/c.txt": "c",
+	}
+	for name, data := range files {
+		if err := fs.WriteFile(ctx, fsys, name, []byte(data)); err != nil {
+			t.Fatalf("WriteFile(%q) error = %v", name, err)
+		}
+	}
+
+	r, err := fs.Open(ctx, fsys, "src/")
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer r.Close()
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(r); err != nil {
+		t.Fatalf("ReadFrom() error = %v", err)
+	}
+	if !bytes.HasPrefix(buf.Bytes(), []byte{0x1f, 0x8b}) {
+		t.Fatalf("Open() stream is not gzip: % x", buf.Bytes()[:2])
+	}
+
+	w, err := fs.Create(ctx, fsys, "dst/")
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	for name, want := range files {
+		name = "dst" + strings.TrimPrefix(name, "src")
+		data, err := fs.ReadFile(ctx, fsys, name)
+		if err != nil {
+			t.Fatalf("ReadFile(%q) error = %v", name, err)
+		}
+		if got := string(data); got != want {
+			t.Errorf("ReadFile(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -208,6 +208,13 @@ func tempDirFallback(
 	// Create directory with mode 0700
 	dirCtx := WithDirMode(ctx, 0700)
 	if err := Mkdir(dirCtx, fsys, dirname); err != nil {
+		if errors.Is(err, ErrUnsupported) {
+			return nil, &PathError{
+				Op:   "temp",
+				Path: name,
+				Err:  ErrUnsupported,
+			}
+		}
 		return nil, err
 	}
 
@@ -0,0 +1,89 @@
+package fs
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"lesiw.io/fs/path"
+)
+
+// TrashDir is the directory that [Trash] moves files into when fsys does
+// not implement [TrashFS].
+const TrashDir = ".trash"
+
+// A TrashFS is a file system with the Trash method.
+//
+// Backends with a native recycle bin, or object stores with soft delete,
+// implement TrashFS.
+type TrashFS interface {
+	FS
+
+	// Trash moves the named file or directory somewhere it can be
+	// restored from, instead of deleting it.
+	Trash(ctx context.Context, name string) error
+}
+
+// Trash moves the named file or directory out of the way so that it can
+// be restored later, instead of deleting it.
+// Analogous to: gio trash, trash-put, the Finder and Explorer recycle bins.
+//
+// If fsys does not implement [TrashFS], or Trash returns
+// [ErrUnsupported], the file is renamed into [TrashDir]. Its path below
+// TrashDir is its original path with a UTC timestamp appended, so that
+// trashing the same path twice keeps both copies:
+//
+//	fs.Trash(ctx, fsys, "docs/report.txt")
+//	// .trash/docs/report.txt.20250102T150405.000000000Z
+//
+// Like any relative name, TrashDir is resolved against the working
+// directory set by [WithWorkDir], if any.
+//
+// Requires: [TrashFS] || ([RenameFS] && [MkdirFS])
+func Trash(ctx context.Context, fsys FS, name string) error {
+	var err error
+	if name, err = localizePath(ctx, fsys, name); err != nil {
+		return err
+	}
+	if tfs, ok := fsys.(TrashFS); ok {
+		err := tfs.Trash(ctx, name)
+		if !errors.Is(err, ErrUnsupported) {
+			return newPathError("trash", name, err)
+		}
+	}
+
+	rel := strings.TrimLeft(strings.TrimPrefix(name, "./"), "/")
+	if rel == "" || rel == "." {
+		return &PathError{Op: "trash", Path: name, Err: ErrInvalid}
+	}
+	if rel == TrashDir || strings.HasPrefix(rel, TrashDir+"/") {
+		return &PathError{Op: "trash", Path: name, Err: ErrInvalid}
+	}
+	if _, err := Stat(ctx, fsys, name); err != nil {
+		return err
+	}
+	stamp := time.Now().UTC().Format("20060102T150405.000000000Z")
+	dst := path.Join(TrashDir, rel+"."+stamp)
+	err = MkdirAll(ctx, fsys, path.Dir(dst))
+	if err != nil && !errors.Is(err, ErrUnsupported) {
+		return err
+	}
+	return Rename(ctx, fsys, name, dst)
+}
+
+// EmptyTrash permanently removes everything in [TrashDir].
+// Analogous to: trash-empty, gio trash --empty.
+//
+// EmptyTrash only removes files trashed by the fallback in [Trash]; it
+// does not empty a backend's native trash. It returns nil if TrashDir
+// does not exist.
+//
+// Requires: See [RemoveAll] requirements
+func EmptyTrash(ctx context.Context, fsys FS) error {
+	err := RemoveAll(ctx, fsys, TrashDir)
+	if errors.Is(err, ErrNotExist) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,83 @@
+package fs_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"lesiw.io/fs"
+	"lesiw.io/fs/memfs"
+)
+
+func TestTrash(t *testing.T) {
+	ctx, fsys := context.Background(), memfs.New()
+	err := fs.WriteFile(ctx, fsys, "docs/report.txt", []byte("report"))
+	if err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	if err := fs.Trash(ctx, fsys, "docs/report.txt"); err != nil {
+		t.Fatalf("Trash() error = %v", err)
+	}
+	_, err = fs.Stat(ctx, fsys, "docs/report.txt")
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Stat(original) error = %v, want ErrNotExist", err)
+	}
+
+	var trashed []string
+	for e, err := range fs.ReadDir(ctx, fsys, ".trash/docs") {
+		if err != nil {
+			t.Fatalf("ReadDir(.trash/docs) error = %v", err)
+		}
+		trashed = append(trashed, e.Name())
+	}
+	if len(trashed) != 1 || !strings.HasPrefix(trashed[0], "report.txt.") {
+		t.Fatalf("ReadDir(.trash/docs) = %v, want [report.txt.<time>]",
+			trashed)
+	}
+	data, err := fs.ReadFile(ctx, fsys, ".trash/docs/"+trashed[0])
+	if err != nil {
+		t.Fatalf("ReadFile(trashed) error = %v", err)
+	}
+	if got, want := string(data), "report"; got != want {
+		t.Errorf("ReadFile(trashed) = %q, want %q", got, want)
+	}
+
+	if err := fs.EmptyTrash(ctx, fsys); err != nil {
+		t.Fatalf("EmptyTrash() error = %v", err)
+	}
+	if _, err := fs.Stat(ctx, fsys, ".trash"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Stat(.trash) error = %v, want ErrNotExist", err)
+	}
+	if err := fs.EmptyTrash(ctx, fsys); err != nil {
+		t.Errorf("EmptyTrash() on empty trash error = %v", err)
+	}
+}
+
+func TestTrashTwice(t *testing.T) {
+	ctx, fsys := context.Background(), memfs.New()
+	for range 2 {
+		if err := fs.WriteFile(ctx, fsys, "file.txt", nil); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+		if err := fs.Trash(ctx, fsys, "file.txt"); err != nil {
+			t.Fatalf("Trash() error = %v", err)
+		}
+	}
+	n, err := fs.Count(ctx, fsys, ".trash")
+	if err != nil {
+		t.Fatalf("Count(.trash) error = %v", err)
+	}
+	if want := 2; n != want {
+		t.Errorf("Count(.trash) = %d, want %d", n, want)
+	}
+}
+
+func TestTrashMissing(t *testing.T) {
+	ctx, fsys := context.Background(), memfs.New()
+	err := fs.Trash(ctx, fsys, "missing.txt")
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Trash(missing) error = %v, want ErrNotExist", err)
+	}
+}
@@ -42,6 +42,11 @@ type TruncateDirFS interface {
 // If the file is larger than size, it is truncated. If it is smaller, it is
 // extended with zeros.
 //
+// Without [TruncateFS], the file is removed and recreated. Its permissions
+// are kept, and are reapplied if the filesystem implements [ChmodFS]. Its
+// ownership is reapplied on a best-effort basis if the filesystem implements
+// [ChownFS].
+//
 // Requires: [TruncateFS] || ([FS] && [RemoveFS] && [CreateFS])
 //
 // # Directories
@@ -72,9 +77,20 @@ func Truncate(ctx context.Context, fsys FS, name string, size int64) error {
 	return recreateTruncate(ctx, fsys, name, size)
 }
 
+// recreateTruncate implements truncate by rewriting the file. The original
+// permissions and ownership are reapplied to the new file where the
+// filesystem allows it.
 func recreateTruncate(
 	ctx context.Context, fsys FS, name string, size int64,
 ) error {
+	var info FileInfo
+	if _, ok := fsys.(StatFS); ok {
+		info, _ = Stat(ctx, fsys, name)
+	}
+	if info != nil {
+		ctx = WithFileMode(ctx, info.Mode().Perm())
+	}
+
 	// Special case: size 0 means create empty file
 	if size == 0 {
 		if err := Remove(ctx, fsys, name); err != nil {
@@ -92,9 +108,14 @@ func recreateTruncate(
 				Err:  err,
 			}
 		}
-		return w.Close()
+		if err := w.Close(); err != nil {
+			return err
+		}
+		return restoreInfo(ctx, fsys, name, info)
 	}
 
+	// Only the bytes that are kept are held in memory; any growth is
+	// written as zeros after them.
 	f, err := Open(ctx, fsys, name)
 	if err != nil {
 		return &PathError{
@@ -103,8 +124,7 @@ func recreateTruncate(
 			Err:  err,
 		}
 	}
-	content := make([]byte, size)
-	n, readErr := io.ReadFull(f, content)
+	content, readErr := io.ReadAll(io.LimitReader(f, size))
 	closeErr := f.Close()
 	if closeErr != nil {
 		return &PathError{
@@ -113,17 +133,13 @@ func recreateTruncate(
 			Err:  closeErr,
 		}
 	}
-	if readErr != nil && readErr != io.ErrUnexpectedEOF {
+	if readErr != nil {
 		return &PathError{
 			Op:   "truncate",
 			Path: name,
 			Err:  readErr,
 		}
 	}
-	content = content[:n]
-	if int64(n) < size {
-		content = append(content, make([]byte, size-int64(n))...)
-	}
 
 	if err := Remove(ctx, fsys, name); err != nil {
 		return &PathError{
@@ -141,7 +157,11 @@ func recreateTruncate(
 			Err:  err,
 		}
 	}
-	if _, err := w.Write(content); err != nil {
+	_, err = w.Write(content)
+	if err == nil {
+		_, err = io.CopyN(w, zeros{}, size-int64(len(content)))
+	}
+	if err != nil {
 		_ = w.Close()
 		return &PathError{
 			Op:   "truncate",
@@ -149,7 +169,43 @@ func recreateTruncate(
 			Err:  err,
 		}
 	}
-	return w.Close()
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return restoreInfo(ctx, fsys, name, info)
+}
+
+// zeros is an endless reader of zero bytes.
+type zeros struct{}
+
+func (zeros) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
+
+// restoreInfo reapplies the permissions and ownership recorded in info to a
+// recreated file. It is a no-op if info is nil, and each attribute is skipped
+// if the filesystem cannot change it.
+func restoreInfo(
+	ctx context.Context, fsys FS, name string, info FileInfo,
+) error {
+	if info == nil {
+		return nil
+	}
+	if _, ok := fsys.(ChmodFS); ok {
+		err := Chmod(ctx, fsys, name, info.Mode().Perm())
+		if err != nil && !errors.Is(err, ErrUnsupported) {
+			return &PathError{Op: "truncate", Path: name, Err: err}
+		}
+	}
+	if uid, gid, ok := sysOwner(info.Sys()); ok {
+		if _, ok := fsys.(ChownFS); ok {
+			// Only privileged callers may give a file away, so a failure
+			// to restore ownership is not an error.
+			_ = Chown(ctx, fsys, name, uid, gid)
+		}
+	}
+	return nil
 }
 
 func truncateDirAsTar(
@@ -199,9 +255,9 @@ func recreateTruncateDir(
 		}
 	}
 
-	if _, ok := fsys.(MkdirFS); ok {
-		return MkdirAll(ctx, fsys, dir)
+	created, err := mkdirAllOrVirtual(ctx, fsys, dir)
+	if err != nil || created {
+		return err
 	}
-
 	return &PathError{Op: "truncate", Path: dir, Err: ErrUnsupported}
 }
@@ -1,11 +1,16 @@
 package fs_test
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"log"
+	"runtime"
+	"testing"
 
 	"lesiw.io/fs"
+	"lesiw.io/fs/memfs"
 	"lesiw.io/fs/osfs"
 )
 
@@ -29,3 +34,89 @@ func ExampleTruncate() {
 	// Output:
 	// Hello
 }
+
+func TestTruncateFallbackPreservesMode(t *testing.T) {
+	for _, size := range []int64{0, 2, 8} {
+		ctx := context.Background()
+		fsys := &chmodFS{memfs.New(), make(map[string]fs.Mode)}
+
+		err := fs.WriteFile(ctx, fsys, "f", []byte("abcd"))
+		if err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+		if err := fs.Chmod(ctx, fsys, "f", 0600); err != nil {
+			t.Fatalf("Chmod() error = %v", err)
+		}
+
+		if err := fs.Truncate(ctx, fsys, "f", size); err != nil {
+			t.Fatalf("Truncate(%d) error = %v", size, err)
+		}
+
+		info, err := fs.Stat(ctx, fsys, "f")
+		if err != nil {
+			t.Fatalf("Stat() error = %v", err)
+		}
+		if got, want := info.Size(), size; got != want {
+			t.Errorf("Truncate(%d): Size() = %d, want %d", size, got, want)
+		}
+		if got, want := info.Mode().Perm(), fs.Mode(0600); got != want {
+			t.Errorf("Truncate(%d): Mode().Perm() = %v, want %v",
+				size, got, want)
+		}
+	}
+}
+
+// growFS holds one file without TruncateFS. Writes to it are checked
+// instead of stored: all bytes after the original content must be zero.
+type growFS struct {
+	data    []byte
+	written int64
+	nonzero bool
+}
+
+func (f *growFS) Open(context.Context, string) (io.ReadCloser, error) {
+	return io.NopCloser(bytes.NewReader(f.data)), nil
+}
+
+func (f *growFS) Remove(context.Context, string) error { return nil }
+
+func (f *growFS) Create(context.Context, string) (io.WriteCloser, error) {
+	f.written = 0
+	return f, nil
+}
+
+func (f *growFS) Write(p []byte) (int, error) {
+	for i, b := range p {
+		off := f.written + int64(i)
+		if off >= int64(len(f.data)) && b != 0 {
+			f.nonzero = true
+		}
+	}
+	f.written += int64(len(p))
+	return len(p), nil
+}
+
+func (f *growFS) Close() error { return nil }
+
+func TestTruncateFallbackGrowStreams(t *testing.T) {
+	ctx := context.Background()
+	fsys := &growFS{data: bytes.Repeat([]byte("x"), 1024)}
+	const size = 10 << 20
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	if err := fs.Truncate(ctx, fsys, "big", size); err != nil {
+		t.Fatalf("Truncate() error = %v", err)
+	}
+	runtime.ReadMemStats(&after)
+
+	if fsys.written != size {
+		t.Errorf("Truncate() wrote %d bytes, want %d", fsys.written, size)
+	}
+	if fsys.nonzero {
+		t.Errorf("Truncate() tail is not zero-filled")
+	}
+	if alloc := after.TotalAlloc - before.TotalAlloc; alloc > size/10 {
+		t.Errorf("Truncate() allocated %d bytes, want < %d", alloc, size/10)
+	}
+}
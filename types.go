@@ -86,20 +86,28 @@
 //   - [ChmodFS] - Change file permissions
 //   - [ChownFS] - Change file ownership
 //   - [ChtimesFS] - Change file timestamps
+//   - [CopyFS] - Copy files without reading them through the client
+//   - [CountFS] - Count directory entries without listing them
 //   - [CreateFS] - Create or truncate files for writing
 //   - [DirFS] - Read directories as tar streams
+//   - [ExistsFS] - Check whether files exist
 //   - [GlobFS] - Pattern-based file matching
+//   - [LinkFS] - Create hard links
 //   - [LocalizeFS] - OS-specific path formatting
 //   - [MkdirFS] - Create directories
+//   - [MknodFS] - Create named pipes, sockets, and device files
+//   - [MountFS] - Detect mount points
 //   - [ReadDirFS] - List directory contents
 //   - [ReadLinkFS] - Read symlink targets and stat without following
 //   - [RemoveAllFS] - Recursively delete directories
 //   - [RemoveFS] - Delete files and empty directories
 //   - [RenameFS] - Move or rename files
+//   - [SameFileFS] - Check whether two paths name the same file
 //   - [StatFS] - Query file metadata
 //   - [SymlinkFS] - Create symbolic links
 //   - [TempDirFS] - Native temporary directory support
 //   - [TempFS] - Native temporary file support
+//   - [TrashFS] - Move files to a native trash instead of deleting them
 //   - [TruncateDirFS] - Efficiently empty directories
 //   - [TruncateFS] - Change file size
 //   - [WalkFS] - Efficient directory traversal
@@ -188,6 +196,8 @@
 //     new content appended when [AppendFS] is not implemented.
 //   - [Rename] falls back to copying and deleting when [RenameFS] is not
 //     implemented.
+//   - [Copy] falls back to reading the source and writing the destination
+//     when [CopyFS] is not implemented.
 //   - [Truncate] falls back to creating an empty file when size is 0, or
 //     reading, removing, and recreating the file with adjusted size for
 //     non-zero sizes, when [TruncateFS] is not implemented.
@@ -342,6 +352,19 @@ var (
 	ErrClosed      = fs.ErrClosed
 	ErrUnsupported = errors.ErrUnsupported
 	ErrNotDir      = errors.New("not a directory")
+	ErrIsDir       = errors.New("is a directory")
+
+	// ErrVerifyFailed is returned under WithVerifyWrite when a file does
+	// not read back as written.
+	ErrVerifyFailed = errors.New("write verification failed")
+
+	// ErrWalkBudgetExceeded is yielded by Walk under WithWalkBudget when
+	// the tree holds more entries than the budget allows.
+	ErrWalkBudgetExceeded = errors.New("walk budget exceeded")
+
+	// ErrDecryptFailed is returned when a file read through Encrypt has
+	// been altered or truncated, or was written with another key.
+	ErrDecryptFailed = errors.New("decryption failed")
 )
 
 // Valid values for [Mode].
@@ -0,0 +1,20 @@
+//go:build linux
+
+package fs
+
+import (
+	"syscall"
+	"testing"
+)
+
+func TestProcUmask(t *testing.T) {
+	defer syscall.Umask(syscall.Umask(0o027))
+
+	got, ok := procUmask()
+	if !ok {
+		t.Fatal("procUmask() ok = false, want true")
+	}
+	if want := Mode(0o027); got != want {
+		t.Errorf("procUmask() = %v, want %v", got, want)
+	}
+}
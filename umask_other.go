@@ -0,0 +1,5 @@
+//go:build !unix
+
+package fs
+
+func processUmask() Mode { return 0 }
@@ -0,0 +1,48 @@
+//go:build unix
+
+package fs
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"strconv"
+	"sync"
+	"syscall"
+)
+
+// processUmask returns the umask of the process, read once.
+//
+// Linux reports the umask in /proc/self/status. Elsewhere, reading it
+// requires setting it: the umask is set to 0 and restored immediately, and
+// a file created by another goroutine in between gets the permissions it
+// asked for, unmasked.
+var processUmask = sync.OnceValue(func() Mode {
+	if mask, ok := procUmask(); ok {
+		return mask
+	}
+	mask := syscall.Umask(0)
+	syscall.Umask(mask)
+	return Mode(mask) & ModePerm
+})
+
+// procUmask reads the umask from the Umask line of /proc/self/status.
+func procUmask() (Mode, bool) {
+	data, err := os.ReadFile("/proc/self/status")
+	if err != nil {
+		return 0, false
+	}
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		v, ok := bytes.CutPrefix(sc.Bytes(), []byte("Umask:"))
+		if !ok {
+			continue
+		}
+		mask, err := strconv.ParseUint(string(bytes.TrimSpace(v)), 8, 32)
+		if err != nil {
+			return 0, false
+		}
+		return Mode(mask) & ModePerm, true
+	}
+	return 0, false
+}
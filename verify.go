@@ -0,0 +1,61 @@
+package fs
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"hash"
+	"io"
+)
+
+// verifyCloser digests the data written through it, and on Close checks
+// that the file holds data with the same digest.
+//
+// MD5 guards against corruption, not tampering, and is what S3 reports as
+// the ETag of a single-part upload.
+type verifyCloser struct {
+	io.WriteCloser
+	ctx  context.Context
+	fsys FS
+	name string
+	sum  hash.Hash
+}
+
+// verifyOnClose wraps wc so that Close verifies the named file.
+func verifyOnClose(
+	ctx context.Context, fsys FS, name string, wc io.WriteCloser,
+) io.WriteCloser {
+	return &verifyCloser{wc, ctx, fsys, name, md5.New()}
+}
+
+func (v *verifyCloser) Write(p []byte) (int, error) {
+	n, err := v.WriteCloser.Write(p)
+	v.sum.Write(p[:n])
+	return n, err
+}
+
+func (v *verifyCloser) Close() error {
+	if err := v.WriteCloser.Close(); err != nil {
+		return err
+	}
+	want := v.sum.Sum(nil)
+	if info, err := Stat(v.ctx, v.fsys, v.name); err == nil {
+		if etag(info) == hex.EncodeToString(want) {
+			return nil
+		}
+	}
+	r, err := v.fsys.Open(v.ctx, v.name)
+	if err != nil {
+		return &PathError{Op: "verify", Path: v.name, Err: err}
+	}
+	defer r.Close()
+	got := md5.New()
+	if _, err := io.Copy(got, r); err != nil {
+		return &PathError{Op: "verify", Path: v.name, Err: err}
+	}
+	if !bytes.Equal(got.Sum(nil), want) {
+		return &PathError{Op: "verify", Path: v.name, Err: ErrVerifyFailed}
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package fs_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"io"
+	"testing"
+
+	"lesiw.io/fs"
+	"lesiw.io/fs/memfs"
+)
+
+// verifyFS stores files in memory, optionally corrupting them on read and
+// reporting an ETag from Stat, and counts Opens.
+type verifyFS struct {
+	fsys    fs.FS
+	corrupt bool
+	etag    string
+	opens   int
+}
+
+func (f *verifyFS) Open(ctx context.Context, name string) (
+	io.ReadCloser, error,
+) {
+	f.opens++
+	data, err := fs.ReadFile(ctx, f.fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	if f.corrupt && len(data) > 0 {
+		data[len(data)/2] ^= 0xFF
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (f *verifyFS) Create(ctx context.Context, name string) (
+	io.WriteCloser, error,
+) {
+	return fs.Create(ctx, f.fsys, name)
+}
+
+func (f *verifyFS) Stat(ctx context.Context, name string) (
+	fs.FileInfo, error,
+) {
+	info, err := fs.Stat(ctx, f.fsys, name)
+	if err != nil || f.etag == "" {
+		return info, err
+	}
+	return testInfo{size: info.Size(), mode: info.Mode(), etag: f.etag}, nil
+}
+
+func TestVerifyWrite(t *testing.T) {
+	data := []byte("critical data")
+	sum := md5.Sum(data)
+
+	tests := []struct {
+		name      string
+		fsys      *verifyFS
+		wantErr   error
+		wantOpens int
+	}{
+		{"Good", &verifyFS{fsys: memfs.New()}, nil, 1},
+		{
+			"Corrupt", &verifyFS{fsys: memfs.New(), corrupt: true},
+			fs.ErrVerifyFailed, 1,
+		},
+		{
+			"ETag", &verifyFS{
+				fsys:    memfs.New(),
+				corrupt: true,
+				etag:    `"` + hex.EncodeToString(sum[:]) + `"`,
+			},
+			nil, 0,
+		},
+		{
+			"OtherETag", &verifyFS{
+				fsys: memfs.New(), corrupt: true, etag: "abc-2",
+			},
+			fs.ErrVerifyFailed, 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := fs.WithVerifyWrite(context.Background())
+
+			err := fs.WriteFile(ctx, tt.fsys, "file.txt", data)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("WriteFile() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.fsys.opens != tt.wantOpens {
+				t.Errorf(
+					"Open() calls = %d, want %d", tt.fsys.opens, tt.wantOpens,
+				)
+			}
+		})
+	}
+}
+
+func TestVerifyWriteCreate(t *testing.T) {
+	ctx := fs.WithVerifyWrite(context.Background())
+	fsys := &verifyFS{fsys: memfs.New(), corrupt: true}
+
+	w, err := fs.Create(ctx, fsys, "file.txt")
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	for _, s := range []string{"one ", "two ", "three"} {
+		if _, err := io.WriteString(w, s); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	if err := w.Close(); !errors.Is(err, fs.ErrVerifyFailed) {
+		t.Errorf("Close() error = %v, want ErrVerifyFailed", err)
+	}
+
+	fsys.opens = 0
+	if err := fs.WriteFile(context.Background(), fsys, "b", nil); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if fsys.opens != 0 {
+		t.Errorf("Open() calls unverified = %d, want 0", fsys.opens)
+	}
+}
@@ -0,0 +1,53 @@
+package fs
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"lesiw.io/fs/path"
+)
+
+// VirtualDirStat returns metadata for a virtual directory.
+//
+// Object stores have no real directories: a directory exists only while
+// some object has its path as a prefix. VirtualDirStat reads the named
+// directory and reports it as a directory if it has at least one child.
+// Otherwise, it returns an error satisfying errors.Is(err, [ErrNotExist]).
+//
+// The returned FileInfo is the same for every backend: its mode is
+// 0755|[ModeDir], its size is 0, and its ModTime is the zero time.
+//
+// VirtualDirStat is intended for [StatFS] implementations that must
+// synthesize directory info. The name is passed to ReadDir unchanged, so
+// it must already be in the backend's native form.
+//
+// Requires: [ReadDirFS]
+func VirtualDirStat(
+	ctx context.Context, fsys FS, name string,
+) (FileInfo, error) {
+	rdfs, ok := fsys.(ReadDirFS)
+	if !ok {
+		return nil, &PathError{Op: "stat", Path: name, Err: ErrUnsupported}
+	}
+	for _, err := range rdfs.ReadDir(ctx, name) {
+		if errors.Is(err, ErrNotExist) || errors.Is(err, ErrNotDir) {
+			break
+		}
+		if err != nil {
+			return nil, newPathError("stat", name, err)
+		}
+		return &virtualDirInfo{name: path.Base(name)}, nil
+	}
+	return nil, &PathError{Op: "stat", Path: name, Err: ErrNotExist}
+}
+
+// virtualDirInfo implements FileInfo for virtual directories.
+type virtualDirInfo struct{ name string }
+
+func (fi *virtualDirInfo) Name() string       { return fi.name }
+func (fi *virtualDirInfo) Size() int64        { return 0 }
+func (fi *virtualDirInfo) Mode() Mode         { return 0755 | ModeDir }
+func (fi *virtualDirInfo) ModTime() time.Time { return time.Time{} }
+func (fi *virtualDirInfo) IsDir() bool        { return true }
+func (fi *virtualDirInfo) Sys() any           { return nil }
@@ -0,0 +1,56 @@
+package fs_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"lesiw.io/fs"
+	"lesiw.io/fs/memfs"
+)
+
+func TestVirtualDirStat(t *testing.T) {
+	ctx, fsys := context.Background(), memfs.New()
+
+	err := fs.WriteFile(ctx, fsys, "prefix/file.txt", []byte("data"))
+	if err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	info, err := fs.VirtualDirStat(ctx, fsys, "prefix")
+	if err != nil {
+		t.Fatalf("VirtualDirStat() error = %v", err)
+	}
+	if got, want := info.Name(), "prefix"; got != want {
+		t.Errorf("Name() = %q, want %q", got, want)
+	}
+	if !info.IsDir() {
+		t.Errorf("IsDir() = false, want true")
+	}
+	if got, want := info.Mode(), 0755|fs.ModeDir; got != want {
+		t.Errorf("Mode() = %v, want %v", got, want)
+	}
+	if got := info.Size(); got != 0 {
+		t.Errorf("Size() = %d, want 0", got)
+	}
+	if got := info.ModTime(); !got.Equal(time.Time{}) {
+		t.Errorf("ModTime() = %v, want zero time", got)
+	}
+}
+
+func TestVirtualDirStatNoChildren(t *testing.T) {
+	ctx, fsys := context.Background(), memfs.New()
+
+	if err := fs.Mkdir(ctx, fsys, "empty"); err != nil {
+		t.Fatalf("Mkdir() error = %v", err)
+	}
+
+	for _, name := range []string{"empty", "missing"} {
+		_, err := fs.VirtualDirStat(ctx, fsys, name)
+		if !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("VirtualDirStat(%q) error = %v, want ErrNotExist",
+				name, err)
+		}
+	}
+}
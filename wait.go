@@ -0,0 +1,85 @@
+package fs
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const (
+	waitMinDelay = 10 * time.Millisecond
+	waitMaxDelay = time.Second
+)
+
+// WaitFor polls [Exists] until the named file exists, or until it no longer
+// exists if exists is false.
+// Analogous to: aws s3api wait object-exists, inotifywait.
+//
+// WaitFor is useful after writing to an eventually consistent store, where
+// a new object may briefly be reported missing. Polls start 10ms apart and
+// back off exponentially to at most one second. On a filesystem with
+// neither [ExistsFS] nor [StatFS], WaitFor polls by opening the file.
+//
+// If timeout elapses first, WaitFor returns an error wrapping
+// [ErrNotExist] when waiting for the file to appear, or [ErrExist] when
+// waiting for it to disappear. If timeout is not positive, WaitFor checks
+// once. Any other error from a poll is returned immediately, as is the
+// context's error if ctx is done.
+//
+// Requires: [FS]
+func WaitFor(
+	ctx context.Context, fsys FS, name string,
+	exists bool, timeout time.Duration,
+) error {
+	var err error
+	if name, err = localizePath(ctx, fsys, name); err != nil {
+		return err
+	}
+	deadline := time.Now().Add(timeout)
+	delay := waitMinDelay
+	for {
+		ok, err := waitExists(ctx, fsys, name)
+		if err != nil {
+			return err
+		} else if ok == exists {
+			return nil
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			werr := ErrNotExist
+			if !exists {
+				werr = ErrExist
+			}
+			return &PathError{Op: "wait", Path: name, Err: werr}
+		}
+		timer := time.NewTimer(min(delay, remaining))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return &PathError{Op: "wait", Path: name, Err: ctx.Err()}
+		case <-timer.C:
+		}
+		delay = min(delay*2, waitMaxDelay)
+	}
+}
+
+// waitExists reports whether name exists, through [Exists], or by opening
+// it if fsys supports neither ExistsFS nor StatFS.
+func waitExists(ctx context.Context, fsys FS, name string) (bool, error) {
+	ok, err := Exists(ctx, fsys, name)
+	if !errors.Is(err, ErrUnsupported) {
+		return ok, err
+	}
+	r, err := fsys.Open(ctx, name)
+	switch {
+	case errors.Is(err, ErrNotExist):
+		return false, nil
+	case errors.Is(err, ErrIsDir):
+		return true, nil
+	case err != nil:
+		return false, newPathError("wait", name, err)
+	}
+	_ = r.Close()
+	return true, nil
+}
@@ -0,0 +1,98 @@
+package fs_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"lesiw.io/fs"
+	"lesiw.io/fs/memfs"
+)
+
+// laggyFS reports files as missing for the first lag calls to Stat.
+type laggyFS struct {
+	fs.FS
+	lag, polls int
+}
+
+func (f *laggyFS) Stat(ctx context.Context, name string) (fs.FileInfo, error) {
+	f.polls++
+	if f.polls <= f.lag {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+	}
+	return fs.Stat(ctx, f.FS, name)
+}
+
+func TestWaitFor(t *testing.T) {
+	ctx, mem := context.Background(), memfs.New()
+	if err := fs.WriteFile(ctx, mem, "file.txt", []byte("data")); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	fsys := &laggyFS{FS: mem, lag: 3}
+
+	if err := fs.WaitFor(ctx, fsys, "file.txt", true, time.Second); err != nil {
+		t.Fatalf("WaitFor() error = %v", err)
+	}
+	if got, want := fsys.polls, 4; got != want {
+		t.Errorf("Stat calls = %d, want %d", got, want)
+	}
+}
+
+func TestWaitForTimeout(t *testing.T) {
+	ctx := context.Background()
+	fsys := &laggyFS{FS: memfs.New()}
+
+	err := fs.WaitFor(ctx, fsys, "missing.txt", true, 50*time.Millisecond)
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("WaitFor(missing) error = %v, want ErrNotExist", err)
+	}
+	if fsys.polls < 2 {
+		t.Errorf("Stat calls = %d, want at least 2", fsys.polls)
+	}
+}
+
+func TestWaitForGone(t *testing.T) {
+	ctx, fsys := context.Background(), memfs.New()
+	if err := fs.WriteFile(ctx, fsys, "file.txt", []byte("data")); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	err := fs.WaitFor(ctx, fsys, "file.txt", false, 20*time.Millisecond)
+	if !errors.Is(err, fs.ErrExist) {
+		t.Errorf("WaitFor(exists=false) error = %v, want ErrExist", err)
+	}
+	if err := fs.WaitFor(ctx, fsys, "gone.txt", false, 0); err != nil {
+		t.Errorf("WaitFor(gone.txt, exists=false) error = %v", err)
+	}
+}
+
+func TestWaitForCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	fsys := &laggyFS{FS: memfs.New()}
+
+	err := fs.WaitFor(ctx, fsys, "missing.txt", true, time.Minute)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("WaitFor() error = %v, want context.Canceled", err)
+	}
+}
+
+func TestWaitForWithoutStat(t *testing.T) {
+	ctx, mem := context.Background(), memfs.New()
+	if err := fs.WriteFile(ctx, mem, "file.txt", []byte("data")); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	fsys := &noStatFS{mem}
+
+	if err := fs.WaitFor(ctx, fsys, "file.txt", true, 0); err != nil {
+		t.Errorf("WaitFor(file.txt) error = %v", err)
+	}
+	err := fs.WaitFor(ctx, fsys, "missing.txt", true, 0)
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("WaitFor(missing) error = %v, want ErrNotExist", err)
+	}
+	if err := fs.WaitFor(ctx, fsys, "missing.txt", false, 0); err != nil {
+		t.Errorf("WaitFor(missing, gone) error = %v", err)
+	}
+}
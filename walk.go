@@ -3,8 +3,10 @@ package fs
 import (
 	"cmp"
 	"context"
+	"errors"
 	"iter"
 	"slices"
+	"strings"
 
 	"lesiw.io/fs/path"
 )
@@ -43,6 +45,10 @@ type WalkFS interface {
 // entries. Analogous to: [os.ReadDir], [io/fs.ReadDir], ls, 9P Tread on
 // directory.
 //
+// Each name is yielded at most once, and entries naming the directory
+// itself (an empty name, "." or "..") are dropped. This hides the directory
+// markers that some object stores keep alongside a directory's children.
+//
 // Requires: [ReadDirFS] || [WalkFS]
 func ReadDir(
 	ctx context.Context, fsys FS, name string,
@@ -53,6 +59,40 @@ func ReadDir(
 			yield(nil, err)
 		}
 	}
+	seq := dedupeEntries(readDir(ctx, fsys, name))
+	if skipHidden(ctx) {
+		seq = filterHidden(seq, "")
+	}
+	return seq
+}
+
+// dedupeEntries drops self entries and repeated names from seq.
+func dedupeEntries(
+	seq iter.Seq2[DirEntry, error],
+) iter.Seq2[DirEntry, error] {
+	return func(yield func(DirEntry, error) bool) {
+		seen := make(map[string]bool)
+		for e, err := range seq {
+			if err == nil {
+				switch name := e.Name(); {
+				case name == "" || name == "." || name == "..":
+					continue
+				case seen[name]:
+					continue
+				default:
+					seen[name] = true
+				}
+			}
+			if !yield(e, err) {
+				return
+			}
+		}
+	}
+}
+
+func readDir(
+	ctx context.Context, fsys FS, name string,
+) iter.Seq2[DirEntry, error] {
 	if rdfs, ok := fsys.(ReadDirFS); ok {
 		return rdfs.ReadDir(ctx, name)
 	}
@@ -109,7 +149,15 @@ func ReadDir(
 //
 // If an error occurs reading a directory, the iteration yields a zero
 // DirEntry and the error. The caller can choose to continue iterating
-// (skip that directory) or break to stop the walk.
+// (skip that directory) or break to stop the walk. Likewise, if an entry's
+// info cannot be read, the error is yielded in its place, but an entry
+// that is a directory is still traversed. Under [WithIgnoreVanished],
+// entries removed during the walk are skipped without an error. Under
+// [WithStayOnFilesystem], mount points are yielded but not descended into.
+// Under [WithConcurrency], the fallback built on [ReadDirFS] reads several
+// directories at once, without changing the order of the entries. Under
+// [WithWalkBudget], the walk stops with an error once it would exceed the
+// budget.
 //
 // Requires: [WalkFS] || [ReadDirFS]
 func Walk(
@@ -122,12 +170,17 @@ func Walk(
 		}
 	}
 	if wfs, ok := fsys.(WalkFS); ok {
-		return wfs.Walk(ctx, root, depth)
+		seq := wfs.Walk(ctx, root, depth)
+		if skipHidden(ctx) {
+			seq = filterHidden(seq, root)
+		}
+		return limitWalk(seq, root, walkBudget(ctx))
 	}
 
 	// Fallback to ReadDir if available
 	if _, ok := fsys.(ReadDirFS); ok {
-		return walkBreadthFirst(ctx, fsys, root, depth)
+		seq := walkBreadthFirst(ctx, fsys, root, depth)
+		return limitWalk(seq, root, walkBudget(ctx))
 	}
 
 	// No Walk or ReadDir support
@@ -140,6 +193,195 @@ func Walk(
 	}
 }
 
+// limitWalk ends seq with [ErrWalkBudgetExceeded] in place of the entry
+// after the first budget entries. A budget less than 1 is no limit.
+func limitWalk(
+	seq iter.Seq2[DirEntry, error], root string, budget int,
+) iter.Seq2[DirEntry, error] {
+	if budget < 1 {
+		return seq
+	}
+	return func(yield func(DirEntry, error) bool) {
+		var n int
+		for e, err := range seq {
+			if err == nil {
+				if n == budget {
+					yield(nil, &PathError{
+						Op:   "walk",
+						Path: root,
+						Err:  ErrWalkBudgetExceeded,
+					})
+					return
+				}
+				n++
+			}
+			if !yield(e, err) {
+				return
+			}
+		}
+	}
+}
+
+// filterHidden drops hidden entries from seq. If root is set, entries are
+// also dropped when any element of their path below root is hidden, so
+// that native walks skip the contents of hidden directories.
+func filterHidden(
+	seq iter.Seq2[DirEntry, error], root string,
+) iter.Seq2[DirEntry, error] {
+	return func(yield func(DirEntry, error) bool) {
+		for e, err := range seq {
+			if err == nil && isHiddenEntry(e, root) {
+				continue
+			}
+			if !yield(e, err) {
+				return
+			}
+		}
+	}
+}
+
+// WalkWithCount is like [Walk], but also returns the number of entries the
+// walk will yield, for use as the denominator of a progress bar.
+//
+// The total is known cheaply when depth is 1 and fsys implements
+// [CountFS]. Otherwise, if ctx carries [WithPrecount], WalkWithCount walks
+// the tree once to count its entries, which doubles the cost of the
+// traversal; if that walk fails, its first error is returned. Without
+// either, total is -1.
+//
+// The total is a snapshot: entries created or removed between the count
+// and the walk are not reflected in it.
+//
+// Requires: [WalkFS] || [ReadDirFS]
+func WalkWithCount(
+	ctx context.Context, fsys FS, root string, depth int,
+) (total int, entries iter.Seq2[DirEntry, error], err error) {
+	entries = Walk(ctx, fsys, root, depth)
+	if _, ok := fsys.(CountFS); ok && depth == 1 {
+		if total, err = Count(ctx, fsys, root); err != nil {
+			return 0, nil, err
+		}
+		return total, entries, nil
+	}
+	if !precount(ctx) {
+		return -1, entries, nil
+	}
+	for _, err := range entries {
+		if err != nil {
+			return 0, nil, err
+		}
+		total++
+	}
+	return total, entries, nil
+}
+
+// WalkFiles traverses the filesystem rooted at root, yielding only regular
+// files. Symbolic links, directories, and special files such as devices and
+// sockets are skipped, but directories are still descended into.
+// Analogous to: find -type f.
+//
+// WalkFiles walks to unlimited depth and otherwise behaves like [Walk]:
+// entries have Path() populated, and errors are yielded as they occur.
+//
+// Requires: [WalkFS] || [ReadDirFS]
+func WalkFiles(
+	ctx context.Context, fsys FS, root string,
+) iter.Seq2[DirEntry, error] {
+	return filterType(Walk(ctx, fsys, root, 0), func(e DirEntry) bool {
+		return e.Type()&ModeType == 0
+	})
+}
+
+// WalkDirs traverses the filesystem rooted at root, yielding only
+// directories.
+// Analogous to: find -type d.
+//
+// WalkDirs walks to unlimited depth and otherwise behaves like [Walk]. A
+// symbolic link to a directory is not a directory and is not yielded.
+//
+// Requires: [WalkFS] || [ReadDirFS]
+func WalkDirs(
+	ctx context.Context, fsys FS, root string,
+) iter.Seq2[DirEntry, error] {
+	return filterType(Walk(ctx, fsys, root, 0), func(e DirEntry) bool {
+		return e.Type()&ModeSymlink == 0 && e.IsDir()
+	})
+}
+
+// filterType drops entries from seq for which keep returns false. Errors
+// are always yielded.
+func filterType(
+	seq iter.Seq2[DirEntry, error], keep func(DirEntry) bool,
+) iter.Seq2[DirEntry, error] {
+	return func(yield func(DirEntry, error) bool) {
+		for e, err := range seq {
+			if err == nil && !keep(e) {
+				continue
+			}
+			if !yield(e, err) {
+				return
+			}
+		}
+	}
+}
+
+func isHiddenEntry(e DirEntry, root string) bool {
+	if isHiddenName(e.Name()) || sysHidden(e) {
+		return true
+	}
+	if root == "" || e.Path() == "" {
+		return false
+	}
+	rel := strings.TrimPrefix(e.Path(), root)
+	for _, elem := range strings.FieldsFunc(rel, func(r rune) bool {
+		return r == '/' || r == '\\'
+	}) {
+		if isHiddenName(elem) {
+			return true
+		}
+	}
+	return false
+}
+
+// isHiddenName reports whether name is a dotfile.
+func isHiddenName(name string) bool {
+	return len(name) > 1 && name[0] == '.' && name != ".."
+}
+
+// vanished reports whether err is due to a file that was removed during
+// the walk and [WithIgnoreVanished] is set.
+func vanished(ctx context.Context, err error) bool {
+	return ignoreVanished(ctx) && errors.Is(err, ErrNotExist)
+}
+
+// statEntries returns the FileInfo of each entry in dir using a single
+// StatMany call, or nil if fsys does not implement StatManyFS or the batch
+// fails. Entries missing from the batch fall back to DirEntry.Info, and so
+// do symbolic links: StatMany follows them, as Stat does, while Info
+// describes the link itself.
+func statEntries(
+	ctx context.Context, fsys FS, dir string, entries []DirEntry,
+) []FileInfo {
+	sfs, ok := fsys.(StatManyFS)
+	if !ok || len(entries) == 0 {
+		return nil
+	}
+	names := make([]string, len(entries))
+	for i, e := range entries {
+		names[i] = path.Join(dir, e.Name())
+	}
+	infos, err := sfs.StatMany(ctx, names)
+	if err != nil || len(infos) != len(entries) {
+		return nil
+	}
+	for i, e := range entries {
+		if e.Type()&ModeSymlink != 0 {
+			infos[i] = nil
+		}
+	}
+	return infos
+}
+
 // readDirEntry implements DirEntry for ReadDir (no path/depth).
 type readDirEntry struct {
 	name  string
@@ -173,57 +415,116 @@ func (we *walkEntry) Path() string            { return we.path }
 type queueItem struct {
 	path  string
 	depth int
+
+	// done receives the listing once it has been read in the background
+	// under WithConcurrency. It is nil if the read has not started.
+	done chan walkListing
+}
+
+// walkListing is the result of reading one directory during a walk.
+type walkListing struct {
+	entries []DirEntry
+	infos   []FileInfo
+	err     error
+}
+
+// listWalkDir reads the entries of dir in lexicographic order, stopping at
+// the first error.
+func listWalkDir(ctx context.Context, fsys FS, dir string) walkListing {
+	var l walkListing
+	for entry, err := range ReadDir(ctx, fsys, dir) {
+		if err == nil {
+			err = ctx.Err()
+		}
+		if err != nil {
+			l.err = err
+			break
+		}
+		l.entries = append(l.entries, entry)
+	}
+
+	// Sort entries lexicographically
+	slices.SortFunc(l.entries, func(a, b DirEntry) int {
+		return cmp.Compare(a.Name(), b.Name())
+	})
+
+	// Backends with expensive entry info fetch it in one batch.
+	l.infos = statEntries(ctx, fsys, dir, l.entries)
+	return l
 }
 
 // walkBreadthFirst implements breadth-first traversal using ReadDirFS.
+//
+// Under WithConcurrency, up to n queued directories are read ahead in the
+// background. Their entries are still yielded in queue order, so the order
+// of the walk does not depend on n.
 func walkBreadthFirst(
 	ctx context.Context, fsys FS, root string, depth int,
 ) iter.Seq2[DirEntry, error] {
 	return func(yield func(DirEntry, error) bool) {
+		skip, stay := walkSkip(ctx), stayOnFilesystem(ctx)
+		n := Concurrency(ctx)
+
+		// Reads in flight are abandoned when the walk stops.
+		readCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
 		// Start with root directory
-		queue := []queueItem{{root, 0}}
+		queue := []*queueItem{{path: root}}
+
+		// The first ahead items of the queue are being read in the
+		// background.
+		var ahead int
+		prefetch := func() {
+			for ; ahead < n && ahead < len(queue); ahead++ {
+				item := queue[ahead]
+				item.done = make(chan walkListing, 1)
+				go func() {
+					item.done <- listWalkDir(readCtx, fsys, item.path)
+				}()
+			}
+		}
 
 		for len(queue) > 0 {
 			current := queue[0]
 			queue = queue[1:]
 
 			// Read directory entries
-			var entries []DirEntry
-			for entry, err := range ReadDir(ctx, fsys, current.path) {
-				if err != nil {
-					// Yield error for this directory and continue
-					if !yield(nil, &PathError{
-						Op:   "readdir",
-						Path: current.path,
-						Err:  err,
-					}) {
-						return
-					}
-					break
+			var l walkListing
+			if current.done != nil {
+				l = <-current.done
+				ahead--
+			} else {
+				l = listWalkDir(readCtx, fsys, current.path)
+			}
+			if n > 1 {
+				prefetch()
+			}
+			if l.err != nil && !(vanished(ctx, l.err) && current.depth > 0) {
+				// Yield error for this directory and continue
+				if !yield(nil, &PathError{
+					Op:   "readdir",
+					Path: current.path,
+					Err:  l.err,
+				}) {
+					return
 				}
-				entries = append(entries, entry)
 			}
 
-			// Sort entries lexicographically
-			slices.SortFunc(entries, func(a, b DirEntry) int {
-				return cmp.Compare(a.Name(), b.Name())
-			})
-
 			// Process entries at this level
-			for _, entry := range entries {
+			for i, entry := range l.entries {
 				// Build full path for this entry
 				entryPath := path.Join(current.path, entry.Name())
 
 				// Get FileInfo for the entry
-				info, err := entry.Info()
-				if err != nil {
-					if !yield(nil, &PathError{
-						Op:   "stat",
-						Path: entryPath,
-						Err:  err,
-					}) {
-						return
-					}
+				var info FileInfo
+				var err error
+				if l.infos != nil && l.infos[i] != nil {
+					info = l.infos[i]
+				} else {
+					info, err = entry.Info()
+				}
+				if err != nil && vanished(ctx, err) {
 					continue
 				}
 
@@ -236,8 +537,15 @@ func walkBreadthFirst(
 					path:  entryPath,
 				}
 
-				// Yield wrapped entry
-				if !yield(we, nil) {
+				// Yield the entry, or the error if its info could not be
+				// read. Either way, a directory is still traversed, since
+				// IsDir does not depend on Info.
+				if err != nil {
+					err = &PathError{Op: "stat", Path: entryPath, Err: err}
+					if !yield(nil, err) {
+						return
+					}
+				} else if !yield(we, nil) {
 					return
 				}
 
@@ -246,13 +554,26 @@ func walkBreadthFirst(
 				// depth = 1 means only immediate children (no subdirs)
 				// depth = 2 means immediate children + 1 level of subdirs,
 				// etc.
-				if entry.IsDir() {
+				if entry.IsDir() && (skip == nil || !skip(we)) {
 					nextDepth := current.depth + 1
-					if depth <= 0 || nextDepth < depth {
-						queue = append(queue, queueItem{
-							path:  entryPath,
-							depth: nextDepth,
-						})
+					if depth > 0 && nextDepth >= depth {
+						continue
+					}
+					if stay {
+						// Mount points are yielded above but not entered.
+						mnt, err := IsMountPoint(ctx, fsys, entryPath)
+						if err != nil && !yield(nil, err) {
+							return
+						} else if err != nil || mnt {
+							continue
+						}
+					}
+					queue = append(queue, &queueItem{
+						path:  entryPath,
+						depth: nextDepth,
+					})
+					if n > 1 {
+						prefetch()
 					}
 				}
 			}
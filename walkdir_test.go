@@ -2,10 +2,19 @@ package fs_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"iter"
 	"log"
+	stdpath "path"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
 
 	"lesiw.io/fs"
+	"lesiw.io/fs/memfs"
 	"lesiw.io/fs/osfs"
 )
 
@@ -38,3 +47,469 @@ func ExampleWalk_recursive() {
 	// Output:
 	// Found 2 files
 }
+
+func TestWithWalkSkip(t *testing.T) {
+	ctx, fsys := context.Background(), memfs.New()
+
+	for _, file := range []string{
+		"keep/a.txt", "skipme/b.txt", "skipme/deep/c.txt", "top.txt",
+	} {
+		if err := fs.WriteFile(ctx, fsys, file, []byte(file)); err != nil {
+			t.Fatalf("WriteFile(%q) error = %v", file, err)
+		}
+	}
+
+	ctx = fs.WithWalkSkip(ctx, func(e fs.DirEntry) bool {
+		return e.Name() == "skipme"
+	})
+	var got []string
+	for e, err := range fs.Walk(ctx, fsys, ".", 0) {
+		if err != nil {
+			t.Fatalf("Walk() error = %v", err)
+		}
+		got = append(got, e.Name())
+	}
+	slices.Sort(got)
+
+	want := []string{"a.txt", "keep", "skipme", "top.txt"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Walk() = %v, want %v", got, want)
+	}
+}
+
+// statCountFS counts entry Info and StatMany calls.
+type statCountFS struct {
+	fs.FS
+	infos, batches int
+}
+
+func (f *statCountFS) ReadDir(
+	ctx context.Context, name string,
+) iter.Seq2[fs.DirEntry, error] {
+	return func(yield func(fs.DirEntry, error) bool) {
+		for e, err := range fs.ReadDir(ctx, f.FS, name) {
+			if err == nil {
+				e = &countedEntry{e, &f.infos}
+			}
+			if !yield(e, err) {
+				return
+			}
+		}
+	}
+}
+
+func (f *statCountFS) StatMany(
+	ctx context.Context, names []string,
+) ([]fs.FileInfo, error) {
+	f.batches++
+	return fs.StatMany(ctx, f.FS, names)
+}
+
+type countedEntry struct {
+	fs.DirEntry
+	n *int
+}
+
+func (e *countedEntry) Info() (fs.FileInfo, error) {
+	*e.n++
+	return e.DirEntry.Info()
+}
+
+func TestWalkStatMany(t *testing.T) {
+	ctx, mem := context.Background(), memfs.New()
+
+	for i := range 50 {
+		name := fmt.Sprintf("dir/file%02d.txt", i)
+		if err := fs.WriteFile(ctx, mem, name, []byte(name)); err != nil {
+			t.Fatalf("WriteFile(%q) error = %v", name, err)
+		}
+	}
+
+	fsys := &statCountFS{FS: mem}
+	var n int
+	for e, err := range fs.Walk(ctx, fsys, ".", 0) {
+		if err != nil {
+			t.Fatalf("Walk() error = %v", err)
+		}
+		if _, err := e.Info(); err != nil {
+			t.Fatalf("Info(%q) error = %v", e.Path(), err)
+		}
+		n++
+	}
+
+	if got, want := n, 51; got != want {
+		t.Errorf("Walk() yielded %d entries, want %d", got, want)
+	}
+	if got, want := fsys.batches, 2; got != want {
+		t.Errorf("StatMany calls = %d, want %d", got, want)
+	}
+	if got := fsys.infos; got != 0 {
+		t.Errorf("DirEntry.Info calls = %d, want 0", got)
+	}
+}
+
+func TestWalkStatManySymlink(t *testing.T) {
+	ctx, mem := context.Background(), memfs.New()
+	if err := fs.WriteFile(ctx, mem, "dir/file.txt", nil); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if err := fs.Symlink(ctx, mem, "dir", "link"); err != nil {
+		t.Fatalf("Symlink() error = %v", err)
+	}
+
+	for e, err := range fs.Walk(ctx, &statCountFS{FS: mem}, ".", 1) {
+		if err != nil {
+			t.Fatalf("Walk() error = %v", err)
+		}
+		if e.Name() != "link" {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			t.Fatalf("Info(link) error = %v", err)
+		}
+		if info.Mode()&fs.ModeSymlink == 0 || info.IsDir() {
+			t.Errorf("Info(link).Mode() = %v, want the link itself",
+				info.Mode())
+		}
+	}
+}
+
+func TestWalkFiles(t *testing.T) {
+	fsys, ctx := osfs.NewTemp(), context.Background()
+	t.Cleanup(func() { fs.Close(fsys) })
+
+	err := fs.WriteFile(ctx, fsys, "dir/file.txt", []byte("content"))
+	if err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if err := fs.Symlink(ctx, fsys, "file.txt", "dir/link.txt"); err != nil {
+		t.Fatalf("Symlink() error = %v", err)
+	}
+
+	collect := func(seq iter.Seq2[fs.DirEntry, error]) (paths []string) {
+		t.Helper()
+		for e, err := range seq {
+			if err != nil {
+				t.Fatalf("walk error = %v", err)
+			}
+			paths = append(paths, e.Path())
+		}
+		slices.Sort(paths)
+		return paths
+	}
+
+	got := collect(fs.WalkFiles(ctx, fsys, "."))
+	if want := []string{"./dir/file.txt"}; !slices.Equal(got, want) {
+		t.Errorf("WalkFiles() = %v, want %v", got, want)
+	}
+	got = collect(fs.WalkDirs(ctx, fsys, "."))
+	if want := []string{"./dir"}; !slices.Equal(got, want) {
+		t.Errorf("WalkDirs() = %v, want %v", got, want)
+	}
+}
+
+func TestWalkWithCount(t *testing.T) {
+	ctx, fsys := context.Background(), memfs.New()
+	for _, name := range []string{"a/1", "a/b/2", "a/b/c/3", "4"} {
+		if err := fs.WriteFile(ctx, fsys, name, nil); err != nil {
+			t.Fatalf("WriteFile(%q) error = %v", name, err)
+		}
+	}
+
+	total, _, err := fs.WalkWithCount(ctx, fsys, ".", 0)
+	if err != nil {
+		t.Fatalf("WalkWithCount() error = %v", err)
+	}
+	if total != -1 {
+		t.Errorf("WalkWithCount() total without precount = %d, want -1",
+			total)
+	}
+
+	ctx = fs.WithPrecount(ctx)
+	for _, depth := range []int{0, 1, 2} {
+		total, entries, err := fs.WalkWithCount(ctx, fsys, ".", depth)
+		if err != nil {
+			t.Fatalf("WalkWithCount(depth %d) error = %v", depth, err)
+		}
+		var n int
+		for _, err := range entries {
+			if err != nil {
+				t.Fatalf("walk error = %v", err)
+			}
+			n++
+		}
+		if total != n {
+			t.Errorf("WalkWithCount(depth %d) total = %d, yielded %d",
+				depth, total, n)
+		}
+	}
+}
+
+func TestWalkWithCountNative(t *testing.T) {
+	ctx, mem := context.Background(), memfs.New()
+	for _, name := range []string{"a", "b", "sub/c"} {
+		if err := fs.WriteFile(ctx, mem, name, nil); err != nil {
+			t.Fatalf("WriteFile(%q) error = %v", name, err)
+		}
+	}
+	fsys := &countFS{FS: mem, count: 3}
+
+	total, _, err := fs.WalkWithCount(ctx, fsys, ".", 1)
+	if err != nil {
+		t.Fatalf("WalkWithCount() error = %v", err)
+	}
+	if total != 3 || fsys.counts != 1 || fsys.reads != 0 {
+		t.Errorf("WalkWithCount() = %d with %d Count and %d ReadDir calls, "+
+			"want 3 with 1 and 0", total, fsys.counts, fsys.reads)
+	}
+}
+
+// vanishFS reports entries named in gone as vanished: their Info fails
+// with ErrNotExist, and so does reading them as directories. Entries named
+// in denied fail Info with ErrPermission.
+type vanishFS struct {
+	fs.FS
+	gone, denied map[string]bool
+}
+
+func (f *vanishFS) ReadDir(
+	ctx context.Context, name string,
+) iter.Seq2[fs.DirEntry, error] {
+	return func(yield func(fs.DirEntry, error) bool) {
+		if f.gone[strings.TrimPrefix(name, "./")] {
+			yield(nil, &fs.PathError{
+				Op: "readdir", Path: name, Err: fs.ErrNotExist,
+			})
+			return
+		}
+		for e, err := range fs.ReadDir(ctx, f.FS, name) {
+			if err == nil {
+				switch {
+				case f.gone[e.Name()]:
+					e = &failInfoEntry{e, fs.ErrNotExist}
+				case f.denied[e.Name()]:
+					e = &failInfoEntry{e, fs.ErrPermission}
+				}
+			}
+			if !yield(e, err) {
+				return
+			}
+		}
+	}
+}
+
+type failInfoEntry struct {
+	fs.DirEntry
+	err error
+}
+
+func (e *failInfoEntry) Info() (fs.FileInfo, error) { return nil, e.err }
+
+func TestWalkVanished(t *testing.T) {
+	ctx, mem := context.Background(), memfs.New()
+	for _, name := range []string{
+		"gone.txt", "gonedir/x.txt", "locked/y.txt", "kept.txt",
+	} {
+		if err := fs.WriteFile(ctx, mem, name, nil); err != nil {
+			t.Fatalf("WriteFile(%q) error = %v", name, err)
+		}
+	}
+	fsys := &vanishFS{
+		FS:     mem,
+		gone:   map[string]bool{"gone.txt": true, "gonedir": true},
+		denied: map[string]bool{"locked": true},
+	}
+
+	walk := func(ctx context.Context) (names []string, errs int) {
+		for e, err := range fs.Walk(ctx, fsys, ".", 0) {
+			if err != nil {
+				errs++
+				continue
+			}
+			names = append(names, e.Name())
+		}
+		slices.Sort(names)
+		return names, errs
+	}
+
+	// The denied directory is reported but still traversed. The vanished
+	// directory is reported twice: once for its info, once for reading it.
+	names, errs := walk(ctx)
+	if want := []string{"kept.txt", "y.txt"}; !slices.Equal(names, want) {
+		t.Errorf("Walk() = %v, want %v", names, want)
+	}
+	if want := 4; errs != want {
+		t.Errorf("Walk() errors = %d, want %d", errs, want)
+	}
+
+	names, errs = walk(fs.WithIgnoreVanished(ctx))
+	if want := []string{"kept.txt", "y.txt"}; !slices.Equal(names, want) {
+		t.Errorf("Walk(WithIgnoreVanished) = %v, want %v", names, want)
+	}
+	if want := 1; errs != want {
+		t.Errorf("Walk(WithIgnoreVanished) errors = %d, want %d", errs, want)
+	}
+}
+
+// parallelFS delays each ReadDir below the root of the walk by lag, or
+// until its context is done, and records the most that ran at once.
+type parallelFS struct {
+	fs.FS
+	lag     time.Duration
+	mu      sync.Mutex
+	running int
+	peak    int
+}
+
+func (f *parallelFS) ReadDir(
+	ctx context.Context, name string,
+) iter.Seq2[fs.DirEntry, error] {
+	return func(yield func(fs.DirEntry, error) bool) {
+		if stdpath.Base(name) != "root" {
+			f.mu.Lock()
+			f.running++
+			f.peak = max(f.peak, f.running)
+			f.mu.Unlock()
+			select {
+			case <-time.After(f.lag):
+			case <-ctx.Done():
+			}
+			f.mu.Lock()
+			f.running--
+			f.mu.Unlock()
+		}
+		for e, err := range fs.ReadDir(ctx, f.FS, name) {
+			if !yield(e, err) {
+				return
+			}
+		}
+	}
+}
+
+func TestWalkConcurrency(t *testing.T) {
+	ctx, mem := context.Background(), memfs.New()
+	for i := range 4 {
+		for j := range 4 {
+			name := fmt.Sprintf("root/d%d/e%d/file.txt", i, j)
+			if err := fs.WriteFile(ctx, mem, name, nil); err != nil {
+				t.Fatalf("WriteFile(%q) error = %v", name, err)
+			}
+		}
+	}
+
+	walk := func(n int) ([]string, int) {
+		fsys := &parallelFS{FS: mem, lag: 5 * time.Millisecond}
+		var paths []string
+		ctx := fs.WithConcurrency(ctx, n)
+		for e, err := range fs.Walk(ctx, fsys, "root", 0) {
+			if err != nil {
+				t.Fatalf("Walk(n=%d) error = %v", n, err)
+			}
+			paths = append(paths, e.Path())
+		}
+		return paths, fsys.peak
+	}
+	want, peak := walk(1)
+	if peak != 1 {
+		t.Errorf("Walk(n=1) concurrent ReadDir = %d, want 1", peak)
+	}
+	for _, n := range []int{2, 8} {
+		got, peak := walk(n)
+		if !slices.Equal(got, want) {
+			t.Errorf("Walk(n=%d) = %q, want %q", n, got, want)
+		}
+		if peak < 2 || peak > n {
+			t.Errorf("Walk(n=%d) concurrent ReadDir = %d, want 2..%d",
+				n, peak, n)
+		}
+	}
+}
+
+func TestWalkConcurrencyStop(t *testing.T) {
+	ctx, mem := context.Background(), memfs.New()
+	for i := range 8 {
+		name := fmt.Sprintf("root/d%d/file.txt", i)
+		if err := fs.WriteFile(ctx, mem, name, nil); err != nil {
+			t.Fatalf("WriteFile(%q) error = %v", name, err)
+		}
+	}
+	fsys := &parallelFS{FS: mem, lag: time.Minute}
+	ctx = fs.WithConcurrency(ctx, 8)
+	for range fs.Walk(ctx, fsys, "root", 0) {
+		break
+	}
+	deadline := time.Now().Add(time.Second)
+	for {
+		fsys.mu.Lock()
+		running := fsys.running
+		fsys.mu.Unlock()
+		if running == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("ReadDir calls still running after Walk stopped")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// nativeWalkFS implements WalkFS by walking its FS.
+type nativeWalkFS struct{ fs.FS }
+
+func (f nativeWalkFS) Walk(
+	ctx context.Context, root string, depth int,
+) iter.Seq2[fs.DirEntry, error] {
+	return fs.Walk(context.Background(), f.FS, root, depth)
+}
+
+func TestWalkBudget(t *testing.T) {
+	ctx, mem := context.Background(), memfs.New()
+	for i := range 5 {
+		name := fmt.Sprintf("root/d%d/file.txt", i)
+		if err := fs.WriteFile(ctx, mem, name, nil); err != nil {
+			t.Fatalf("WriteFile(%q) error = %v", name, err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		fsys fs.FS
+	}{
+		{"Fallback", mem},
+		{"Native", nativeWalkFS{mem}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := fs.WithWalkBudget(ctx, 3)
+			var entries int
+			var errs []error
+			for e, err := range fs.Walk(ctx, tt.fsys, "root", 0) {
+				if err != nil {
+					errs = append(errs, err)
+					continue
+				}
+				if len(errs) > 0 {
+					t.Fatalf("Walk() yielded %q after an error", e.Path())
+				}
+				entries++
+			}
+			if entries != 3 {
+				t.Errorf("Walk() entries = %d, want 3", entries)
+			}
+			if len(errs) != 1 ||
+				!errors.Is(errs[0], fs.ErrWalkBudgetExceeded) {
+				t.Errorf("Walk() errors = %v, want ErrWalkBudgetExceeded",
+					errs)
+			}
+
+			// A budget that covers the tree is never exceeded.
+			ctx = fs.WithWalkBudget(ctx, 10)
+			for _, err := range fs.Walk(ctx, tt.fsys, "root", 0) {
+				if err != nil {
+					t.Errorf("Walk(budget 10) error = %v", err)
+				}
+			}
+		})
+	}
+}
@@ -1,6 +1,10 @@
 package fs
 
-import "context"
+import (
+	"bytes"
+	"context"
+	"io"
+)
 
 // WriteFile writes data to the named file in the filesystem.
 // It creates the file or truncates it if it already exists.
@@ -36,3 +40,52 @@ func WriteFile(
 	}
 	return nil
 }
+
+// WriteFileIfChanged writes data to the named file unless the file already
+// holds exactly data, and reports whether it wrote.
+// Analogous to: rsync --checksum, install -C.
+//
+// If [StatFS] reports a different size, the file is written without being
+// read. Otherwise the existing contents are streamed and compared with
+// data, stopping at the first difference. A missing or unreadable file is
+// treated as changed. Writes go through [WriteFile].
+//
+// Requires: [CreateFS]
+func WriteFileIfChanged(
+	ctx context.Context, fsys FS, name string, data []byte,
+) (changed bool, err error) {
+	if name, err = localizePath(ctx, fsys, name); err != nil {
+		return false, err
+	}
+	if !sameContent(ctx, fsys, name, data) {
+		return true, WriteFile(ctx, fsys, name, data)
+	}
+	return false, nil
+}
+
+// sameContent reports whether the named file holds exactly data.
+func sameContent(ctx context.Context, fsys FS, name string, data []byte) bool {
+	if info, err := Stat(ctx, fsys, name); err == nil {
+		if info.IsDir() || info.Size() != int64(len(data)) {
+			return false
+		}
+	}
+	r, err := fsys.Open(ctx, name)
+	if err != nil {
+		return false
+	}
+	defer r.Close()
+	buf := make([]byte, min(len(data)+1, 32*1024))
+	for {
+		n, err := io.ReadFull(r, buf)
+		if n > len(data) || !bytes.Equal(buf[:n], data[:n]) {
+			return false
+		}
+		data = data[n:]
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return len(data) == 0
+		} else if err != nil {
+			return false
+		}
+	}
+}
@@ -3,9 +3,12 @@ package fs_test
 import (
 	"context"
 	"fmt"
+	"io"
 	"log"
+	"testing"
 
 	"lesiw.io/fs"
+	"lesiw.io/fs/memfs"
 	"lesiw.io/fs/osfs"
 )
 
@@ -26,3 +29,63 @@ func ExampleWriteFile() {
 	// Output:
 	// Hello, filesystem!
 }
+
+// createCountFS counts calls to Create.
+type createCountFS struct {
+	fs.FS
+	creates int
+}
+
+func (f *createCountFS) Create(
+	ctx context.Context, name string,
+) (io.WriteCloser, error) {
+	f.creates++
+	return fs.Create(ctx, f.FS, name)
+}
+
+func (f *createCountFS) Stat(
+	ctx context.Context, name string,
+) (fs.FileInfo, error) {
+	return fs.Stat(ctx, f.FS, name)
+}
+
+func TestWriteFileIfChanged(t *testing.T) {
+	ctx := context.Background()
+	fsys := &createCountFS{FS: memfs.New()}
+
+	tests := []struct {
+		data    string
+		changed bool
+		creates int
+	}{
+		{"hello", true, 1},
+		{"hello", false, 1},
+		{"world", true, 2},
+		{"world!", true, 3},
+		{"", true, 4},
+		{"", false, 4},
+	}
+	for _, tt := range tests {
+		changed, err := fs.WriteFileIfChanged(
+			ctx, fsys, "file.txt", []byte(tt.data),
+		)
+		if err != nil {
+			t.Fatalf("WriteFileIfChanged(%q) error = %v", tt.data, err)
+		}
+		if changed != tt.changed {
+			t.Errorf("WriteFileIfChanged(%q) = %v, want %v",
+				tt.data, changed, tt.changed)
+		}
+		if fsys.creates != tt.creates {
+			t.Errorf("after WriteFileIfChanged(%q): Create calls = %d, "+
+				"want %d", tt.data, fsys.creates, tt.creates)
+		}
+		data, err := fs.ReadFile(ctx, fsys, "file.txt")
+		if err != nil {
+			t.Fatalf("ReadFile() error = %v", err)
+		}
+		if got := string(data); got != tt.data {
+			t.Errorf("ReadFile() = %q, want %q", got, tt.data)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+// Package writerfs implements lesiw.io/fs.FS over a single io.Writer.
+//
+// It is the counterpart of lesiw.io/fs/readerfs, for command-line tools
+// that accept "-" for standard output:
+//
+//	fsys := writerfs.New("-", os.Stdout)
+//	err := fs.WriteFile(ctx, fsys, "-", data)
+package writerfs
+
+import (
+	"context"
+	"io"
+	stdpath "path"
+	"sync"
+
+	"lesiw.io/fs"
+)
+
+// New returns a filesystem holding one write-only file, name, whose
+// contents are written to w.
+//
+// Each Create of name returns a writer that passes its bytes on to w, so
+// successive files are concatenated. Closing the writer does not close w.
+// Creating any other name reports [fs.ErrPermission]. Opening name for
+// reading also reports [fs.ErrPermission], and opening any other name
+// reports [fs.ErrNotExist].
+func New(name string, w io.Writer) fs.FS {
+	return &writerFS{name: clean(name), w: w}
+}
+
+type writerFS struct {
+	name string
+
+	mu sync.Mutex // serializes writes to w
+	w  io.Writer
+}
+
+func clean(name string) string {
+	return stdpath.Clean("/" + name)[1:]
+}
+
+func (f *writerFS) Open(
+	ctx context.Context, name string,
+) (io.ReadCloser, error) {
+	err := fs.ErrNotExist
+	if clean(name) == f.name {
+		err = fs.ErrPermission
+	}
+	return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+}
+
+var _ fs.CreateFS = (*writerFS)(nil)
+
+func (f *writerFS) Create(
+	ctx context.Context, name string,
+) (io.WriteCloser, error) {
+	if clean(name) != f.name {
+		return nil, &fs.PathError{
+			Op:   "create",
+			Path: name,
+			Err:  fs.ErrPermission,
+		}
+	}
+	return &writer{fsys: f}, nil
+}
+
+// writer forwards writes to the underlying writer of fsys.
+type writer struct {
+	fsys *writerFS
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	w.fsys.mu.Lock()
+	defer w.fsys.mu.Unlock()
+	return w.fsys.w.Write(p)
+}
+
+func (w *writer) Close() error { return nil }
@@ -0,0 +1,29 @@
+package writerfs
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"lesiw.io/fs"
+)
+
+func TestWriteFile(t *testing.T) {
+	var buf bytes.Buffer
+	fsys, ctx := New("-", &buf), t.Context()
+
+	if err := fs.WriteFile(ctx, fsys, "-", []byte("hello")); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if got, want := buf.String(), "hello"; got != want {
+		t.Errorf("written = %q, want %q", got, want)
+	}
+
+	err := fs.WriteFile(ctx, fsys, "other", []byte("x"))
+	if !errors.Is(err, fs.ErrPermission) {
+		t.Errorf("WriteFile(%q) error = %v, want ErrPermission", "other", err)
+	}
+	if got, want := buf.String(), "hello"; got != want {
+		t.Errorf("written = %q, want %q", got, want)
+	}
+}
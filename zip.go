@@ -0,0 +1,229 @@
+package fs
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+
+	"lesiw.io/fs/path"
+)
+
+// OpenZip opens the directory dir for reading as a zip archive of its
+// contents, for consumers such as browsers and Windows tools that expect
+// zip rather than the tar streams returned by [Open].
+//
+// Members are listed in the same order as the tar stream [Open] builds from
+// [ReadDirFS], and carry each file's mode and modification time. Regular
+// files are compressed with Deflate. Named pipes, devices, and symbolic
+// links have no zip representation and are left out.
+//
+// Requires: [FS] && ([ReadDirFS] || [WalkFS])
+func OpenZip(ctx context.Context, fsys FS, dir string) (ReadPathCloser, error) {
+	var err error
+	if dir, err = localizePath(ctx, fsys, dir); err != nil {
+		return nil, err
+	}
+	pr, pw := io.Pipe()
+	go func() {
+		err := createZipFromFS(ctx, fsys, dir, pw)
+		pw.CloseWithError(err)
+	}()
+	return readPathCloser(pr, dir), nil
+}
+
+// createZipFromFS walks the filesystem and creates a zip archive.
+func createZipFromFS(
+	ctx context.Context, fsys FS, dir string, w io.Writer,
+) error {
+	zw := zip.NewWriter(w)
+	err := walkArchive(ctx, fsys, dir, func(m archiveMember) error {
+		mode := m.info.Mode()
+		if !mode.IsRegular() && !mode.IsDir() {
+			return nil
+		}
+		hdr, err := zip.FileInfoHeader(m.info)
+		if err != nil {
+			return err
+		}
+		hdr.Name = m.rel
+		if mode.IsDir() {
+			hdr.Name += "/"
+		} else {
+			hdr.Method = zip.Deflate
+		}
+		fw, err := zw.CreateHeader(hdr)
+		if err != nil || mode.IsDir() {
+			return err
+		}
+		return copyMember(ctx, fsys, m, fw)
+	})
+	if err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// CreateZip returns a writer that extracts a zip archive into the directory
+// dir, replacing its contents. It is the zip counterpart of [Create] with a
+// trailing slash.
+//
+// The directory is emptied (or created if it doesn't exist), as with
+// Truncate(dir+"/", 0), and the archive is extracted as described in
+// [AppendZip].
+//
+// Requires: See [Truncate] and [AppendZip] requirements
+func CreateZip(
+	ctx context.Context, fsys FS, dir string,
+) (WritePathCloser, error) {
+	var err error
+	if dir, err = localizePath(ctx, fsys, dir); err != nil {
+		return nil, err
+	}
+	created, err := mkdirAllOrVirtual(ctx, fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	if created {
+		if err := Truncate(ctx, fsys, path.Join(dir, ""), 0); err != nil {
+			return nil, err
+		}
+	}
+	return AppendZip(ctx, fsys, dir)
+}
+
+// AppendZip returns a writer that extracts a zip archive into the directory
+// dir. It is the zip counterpart of [Append] with a trailing slash: existing
+// files with the same names are overwritten, but other files in the
+// directory are preserved.
+//
+// A zip archive is indexed by a directory at its end, so the archive is
+// buffered in memory and extracted when the writer is closed. Close returns
+// any error from the extraction.
+//
+// Files are created with the mode recorded in the archive, through
+// [WithFileMode], and their modification times are set with [Chtimes] if
+// the filesystem implements [ChtimesFS]. Directories, including empty ones,
+// are created when the filesystem implements [MkdirFS]. [WithStripComponents]
+// applies to member names as it does to tar streams. A member whose name,
+// with either separator, leads outside dir fails the extraction with
+// [ErrInvalid].
+//
+// Requires: [CreateFS]
+func AppendZip(
+	ctx context.Context, fsys FS, dir string,
+) (WritePathCloser, error) {
+	var err error
+	if dir, err = localizePath(ctx, fsys, dir); err != nil {
+		return nil, err
+	}
+	if _, ok := fsys.(CreateFS); !ok {
+		return nil, &PathError{Op: "append", Path: dir, Err: ErrUnsupported}
+	}
+	return writePathCloser(&zipWriter{ctx: ctx, fsys: fsys, dir: dir}, dir), nil
+}
+
+// zipWriter buffers a zip archive and extracts it on Close.
+type zipWriter struct {
+	bytes.Buffer
+	ctx  context.Context
+	fsys FS
+	dir  string
+}
+
+func (w *zipWriter) Close() error {
+	data := w.Bytes()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return &PathError{Op: "append", Path: w.dir, Err: err}
+	}
+	return extractZipToFS(w.ctx, w.fsys, path.Clean(w.dir), zr)
+}
+
+// extractZipToFS extracts a zip archive to the filesystem.
+func extractZipToFS(
+	ctx context.Context, fsys FS, dir string, zr *zip.Reader,
+) error {
+	strip := stripComponents(ctx)
+
+	for _, f := range zr.File {
+		name := archiveMemberName(f.Name)
+		if name == "." {
+			continue
+		}
+		if strip > 0 {
+			var ok bool
+			if name, ok = stripPath(name, strip); !ok {
+				continue
+			}
+		}
+		fullPath := path.Join(dir, name)
+		if !withinDir(dir, fullPath) {
+			return &PathError{Op: "append", Path: f.Name, Err: ErrInvalid}
+		}
+
+		mode := f.Mode()
+		switch {
+		case mode.IsDir():
+			// Directories are virtual if the filesystem cannot create
+			// them.
+			dirCtx := WithDirMode(ctx, mode.Perm())
+			_, err := mkdirAllOrVirtual(dirCtx, fsys, fullPath)
+			if err != nil {
+				return err
+			}
+		case mode.IsRegular():
+			if err := extractZipFile(ctx, fsys, fullPath, f); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// withinDir reports whether name lies in dir, checked as [Jail] checks
+// paths: with backslashes as separators, so that a member named
+// `..\..\etc\passwd` cannot climb out of dir on any backend.
+func withinDir(dir, name string) bool {
+	dir, name = cleanJailPath(dir), cleanJailPath(name)
+	if dir == "." {
+		return !escapes(name)
+	}
+	return name == dir ||
+		strings.HasPrefix(name, strings.TrimSuffix(dir, "/")+"/")
+}
+
+// extractZipFile writes the zip member f to name and sets its modification
+// time.
+func extractZipFile(
+	ctx context.Context, fsys FS, name string, f *zip.File,
+) error {
+	r, err := f.Open()
+	if err != nil {
+		return &PathError{Op: "append", Path: name, Err: err}
+	}
+	defer r.Close()
+
+	w, err := Create(WithFileMode(ctx, f.Mode().Perm()), fsys, name)
+	if err != nil {
+		return err
+	}
+	_, copyErr := io.Copy(w, r)
+	closeErr := w.Close()
+	if copyErr != nil {
+		return copyErr
+	}
+	if closeErr != nil {
+		return closeErr
+	}
+
+	if mtime := f.Modified; !mtime.IsZero() {
+		err := Chtimes(ctx, fsys, name, mtime, mtime)
+		if err != nil && !errors.Is(err, ErrUnsupported) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+package fs_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"testing"
+	"time"
+
+	"lesiw.io/fs"
+	"lesiw.io/fs/memfs"
+	"lesiw.io/fs/osfs"
+)
+
+func TestOpenZip(t *testing.T) {
+	ctx, fsys := context.Background(), memfs.New()
+	for name, data := range map[string]string{
+		"src/b.txt":   "b",
+		"src/a/c.txt": "c",
+	} {
+		if err := fs.WriteFile(ctx, fsys, name, []byte(data)); err != nil {
+			t.Fatalf("WriteFile(%q) error = %v", name, err)
+		}
+	}
+
+	r, err := fs.OpenZip(ctx, fsys, "src")
+	if err != nil {
+		t.Fatalf("OpenZip() error = %v", err)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("zip.NewReader() error = %v", err)
+	}
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	want := []string{"a/", "a/c.txt", "b.txt"}
+	if !slices.Equal(names, want) {
+		t.Errorf("zip members = %q, want %q", names, want)
+	}
+}
+
+func TestCreateZip(t *testing.T) {
+	fsys, ctx := osfs.NewTemp(), context.Background()
+	defer fs.Close(fsys)
+	mtime := time.Date(2020, 1, 2, 3, 4, 6, 0, time.UTC)
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, m := range []struct {
+		name string
+		mode fs.Mode
+		data string
+	}{
+		{"empty/", fs.ModeDir | 0755, ""},
+		{"dir/run.sh", 0700, "#!/bin/sh"},
+		{"note.txt", 0644, "note"},
+	} {
+		hdr := &zip.FileHeader{Name: m.name, Modified: mtime}
+		hdr.SetMode(m.mode)
+		w, err := zw.CreateHeader(hdr)
+		if err != nil {
+			t.Fatalf("CreateHeader(%q) error = %v", m.name, err)
+		}
+		if _, err := io.WriteString(w, m.data); err != nil {
+			t.Fatalf("Write(%q) error = %v", m.name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("zip Close() error = %v", err)
+	}
+
+	w, err := fs.CreateZip(ctx, fsys, "out")
+	if err != nil {
+		t.Fatalf("CreateZip() error = %v", err)
+	}
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	info, err := fs.Stat(ctx, fsys, "out/empty")
+	if err != nil || !info.IsDir() {
+		t.Errorf("Stat(out/empty) = %v, %v, want directory", info, err)
+	}
+	data, err := fs.ReadFile(ctx, fsys, "out/dir/run.sh")
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if got, want := string(data), "#!/bin/sh"; got != want {
+		t.Errorf("ReadFile() = %q, want %q", got, want)
+	}
+	info, err = fs.Stat(ctx, fsys, "out/dir/run.sh")
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	if got, want := info.Mode().Perm(), fs.Mode(0700); got != want {
+		t.Errorf("Stat().Mode().Perm() = %v, want %v", got, want)
+	}
+	if got := info.ModTime(); !got.Equal(mtime) {
+		t.Errorf("Stat().ModTime() = %v, want %v", got, mtime)
+	}
+}
+
+func TestZipRoundTrip(t *testing.T) {
+	ctx, fsys := context.Background(), memfs.New()
+	if err := fs.WriteFile(ctx, fsys, "src/a/b.txt", []byte("b")); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	r, err := fs.OpenZip(ctx, fsys, "src")
+	if err != nil {
+		t.Fatalf("OpenZip() error = %v", err)
+	}
+	defer r.Close()
+	w, err := fs.AppendZip(ctx, fsys, "dst")
+	if err != nil {
+		t.Fatalf("AppendZip() error = %v", err)
+	}
+	if _, err := io.Copy(w, r); err != nil {
+		t.Fatalf("Copy() error = %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	data, err := fs.ReadFile(ctx, fsys, "dst/a/b.txt")
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if got, want := string(data), "b"; got != want {
+		t.Errorf("ReadFile() = %q, want %q", got, want)
+	}
+}
+
+func TestAppendZipEscape(t *testing.T) {
+	for _, name := range []string{
+		`..\..\etc\passwd`,
+		`a\..\..\..\etc\passwd`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctx, fsys := context.Background(), memfs.New()
+			var buf bytes.Buffer
+			zw := zip.NewWriter(&buf)
+			w, err := zw.Create(name)
+			if err != nil {
+				t.Fatalf("zip Create() error = %v", err)
+			}
+			if _, err := io.WriteString(w, "root::0:0"); err != nil {
+				t.Fatalf("zip Write() error = %v", err)
+			}
+			if err := zw.Close(); err != nil {
+				t.Fatalf("zip Close() error = %v", err)
+			}
+
+			zf, err := fs.AppendZip(ctx, fsys, "srv/out")
+			if err != nil {
+				t.Fatalf("AppendZip() error = %v", err)
+			}
+			if _, err := zf.Write(buf.Bytes()); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+			if err := zf.Close(); !errors.Is(err, fs.ErrInvalid) {
+				t.Errorf("Close() error = %v, want ErrInvalid", err)
+			}
+			for _, p := range []string{"etc/passwd", "srv/etc/passwd"} {
+				if ok, _ := fs.Exists(ctx, fsys, p); ok {
+					t.Errorf("Exists(%q) = true, want member not extracted", p)
+				}
+			}
+		})
+	}
+}